@@ -10,6 +10,9 @@ import (
 	"github.com/zagvozdeen/ola/internal/config"
 	"github.com/zagvozdeen/ola/internal/db"
 	"github.com/zagvozdeen/ola/internal/logger"
+	"github.com/zagvozdeen/ola/internal/mailer"
+	"github.com/zagvozdeen/ola/internal/payments"
+	"github.com/zagvozdeen/ola/internal/receipts"
 	"github.com/zagvozdeen/ola/internal/store"
 )
 
@@ -26,6 +29,9 @@ func main() {
 	pool := db.New(ctx, cfg, log)
 	defer pool.Close()
 	storage := store.New(log, pool)
+	mail := mailer.New(cfg, log)
+	provider := payments.New(cfg, log)
+	operator := receipts.New(cfg, log)
 
-	api.New(cfg, log, storage).Run(ctx)
+	api.New(cfg, log, storage, mail, provider, operator).Run(ctx)
 }
@@ -17,6 +17,10 @@ import (
 	"github.com/zagvozdeen/ola/internal/config"
 	"github.com/zagvozdeen/ola/internal/event_bus"
 	"github.com/zagvozdeen/ola/internal/logger"
+	"github.com/zagvozdeen/ola/internal/mailer"
+	"github.com/zagvozdeen/ola/internal/payments"
+	"github.com/zagvozdeen/ola/internal/receipts"
+	"github.com/zagvozdeen/ola/internal/scheduler"
 	"github.com/zagvozdeen/ola/internal/seeder"
 	"github.com/zagvozdeen/ola/internal/store"
 	"github.com/zagvozdeen/ola/internal/worker_pool"
@@ -31,12 +35,16 @@ type Service struct {
 	conform    *mold.Transformer
 	workerPool *worker_pool.WorkerPool
 	eventBus   *event_bus.EventBus
+	scheduler  *scheduler.Scheduler
+	mailer     *mailer.Mailer
+	payments   payments.Provider
+	receipts   receipts.Operator
 	bot        *bot.Bot
 	templates  *template.Template
 	mu         sync.Mutex
 }
 
-func New(cfg *config.Config, log *logger.Logger, store *store.Store) *Service {
+func New(cfg *config.Config, log *logger.Logger, store *store.Store, mailer *mailer.Mailer, payments payments.Provider, receipts receipts.Operator) *Service {
 	workerPool := worker_pool.New(log, 4, 100)
 	return &Service{
 		cfg:        cfg,
@@ -47,6 +55,10 @@ func New(cfg *config.Config, log *logger.Logger, store *store.Store) *Service {
 		conform:    modifiers.New(),
 		workerPool: workerPool,
 		eventBus:   event_bus.New(workerPool),
+		scheduler:  scheduler.New(log),
+		mailer:     mailer,
+		payments:   payments,
+		receipts:   receipts,
 	}
 }
 
@@ -65,6 +77,18 @@ func (s *Service) Run(ctx context.Context) {
 	}
 
 	s.registerListeners()
+	s.registerCustomerListeners()
+	s.registerEmailListeners()
+	s.registerPaymentListeners()
+	s.registerReferralListeners()
+	s.registerRatingListeners()
+	s.registerReceiptListeners()
+
+	err = s.registerJobs()
+	if err != nil {
+		s.log.Error("Failed to register scheduled jobs", err)
+		return
+	}
 
 	errCh := make(chan error, 2)
 	wg := &sync.WaitGroup{}
@@ -80,6 +104,9 @@ func (s *Service) Run(ctx context.Context) {
 	wg.Go(func() {
 		s.workerPool.Run(ctx)
 	})
+	wg.Go(func() {
+		s.scheduler.Run(ctx)
+	})
 	s.log.Infof("Server started on %s", addr)
 
 	select {
@@ -112,10 +139,20 @@ func (s *Service) getRoutes() *http.ServeMux {
 	mux.HandleFunc("POST /api/auth/login", s.guest(s.login))
 	mux.HandleFunc("POST /api/auth/register", s.guest(s.register))
 
+	mux.HandleFunc("POST /api/telegram/webhook", s.telegramWebhook)
+	mux.HandleFunc("POST /api/payments/webhook", s.paymentWebhook)
+	mux.HandleFunc("GET /api/payments/fake/{external_id}", s.confirmFakePayment)
+
 	mux.HandleFunc("POST /api/guest/feedback", s.guest(s.createGuestFeedback))
 	mux.HandleFunc("POST /api/guest/orders", s.guest(s.createGuestOrder))
+	mux.HandleFunc("POST /api/guest/orders/{uuid}/payments", s.guest(s.createGuestOrderPayment))
+	mux.HandleFunc("GET /api/guest/orders/{uuid}/rating/{score}", s.confirmOrderRating)
+	mux.HandleFunc("POST /api/guest/orders/{uuid}/rating/{score}", s.rateOrderFromEmail)
 
 	mux.HandleFunc("GET /api/me", s.auth(s.getMe))
+	mux.HandleFunc("GET /api/me/notifications", s.auth(s.getMeNotifications))
+	mux.HandleFunc("PATCH /api/me/notifications", s.auth(s.updateMeNotifications))
+	mux.HandleFunc("GET /api/me/referral", s.auth(s.getMeReferral))
 	mux.HandleFunc("GET /api/products", s.auth(s.getProducts))
 	mux.HandleFunc("POST /api/products", s.auth(s.createProduct))
 	mux.HandleFunc("GET /api/products/{uuid}", s.auth(s.getProduct))
@@ -130,20 +167,59 @@ func (s *Service) getRoutes() *http.ServeMux {
 	mux.HandleFunc("GET /api/feedback", s.auth(s.getFeedback))
 	mux.HandleFunc("GET /api/feedback/{uuid}", s.auth(s.getFeedbackByUUID))
 	mux.HandleFunc("PATCH /api/feedback/{uuid}/status", s.auth(s.updateFeedbackStatus))
+	mux.HandleFunc("POST /api/feedback/{uuid}/order", s.auth(s.createOrderFromFeedback))
 	mux.HandleFunc("POST /api/feedback", s.auth(s.createFeedback))
-	//mux.HandleFunc("GET /api/reviews", s.auth(s.getReviews))
-	//mux.HandleFunc("GET /api/reviews/{uuid}", s.auth(s.getReview))
-	//mux.HandleFunc("POST /api/reviews", s.auth(s.createReview))
-	//mux.HandleFunc("PATCH /api/reviews/{uuid}", s.auth(s.updateReview))
-	//mux.HandleFunc("DELETE /api/reviews/{uuid}", s.auth(s.deleteReview))
+	mux.HandleFunc("GET /api/reviews", s.auth(s.getReviews))
+	mux.HandleFunc("GET /api/reviews/{uuid}", s.auth(s.getReview))
+	mux.HandleFunc("POST /api/reviews", s.auth(s.createReview))
+	mux.HandleFunc("PATCH /api/reviews/{uuid}", s.auth(s.updateReview))
+	mux.HandleFunc("PATCH /api/reviews/{uuid}/status", s.auth(s.updateReviewStatus))
+	mux.HandleFunc("DELETE /api/reviews/{uuid}", s.auth(s.deleteReview))
 	mux.HandleFunc("GET /api/orders", s.auth(s.getOrders))
 	mux.HandleFunc("GET /api/orders/{uuid}", s.auth(s.getOrder))
 	mux.HandleFunc("PATCH /api/orders/{uuid}/status", s.auth(s.updateOrderStatus))
+	mux.HandleFunc("PATCH /api/orders/{uuid}/amount", s.auth(s.updateOrderAmount))
+	mux.HandleFunc("POST /api/orders/{uuid}/invoice", s.auth(s.createOrderInvoice))
+	mux.HandleFunc("POST /api/orders/{uuid}/payments", s.auth(s.createOrderPayment))
+	mux.HandleFunc("POST /api/orders/{uuid}/ledger", s.auth(s.createOrderLedgerEntry))
+	mux.HandleFunc("POST /api/payments/{uuid}/refund", s.auth(s.refundPayment))
+	mux.HandleFunc("GET /api/orders/{uuid}/receipts", s.auth(s.getOrderReceipts))
+	mux.HandleFunc("POST /api/receipts/{uuid}/retry", s.auth(s.retryReceipt))
 	mux.HandleFunc("POST /api/orders", s.auth(s.createOrder))
 	mux.HandleFunc("POST /api/orders/from-cart", s.auth(s.createOrderFromCart))
 	mux.HandleFunc("GET /api/cart", s.auth(s.getCart))
 	mux.HandleFunc("POST /api/cart/items", s.auth(s.upsertCartItem))
 	mux.HandleFunc("DELETE /api/cart/items/{product_uuid}", s.auth(s.deleteCartItem))
+	mux.HandleFunc("GET /api/cart/promo-code", s.auth(s.getCartPromoCode))
+	mux.HandleFunc("POST /api/cart/promo-code", s.auth(s.applyCartPromoCode))
+	mux.HandleFunc("DELETE /api/cart/promo-code", s.auth(s.deleteCartPromoCode))
+	mux.HandleFunc("GET /api/promo-codes", s.auth(s.getPromoCodes))
+	mux.HandleFunc("POST /api/promo-codes", s.auth(s.createPromoCode))
+	mux.HandleFunc("GET /api/promo-codes/{uuid}", s.auth(s.getPromoCode))
+	mux.HandleFunc("PATCH /api/promo-codes/{uuid}", s.auth(s.updatePromoCode))
+	mux.HandleFunc("DELETE /api/promo-codes/{uuid}", s.auth(s.deletePromoCode))
+	mux.HandleFunc("GET /api/referrals/report", s.auth(s.getReferralReport))
+	mux.HandleFunc("GET /api/attribution/report", s.auth(s.getAttributionReport))
+	mux.HandleFunc("GET /api/customers", s.auth(s.getCustomers))
+	mux.HandleFunc("GET /api/customers/{uuid}", s.auth(s.getCustomer))
+	mux.HandleFunc("POST /api/customers/{uuid}/merge", s.auth(s.mergeCustomers))
+	mux.HandleFunc("PUT /api/customers/{uuid}/tags", s.auth(s.updateCustomerTags))
+	mux.HandleFunc("POST /api/customers/{uuid}/notes", s.auth(s.createCustomerNote))
+	mux.HandleFunc("DELETE /api/customers/{uuid}/notes/{note_uuid}", s.auth(s.deleteCustomerNote))
+	mux.HandleFunc("GET /api/tags", s.auth(s.getCustomerTags))
+	mux.HandleFunc("GET /api/segments", s.auth(s.getSegments))
+	mux.HandleFunc("POST /api/segments", s.auth(s.createSegment))
+	mux.HandleFunc("GET /api/segments/{uuid}", s.auth(s.getSegment))
+	mux.HandleFunc("PATCH /api/segments/{uuid}", s.auth(s.updateSegment))
+	mux.HandleFunc("DELETE /api/segments/{uuid}", s.auth(s.deleteSegment))
+	mux.HandleFunc("GET /api/segments/{uuid}/customers", s.auth(s.getSegmentCustomers))
+	mux.HandleFunc("GET /api/campaigns", s.auth(s.getCampaigns))
+	mux.HandleFunc("POST /api/campaigns", s.auth(s.createCampaign))
+	mux.HandleFunc("GET /api/campaigns/{uuid}", s.auth(s.getCampaign))
+	mux.HandleFunc("PATCH /api/campaigns/{uuid}", s.auth(s.updateCampaign))
+	mux.HandleFunc("POST /api/campaigns/{uuid}/preview", s.auth(s.previewCampaign))
+	mux.HandleFunc("POST /api/campaigns/{uuid}/schedule", s.auth(s.scheduleCampaign))
+	mux.HandleFunc("POST /api/campaigns/{uuid}/cancel", s.auth(s.cancelCampaign))
 	mux.HandleFunc("GET /api/users", s.auth(s.getUsers))
 	mux.HandleFunc("GET /api/users/{uuid}", s.auth(s.getUser))
 	mux.HandleFunc("PATCH /api/users/{uuid}/role", s.auth(s.updateUserRole))
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	attributionCookie       = "ola_attribution"
+	attributionCookieMaxAge = 30 * 24 * time.Hour
+	attributionValueMaxLen  = 255
+	reportDefaultPeriod     = 30 * 24 * time.Hour
+)
+
+// captureAttribution stores UTM parameters and the external referrer of a landing visit
+// in a first-party cookie. Direct visits keep the previous attribution (last non-direct click).
+func (s *Service) captureAttribution(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	attribution := models.Attribution{
+		Source:      truncateAttribution(query.Get("utm_source")),
+		Medium:      truncateAttribution(query.Get("utm_medium")),
+		Campaign:    truncateAttribution(query.Get("utm_campaign")),
+		Term:        truncateAttribution(query.Get("utm_term")),
+		Content:     truncateAttribution(query.Get("utm_content")),
+		LandingPage: truncateAttribution(r.URL.Path),
+	}
+
+	referrer, err := url.Parse(r.Referer())
+	if err == nil && referrer.Host != "" && !strings.EqualFold(referrer.Hostname(), (&url.URL{Host: r.Host}).Hostname()) {
+		attribution.Referrer = truncateAttribution(referrer.Scheme + "://" + referrer.Host + referrer.Path)
+		if attribution.Source == "" {
+			attribution.Source = truncateAttribution(referrer.Hostname())
+			attribution.Medium = "referral"
+		}
+	}
+	if attribution.Source == "" && attribution.Campaign == "" {
+		return
+	}
+
+	values := url.Values{}
+	values.Set("utm_source", attribution.Source)
+	values.Set("utm_medium", attribution.Medium)
+	values.Set("utm_campaign", attribution.Campaign)
+	values.Set("utm_term", attribution.Term)
+	values.Set("utm_content", attribution.Content)
+	values.Set("referrer", attribution.Referrer)
+	values.Set("landing_page", attribution.LandingPage)
+	values.Set("captured_at", time.Now().UTC().Format(time.RFC3339))
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     attributionCookie,
+		Value:    values.Encode(),
+		Path:     "/",
+		MaxAge:   int(attributionCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   s.cfg.App.IsProduction,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// attributionFromRequest returns the attribution captured on the landing, if any.
+func attributionFromRequest(r *http.Request) *models.Attribution {
+	cookie, err := r.Cookie(attributionCookie)
+	if err != nil {
+		return nil
+	}
+	values, err := url.ParseQuery(cookie.Value)
+	if err != nil {
+		return nil
+	}
+
+	attribution := &models.Attribution{
+		Source:      truncateAttribution(values.Get("utm_source")),
+		Medium:      truncateAttribution(values.Get("utm_medium")),
+		Campaign:    truncateAttribution(values.Get("utm_campaign")),
+		Term:        truncateAttribution(values.Get("utm_term")),
+		Content:     truncateAttribution(values.Get("utm_content")),
+		Referrer:    truncateAttribution(values.Get("referrer")),
+		LandingPage: truncateAttribution(values.Get("landing_page")),
+	}
+	if attribution.Source == "" && attribution.Campaign == "" {
+		return nil
+	}
+	attribution.CapturedAt, err = time.Parse(time.RFC3339, values.Get("captured_at"))
+	if err != nil {
+		attribution.CapturedAt = time.Now()
+	}
+	return attribution
+}
+
+func truncateAttribution(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) <= attributionValueMaxLen {
+		return value
+	}
+	return strings.ToValidUTF8(value[:attributionValueMaxLen], "")
+}
+
+func (s *Service) getAttributionReport(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	from, to, err := parseReportPeriod(r)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+
+	report, err := s.store.GetAttributionReport(r.Context(), from, to)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get attribution report: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, report)
+}
+
+// parseReportPeriod reads optional "from" and "to" dates (inclusive) from the query.
+// Without them the report covers the last 30 days.
+func parseReportPeriod(r *http.Request) (time.Time, time.Time, error) {
+	now := time.Now()
+	from, to := now.Add(-reportDefaultPeriod), now
+	if value := r.URL.Query().Get("from"); value != "" {
+		date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date: %w", err)
+		}
+		from = date
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = date.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("to date must not be before from date")
+	}
+	return from, to, nil
+}
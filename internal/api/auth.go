@@ -100,6 +100,7 @@ func (s *Service) register(r *http.Request) core.Response {
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create user: %w", err))
 	}
+	s.eventBus.UserRegistered.Publish(context.WithoutCancel(r.Context()), user)
 	return core.JSON(http.StatusCreated, user)
 }
 
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	ordersPageCallbackPrefix = "orders_page"
+	botListPageSize          = 10
+)
+
+type commandFunc func(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error
+
+func (s *Service) registerBotHandlers(b *bot.Bot) {
+	b.RegisterHandlerMatchFunc(commandMatch("orders"), s.commandHandler(s.handleOrdersCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("order"), s.commandHandler(s.handleOrderCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("feedback"), s.commandHandler(s.handleFeedbackCommand, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("stats"), s.commandHandler(s.handleStatsCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("thread"), s.commandHandler(s.handleThreadCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("close"), s.commandHandler(s.handleCloseCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("invoice"), s.commandHandler(s.handleInvoiceCommand, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(commandMatch("referral"), s.commandHandler(s.handleReferralCommand, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandlerMatchFunc(isGroupMessage, s.handleGroupMessage)
+	b.RegisterHandlerMatchFunc(isInlineQuery, s.handleInlineQuery)
+	b.RegisterHandlerMatchFunc(isPreCheckoutQuery, s.handlePreCheckoutQuery)
+	b.RegisterHandlerMatchFunc(isSuccessfulPayment, s.handleSuccessfulPayment)
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, fakePaymentCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleFakePaymentCallback, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+	b.RegisterHandler(bot.HandlerTypeCallbackQueryData, ordersPageCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleOrdersPageCallback, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin))
+}
+
+// parseCommand splits "/name@bot args" into name and args.
+func parseCommand(message *models.Message) (string, string, bool) {
+	if message == nil || len(message.Entities) == 0 {
+		return "", "", false
+	}
+	entity := message.Entities[0]
+	if entity.Type != models.MessageEntityTypeBotCommand || entity.Offset != 0 || entity.Length > len(message.Text) {
+		return "", "", false
+	}
+	name, _, _ := strings.Cut(message.Text[1:entity.Length], "@")
+	return name, strings.TrimSpace(message.Text[entity.Length:]), true
+}
+
+func commandMatch(name string) bot.MatchFunc {
+	return func(update *models.Update) bool {
+		command, _, ok := parseCommand(update.Message)
+		return ok && command == name
+	}
+}
+
+func (s *Service) commandHandler(fn commandFunc, roles ...enums.UserRole) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		message := update.Message
+		if message == nil || message.From == nil {
+			return
+		}
+		// Replies contain customers' contacts, so commands are ignored in chats other than private and manager ones.
+		if message.Chat.Type != models.ChatTypePrivate && !s.isManagerChat(message.Chat.ID) {
+			return
+		}
+
+		user, err := s.createUserIfNotExists(ctx, *message.From, "")
+		if err != nil {
+			s.log.Error("Failed to create user", err)
+			return
+		}
+		if !slices.Contains(roles, user.Role) {
+			s.replyToCommand(ctx, b, message, "Эта команда недоступна для вас", nil)
+			return
+		}
+
+		_, args, _ := parseCommand(message)
+		err = fn(ctx, b, message, user, args)
+		if err != nil {
+			s.log.Error("Failed to handle bot command", err)
+			s.replyToCommand(ctx, b, message, "Не удалось выполнить команду", nil)
+		}
+	}
+}
+
+func (s *Service) replyToCommand(ctx context.Context, b *bot.Bot, message *models.Message, text string, keyboard models.ReplyMarkup) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:             message.Chat.ID,
+		MessageThreadID:    message.MessageThreadID,
+		ParseMode:          models.ParseModeMarkdown,
+		Text:               text,
+		ReplyMarkup:        keyboard,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: new(true)},
+		ReplyParameters:    &models.ReplyParameters{MessageID: message.ID, AllowSendingWithoutReply: true},
+	})
+	if err != nil {
+		s.log.Error("Failed to send telegram message", err)
+	}
+}
+
+func (s *Service) handleOrdersCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	text, keyboard, err := s.buildOrdersPage(ctx, 1)
+	if err != nil {
+		return err
+	}
+	s.replyToCommand(ctx, b, message, text, keyboard)
+	return nil
+}
+
+func (s *Service) handleOrdersPageCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	page, err := strconv.Atoi(strings.TrimPrefix(callback.Data, ordersPageCallbackPrefix+":"))
+	if err != nil || page < 1 {
+		return "Не удалось распарсить данные", nil
+	}
+	message := callback.Message.Message
+	if message == nil {
+		return "Сообщение устарело", nil
+	}
+
+	text, keyboard, err := s.buildOrdersPage(ctx, page)
+	if err != nil {
+		return "Не удалось получить заказы", err
+	}
+
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:             message.Chat.ID,
+		MessageID:          message.ID,
+		ParseMode:          models.ParseModeMarkdown,
+		Text:               text,
+		ReplyMarkup:        keyboard,
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: new(true)},
+	})
+	if err != nil {
+		return "Не удалось обновить сообщение", fmt.Errorf("failed to edit orders page message: %w", err)
+	}
+	return fmt.Sprintf("Страница %d", page), nil
+}
+
+func (s *Service) buildOrdersPage(ctx context.Context, page int) (string, models.ReplyMarkup, error) {
+	orders, err := s.store.GetUnfinishedOrders(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get unfinished orders: %w", err)
+	}
+	if len(orders) == 0 {
+		return "📋 *Открытых заказов нет*", nil, nil
+	}
+
+	pages := (len(orders) + botListPageSize - 1) / botListPageSize
+	page = min(page, pages)
+	from := (page - 1) * botListPageSize
+	to := min(from+botListPageSize, len(orders))
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "📋 *Открытые заказы* \\(%d\\)\n_Страница %d из %d_\n\n", len(orders), page, pages)
+	for _, order := range orders[from:to] {
+		fmt.Fprintf(
+			&b,
+			"%s [Заказ \\#%s](%s) — %s, %s\n",
+			order.Status.Emoji(),
+			bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+			bot.EscapeMarkdown(miniAppLink("order", order.UUID)),
+			bot.EscapeMarkdown(order.Name),
+			bot.EscapeMarkdown(order.Status.Label()),
+		)
+	}
+
+	var buttons []models.InlineKeyboardButton
+	if page > 1 {
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "← Назад", CallbackData: fmt.Sprintf("%s:%d", ordersPageCallbackPrefix, page-1)})
+	}
+	if page < pages {
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "Вперёд →", CallbackData: fmt.Sprintf("%s:%d", ordersPageCallbackPrefix, page+1)})
+	}
+	if len(buttons) == 0 {
+		return b.String(), nil, nil
+	}
+	return b.String(), models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{buttons}}, nil
+}
+
+func (s *Service) handleOrderCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(args, "#"))
+	if err != nil {
+		s.replyToCommand(ctx, b, message, "Укажите номер заказа, например `/order 42`", nil)
+		return nil
+	}
+
+	order, err := s.store.GetOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			s.replyToCommand(ctx, b, message, "Заказ не найден", nil)
+			return nil
+		}
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	var customer *model.User
+	if order.UserID != nil {
+		customer, err = s.store.GetUserByID(ctx, *order.UserID)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	s.replyToCommand(ctx, b, message, buildOrderTelegramText(order, customer), getKeyboard(order.Status, orderCallbackPrefix, order.ID, order.UUID))
+	return nil
+}
+
+func (s *Service) handleFeedbackCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	feedback, err := s.store.GetUnfinishedFeedback(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get unfinished feedback: %w", err)
+	}
+	if len(feedback) == 0 {
+		s.replyToCommand(ctx, b, message, "📨 *Открытых заявок нет*", nil)
+		return nil
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "📨 *Открытые заявки* \\(%d\\)\n\n", len(feedback))
+	for _, item := range feedback[:min(len(feedback), botListPageSize)] {
+		fmt.Fprintf(
+			&sb,
+			"%s [Заявка \\#%s](%s) — %s, %s\n",
+			item.Status.Emoji(),
+			bot.EscapeMarkdown(strconv.Itoa(item.ID)),
+			bot.EscapeMarkdown(miniAppLink("feedback", item.UUID)),
+			bot.EscapeMarkdown(item.Type.Label()),
+			bot.EscapeMarkdown(item.Name),
+		)
+	}
+	if len(feedback) > botListPageSize {
+		fmt.Fprintf(&sb, "\n_и ещё %d_", len(feedback)-botListPageSize)
+	}
+
+	s.replyToCommand(ctx, b, message, sb.String(), nil)
+	return nil
+}
+
+func (s *Service) handleStatsCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	staleAfter := s.digestStaleAfter()
+	digest, err := s.store.GetDigest(ctx, from, now, now.Add(-staleAfter), s.digestTopProducts(), 0)
+	if err != nil {
+		return fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	s.replyToCommand(ctx, b, message, buildDigestTelegramText("Статистика за сегодня", digest, staleAfter), nil)
+	return nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+func TestParseCommand(t *testing.T) {
+	command := func(text string, length int) *models.Message {
+		return &models.Message{
+			Text:     text,
+			Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 0, Length: length}},
+		}
+	}
+	tests := []struct {
+		name     string
+		message  *models.Message
+		wantName string
+		wantArgs string
+		wantOK   bool
+	}{
+		{"plain command", command("/orders", 7), "orders", "", true},
+		{"command with args", command("/start ref_abc", 6), "start", "ref_abc", true},
+		{"bot mention", command("/stats@ola_bot  week ", 14), "stats", "week", true},
+		{"nil message", nil, "", "", false},
+		{"no entities", &models.Message{Text: "/orders"}, "", "", false},
+		{
+			name: "command not at the start",
+			message: &models.Message{
+				Text:     "see /orders",
+				Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: 4, Length: 7}},
+			},
+		},
+		{
+			name: "other entity",
+			message: &models.Message{
+				Text:     "#orders",
+				Entities: []models.MessageEntity{{Type: models.MessageEntityTypeHashtag, Offset: 0, Length: 7}},
+			},
+		},
+		{"entity longer than text", command("/x", 10), "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args, ok := parseCommand(tt.message)
+			if name != tt.wantName || args != tt.wantArgs || ok != tt.wantOK {
+				t.Errorf("parseCommand() = %q, %q, %v, want %q, %q, %v", name, args, ok, tt.wantName, tt.wantArgs, tt.wantOK)
+			}
+		})
+	}
+}
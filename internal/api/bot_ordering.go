@@ -0,0 +1,405 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const botOrderCallbackPrefix = "bot_order"
+
+const (
+	botStateBrowsing = "browsing"
+	botStateName     = "name"
+	botStatePhone    = "phone"
+	botStateDelivery = "delivery"
+	botStateContent  = "content"
+)
+
+const (
+	botProductsLimit = 30
+	botMaxQty        = 5
+)
+
+func (s *Service) handleBotOrderCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	message := callback.Message.Message
+	if message == nil || message.Chat.Type != models.ChatTypePrivate {
+		return "Оформить заказ можно только в личном чате с ботом", nil
+	}
+
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) < 2 || parts[0] != botOrderCallbackPrefix {
+		return "Не удалось распарсить данные", nil
+	}
+
+	conversation, err := s.getBotConversation(ctx, user)
+	if err != nil {
+		return "Не удалось загрузить заказ", err
+	}
+
+	switch parts[1] {
+	case "start", "categories":
+		conversation.State = botStateBrowsing
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return "Не удалось начать заказ", err
+		}
+		return "", s.showBotCategories(ctx, b, message, user)
+	case "cat":
+		if len(parts) != 3 {
+			return "Не удалось распарсить данные", nil
+		}
+		conversation.State = botStateBrowsing
+		conversation.Data.Category = parts[2]
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return "Не удалось сохранить выбор", err
+		}
+		return "", s.showBotProducts(ctx, b, message, user, parts[2])
+	case "prod":
+		if len(parts) != 3 {
+			return "Не удалось распарсить данные", nil
+		}
+		productID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return "Не удалось распарсить данные", nil
+		}
+		return "", s.showBotQuantity(ctx, b, message, conversation, productID)
+	case "qty":
+		if len(parts) != 4 {
+			return "Не удалось распарсить данные", nil
+		}
+		productID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return "Не удалось распарсить данные", nil
+		}
+		qty, err := strconv.Atoi(parts[3])
+		if err != nil || qty < 1 || qty > botMaxQty {
+			return "Не удалось распарсить данные", nil
+		}
+		err = s.store.UpsertUserCartItem(ctx, user.ID, productID, qty)
+		if err != nil {
+			return "Не удалось добавить в корзину", fmt.Errorf("failed to upsert cart item from bot: %w", err)
+		}
+		err = s.showBotCategories(ctx, b, message, user)
+		if err != nil {
+			return "", err
+		}
+		return "Добавлено в корзину", nil
+	case "checkout":
+		items, err := s.store.GetUserCartItems(ctx, user.ID)
+		if err != nil {
+			return "Не удалось загрузить корзину", fmt.Errorf("failed to get cart items: %w", err)
+		}
+		if len(items) == 0 {
+			return "Корзина пуста", nil
+		}
+		conversation.State = botStateName
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return "Не удалось оформить заказ", err
+		}
+		return "", s.sendBotMessage(ctx, b, message.Chat.ID, "*Как к вам обращаться?*\n\nНапишите ваше имя в ответном сообщении\\.", nil)
+	case "delivery":
+		if len(parts) != 3 || conversation.State != botStateDelivery {
+			return "Не удалось распарсить данные", nil
+		}
+		deliveryType, err := enums.NewDeliveryType(parts[2])
+		if err != nil {
+			return "Не удалось распарсить данные", nil
+		}
+		conversation.State = botStateContent
+		conversation.Data.DeliveryType = deliveryType.String()
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return "Не удалось сохранить выбор", err
+		}
+		text := "*Комментарий к заказу*\n\nНапишите пожелания: дату, время, оформление\\."
+		if deliveryType == enums.DeliveryTypeDelivery {
+			text = "*Адрес доставки*\n\nНапишите адрес, дату и время доставки, а также пожелания к заказу\\."
+		}
+		return "", s.sendBotMessage(ctx, b, message.Chat.ID, text, nil)
+	case "cancel":
+		err = s.store.DeleteBotConversation(ctx, user.ID)
+		if err != nil {
+			return "Не удалось отменить заказ", fmt.Errorf("failed to delete bot conversation: %w", err)
+		}
+		return "", s.sendBotMessage(ctx, b, message.Chat.ID, "Оформление отменено\\. Товары остались в корзине\\.", models.ReplyKeyboardRemove{RemoveKeyboard: true})
+	default:
+		return "Не удалось распарсить данные", nil
+	}
+}
+
+// handleBotConversationMessage consumes private text replies while the conversation waits for them.
+func (s *Service) handleBotConversationMessage(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User) (bool, error) {
+	if strings.HasPrefix(message.Text, "/") {
+		return false, nil
+	}
+
+	conversation, err := s.store.GetBotConversation(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get bot conversation: %w", err)
+	}
+
+	text := strings.TrimSpace(message.Text)
+	switch conversation.State {
+	case botStateName:
+		if text == "" || utf8.RuneCountInString(text) > 255 {
+			return true, s.sendBotMessage(ctx, b, message.Chat.ID, "Напишите, пожалуйста, имя текстом\\.", nil)
+		}
+		conversation.State = botStatePhone
+		conversation.Data.Name = text
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return true, err
+		}
+		return true, s.sendBotMessage(ctx, b, message.Chat.ID, "*Ваш номер телефона*\n\nНажмите кнопку ниже или напишите номер в формате \\+7 900 000\\-00\\-00\\.", models.ReplyKeyboardMarkup{
+			Keyboard:        [][]models.KeyboardButton{{{Text: "📱 Отправить номер", RequestContact: true}}},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+		})
+	case botStatePhone:
+		if message.Contact != nil {
+			text = message.Contact.PhoneNumber
+		}
+		phone, ok := normalizePhone(text)
+		if !ok {
+			return true, s.sendBotMessage(ctx, b, message.Chat.ID, "Не получилось распознать номер\\. Напишите его в формате \\+7 900 000\\-00\\-00\\.", nil)
+		}
+		conversation.State = botStateDelivery
+		conversation.Data.Phone = phone
+		err = s.saveBotConversation(ctx, conversation)
+		if err != nil {
+			return true, err
+		}
+		err = s.sendBotMessage(ctx, b, message.Chat.ID, "Спасибо\\! Номер сохранён\\.", models.ReplyKeyboardRemove{RemoveKeyboard: true})
+		if err != nil {
+			return true, err
+		}
+		return true, s.sendBotMessage(ctx, b, message.Chat.ID, "*Как вы хотите получить заказ?*", models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{Text: enums.DeliveryTypePickup.Label(), CallbackData: botOrderCallbackPrefix + ":delivery:" + enums.DeliveryTypePickup.String()},
+					{Text: enums.DeliveryTypeDelivery.Label(), CallbackData: botOrderCallbackPrefix + ":delivery:" + enums.DeliveryTypeDelivery.String()},
+				},
+			},
+		})
+	case botStateContent:
+		if text == "" || utf8.RuneCountInString(text) > 3000 {
+			return true, s.sendBotMessage(ctx, b, message.Chat.ID, "Напишите, пожалуйста, комментарий текстом\\.", nil)
+		}
+		order, err := s.placeOrderFromCart(
+			ctx,
+			user,
+			enums.OrderSourceBot,
+			deliveryTypeFromRequest(conversation.Data.DeliveryType),
+			conversation.Data.Name,
+			conversation.Data.Phone,
+			text,
+		)
+		if err != nil {
+			if errors.Is(err, model.ErrCartEmpty) {
+				return true, s.sendBotMessage(ctx, b, message.Chat.ID, "Корзина пуста, добавьте товары и попробуйте снова\\.", nil)
+			}
+			if promoErr, ok := errors.AsType[*promoCodeError](err); ok {
+				err = s.store.SetUserCartPromoCode(ctx, user.ID, nil)
+				if err != nil {
+					return true, fmt.Errorf("failed to clear cart promo code: %w", err)
+				}
+				return true, s.sendBotMessage(ctx, b, message.Chat.ID, bot.EscapeMarkdown(promoErr.Label+". Промокод снят с корзины, отправьте комментарий ещё раз, чтобы оформить заказ без скидки."), nil)
+			}
+			return true, err
+		}
+		err = s.store.DeleteBotConversation(ctx, user.ID)
+		if err != nil {
+			return true, fmt.Errorf("failed to delete bot conversation: %w", err)
+		}
+		return true, s.sendBotMessage(ctx, b, message.Chat.ID, fmt.Sprintf(
+			"🎉 *Заказ \\#%s принят\\!*\n\nМенеджер свяжется с вами по телефону %s, чтобы уточнить детали\\.",
+			bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+			bot.EscapeMarkdown(order.Phone),
+		), nil)
+	default:
+		return false, nil
+	}
+}
+
+func (s *Service) getBotConversation(ctx context.Context, user *model.User) (*model.BotConversation, error) {
+	conversation, err := s.store.GetBotConversation(ctx, user.ID)
+	if err == nil {
+		return conversation, nil
+	}
+	if !errors.Is(err, model.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get bot conversation: %w", err)
+	}
+	return &model.BotConversation{UserID: user.ID, State: botStateBrowsing}, nil
+}
+
+func (s *Service) saveBotConversation(ctx context.Context, conversation *model.BotConversation) error {
+	conversation.UpdatedAt = time.Now()
+	err := s.store.SaveBotConversation(ctx, conversation)
+	if err != nil {
+		return fmt.Errorf("failed to save bot conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) showBotCategories(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User) error {
+	categories, err := s.store.GetAllCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+	items, err := s.store.GetUserCartItems(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(categories)+1)
+	for _, category := range categories {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{{Text: category.Name, CallbackData: botOrderCallbackPrefix + ":cat:" + category.Slug}})
+	}
+	keyboard = append(keyboard, botCheckoutRow(len(items)))
+
+	return s.editBotMessage(ctx, b, message, "*Выберите категорию*\n\n"+buildBotCartText(items), keyboard)
+}
+
+func (s *Service) showBotProducts(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, categorySlug string) error {
+	products, err := s.store.GetCatalogProducts(ctx, []string{categorySlug}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get catalog products: %w", err)
+	}
+	items, err := s.store.GetUserCartItems(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	keyboard := make([][]models.InlineKeyboardButton, 0, len(products)+2)
+	for _, product := range products[:min(len(products), botProductsLimit)] {
+		keyboard = append(keyboard, []models.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%s · %s", product.Name, formatPriceRange(product.PriceFrom, product.PriceTo)),
+			CallbackData: fmt.Sprintf("%s:prod:%d", botOrderCallbackPrefix, product.ID),
+		}})
+	}
+	keyboard = append(keyboard, []models.InlineKeyboardButton{{Text: "← Категории", CallbackData: botOrderCallbackPrefix + ":categories"}})
+	keyboard = append(keyboard, botCheckoutRow(len(items)))
+
+	text := "*Выберите товар*"
+	if len(products) == 0 {
+		text = "*В этой категории пока нет товаров*"
+	}
+	return s.editBotMessage(ctx, b, message, text, keyboard)
+}
+
+func (s *Service) showBotQuantity(ctx context.Context, b *bot.Bot, message *models.Message, conversation *model.BotConversation, productID int) error {
+	product, err := s.store.GetProductByID(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+
+	buttons := make([]models.InlineKeyboardButton, 0, botMaxQty)
+	for qty := 1; qty <= botMaxQty; qty++ {
+		buttons = append(buttons, models.InlineKeyboardButton{
+			Text:         strconv.Itoa(qty),
+			CallbackData: fmt.Sprintf("%s:qty:%d:%d", botOrderCallbackPrefix, product.ID, qty),
+		})
+	}
+	back := botOrderCallbackPrefix + ":categories"
+	if conversation.Data.Category != "" {
+		back = botOrderCallbackPrefix + ":cat:" + conversation.Data.Category
+	}
+
+	return s.editBotMessage(ctx, b, message, fmt.Sprintf(
+		"*%s*\n%s\n\n%s\n\n*Сколько штук добавить?*",
+		bot.EscapeMarkdown(product.Name),
+		bot.EscapeMarkdown(formatPriceRange(product.PriceFrom, product.PriceTo)),
+		bot.EscapeMarkdown(truncateText(product.Description, inlineCaptionDescriptionLimit)),
+	), [][]models.InlineKeyboardButton{
+		buttons,
+		{{Text: "← Назад", CallbackData: back}},
+	})
+}
+
+func botCheckoutRow(itemsCount int) []models.InlineKeyboardButton {
+	row := []models.InlineKeyboardButton{{Text: "✖️ Отмена", CallbackData: botOrderCallbackPrefix + ":cancel"}}
+	if itemsCount > 0 {
+		row = append(row, models.InlineKeyboardButton{Text: fmt.Sprintf("🛒 Оформить (%d)", itemsCount), CallbackData: botOrderCallbackPrefix + ":checkout"})
+	}
+	return row
+}
+
+func buildBotCartText(items []model.CartItem) string {
+	if len(items) == 0 {
+		return "_Корзина пуста_"
+	}
+	sb := strings.Builder{}
+	sb.WriteString("*Корзина*\n")
+	for _, item := range items {
+		fmt.Fprintf(&sb, "– %s × %d\n", bot.EscapeMarkdown(item.ProductName), item.Qty)
+	}
+	return sb.String()
+}
+
+func (s *Service) editBotMessage(ctx context.Context, b *bot.Bot, message *models.Message, text string, keyboard [][]models.InlineKeyboardButton) error {
+	_, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      message.Chat.ID,
+		MessageID:   message.ID,
+		ParseMode:   models.ParseModeMarkdown,
+		Text:        text,
+		ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit bot order message: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) sendBotMessage(ctx context.Context, b *bot.Bot, chatID int64, text string, keyboard models.ReplyMarkup) error {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		ParseMode:   models.ParseModeMarkdown,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send bot order message: %w", err)
+	}
+	return nil
+}
+
+// normalizePhone converts Russian phone numbers to the "+7 (XXX) XXX-XX-XX" format used by orders.
+func normalizePhone(s string) (string, bool) {
+	d, ok := phoneDigits(s)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("+7 (%s) %s-%s-%s", d[0:3], d[3:6], d[6:8], d[8:10]), true
+}
+
+// phoneDigits returns the ten digits of a Russian phone number without the country code.
+func phoneDigits(s string) (string, bool) {
+	digits := make([]byte, 0, 11)
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	if len(digits) == 11 && (digits[0] == '7' || digits[0] == '8') {
+		digits = digits[1:]
+	}
+	if len(digits) != 10 {
+		return "", false
+	}
+	return string(digits), true
+}
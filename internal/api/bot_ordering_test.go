@@ -0,0 +1,29 @@
+package api
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"+7 (912) 345-67-89", "+7 (912) 345-67-89", true},
+		{"89123456789", "+7 (912) 345-67-89", true},
+		{"79123456789", "+7 (912) 345-67-89", true},
+		{"9123456789", "+7 (912) 345-67-89", true},
+		{"8 912 345 67 89", "+7 (912) 345-67-89", true},
+		{"19123456789", "", false},
+		{"+380 44 123 45 67", "", false},
+		{"12345", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := normalizePhone(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("normalizePhone(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
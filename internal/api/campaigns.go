@@ -0,0 +1,547 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+var errCampaignsDisabled = errors.New("campaigns are disabled")
+
+const (
+	campaignMaxBatch       = 500
+	campaignLeaseMargin    = time.Minute
+	campaignRetryInterval  = time.Minute
+	campaignCaptionMaxSize = 1024
+)
+
+type upsertCampaignRequest struct {
+	Name        string  `json:"name" mold:"trim" validate:"required,max=255"`
+	Text        string  `json:"text" mold:"trim" validate:"required,max=4096"`
+	Image       *string `json:"image" mold:"trim" validate:"omitempty,max=255,startswith=/files/"`
+	ButtonText  *string `json:"button_text" mold:"trim" validate:"omitempty,max=64"`
+	SegmentUUID *string `json:"segment_uuid" validate:"omitempty,uuid"`
+}
+
+func (s *Service) applyCampaignRequest(ctx context.Context, req *upsertCampaignRequest, campaign *model.Campaign) core.Response {
+	if req.Image != nil && *req.Image == "" {
+		req.Image = nil
+	}
+	if req.Image != nil && path.Clean(*req.Image) != *req.Image {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid campaign image"))
+	}
+	if req.Image != nil && utf8.RuneCountInString(req.Text) > campaignCaptionMaxSize {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("campaign text with image must not exceed %d characters", campaignCaptionMaxSize))
+	}
+	if req.ButtonText != nil && *req.ButtonText == "" {
+		req.ButtonText = nil
+	}
+
+	campaign.SegmentID = nil
+	if req.SegmentUUID != nil {
+		segment, res := s.findSegment(ctx, *req.SegmentUUID)
+		if res != nil {
+			return res
+		}
+		campaign.SegmentID = &segment.ID
+	}
+	if campaign.Image == nil || req.Image == nil || *campaign.Image != *req.Image {
+		campaign.ImageFileID = nil
+	}
+	campaign.Name = req.Name
+	campaign.Text = req.Text
+	campaign.Image = req.Image
+	campaign.ButtonText = req.ButtonText
+	return nil
+}
+
+func (s *Service) getCampaigns(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	campaigns, err := s.store.GetAllCampaigns(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get campaigns: %w", err))
+	}
+	err = s.attachCampaignStats(r.Context(), campaigns)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get campaign stats: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, campaigns)
+}
+
+func (s *Service) getCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	campaign, res := s.findCampaign(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+
+	return core.JSON(http.StatusOK, campaign)
+}
+
+func (s *Service) createCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[upsertCampaignRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	now := time.Now()
+	campaign := &model.Campaign{
+		UUID:      uid,
+		Status:    enums.CampaignStatusDraft,
+		CreatedBy: &user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	res = s.applyCampaignRequest(r.Context(), req, campaign)
+	if res != nil {
+		return res
+	}
+
+	err = s.store.CreateCampaign(r.Context(), campaign)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create campaign: %w", err))
+	}
+
+	return core.JSON(http.StatusCreated, campaign)
+}
+
+func (s *Service) updateCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[upsertCampaignRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	campaign, res := s.findCampaign(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+	if campaign.Status != enums.CampaignStatusDraft && campaign.Status != enums.CampaignStatusScheduled {
+		return core.Err(http.StatusConflict, fmt.Errorf("campaign %s can not be changed", campaign.Status.String()))
+	}
+	res = s.applyCampaignRequest(r.Context(), req, campaign)
+	if res != nil {
+		return res
+	}
+	if campaign.Status == enums.CampaignStatusScheduled && campaign.SegmentID == nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("scheduled campaign requires a segment"))
+	}
+	campaign.UpdatedAt = time.Now()
+
+	updated, err := s.store.UpdateCampaignContent(r.Context(), campaign)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update campaign: %w", err))
+	}
+	if !updated {
+		return core.Err(http.StatusConflict, fmt.Errorf("campaign has already started"))
+	}
+
+	return core.JSON(http.StatusOK, campaign)
+}
+
+// previewCampaign sends the campaign message to the current user only.
+func (s *Service) previewCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+	if s.bot == nil {
+		return core.Err(http.StatusBadRequest, errTelegramBotDisabled)
+	}
+	if user.TID == nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("user has no telegram account"))
+	}
+
+	campaign, res := s.findCampaign(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+
+	_, err := s.sendCampaignMessage(r.Context(), campaign, *user.TID)
+	if err != nil {
+		return core.Err(http.StatusBadGateway, fmt.Errorf("failed to send campaign preview: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, campaign)
+}
+
+type scheduleCampaignRequest struct {
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// scheduleCampaign queues the campaign for sending at scheduled_at, or right away when it is empty.
+// A cancelled campaign can be scheduled again, it resumes with the recipients not reached yet.
+func (s *Service) scheduleCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+	if !s.cfg.Campaigns.Enabled || s.bot == nil {
+		return core.Err(http.StatusBadRequest, errCampaignsDisabled)
+	}
+
+	req, res := core.Validate[scheduleCampaignRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	campaign, res := s.findCampaign(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+	if campaign.Status == enums.CampaignStatusSending || campaign.Status == enums.CampaignStatusDone {
+		return core.Err(http.StatusConflict, fmt.Errorf("campaign %s can not be scheduled", campaign.Status.String()))
+	}
+	if campaign.SegmentID == nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("campaign requires a segment"))
+	}
+
+	now := time.Now()
+	campaign.Status = enums.CampaignStatusScheduled
+	campaign.ScheduledAt = &now
+	campaign.FinishedAt = nil
+	if req.ScheduledAt != nil && req.ScheduledAt.After(now) {
+		campaign.ScheduledAt = req.ScheduledAt
+	}
+	campaign.UpdatedAt = now
+	err := s.store.UpdateCampaign(r.Context(), campaign)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update campaign: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, campaign)
+}
+
+// cancelCampaign stops a scheduled or sending campaign, messages already sent stay delivered.
+func (s *Service) cancelCampaign(r *http.Request, user *model.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	campaign, res := s.findCampaign(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+	if campaign.Status != enums.CampaignStatusScheduled && campaign.Status != enums.CampaignStatusSending {
+		return core.Err(http.StatusConflict, fmt.Errorf("campaign %s can not be cancelled", campaign.Status.String()))
+	}
+
+	now := time.Now()
+	campaign.FinishedAt = &now
+	campaign.UpdatedAt = now
+	cancelled, err := s.store.CancelCampaign(r.Context(), campaign)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to cancel campaign: %w", err))
+	}
+	if !cancelled {
+		return core.Err(http.StatusConflict, fmt.Errorf("campaign has already finished"))
+	}
+	campaign.Status = enums.CampaignStatusCancelled
+
+	return core.JSON(http.StatusOK, campaign)
+}
+
+func (s *Service) findCampaign(ctx context.Context, value string) (*model.Campaign, core.Response) {
+	uid, err := uuid.Parse(value)
+	if err != nil {
+		return nil, core.Err(http.StatusBadRequest, fmt.Errorf("invalid campaign uuid: %w", err))
+	}
+
+	campaign, err := s.store.GetCampaignByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, core.Err(http.StatusNotFound, fmt.Errorf("campaign not found"))
+		}
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get campaign: %w", err))
+	}
+
+	campaigns := []model.Campaign{*campaign}
+	err = s.attachCampaignStats(ctx, campaigns)
+	if err != nil {
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get campaign stats: %w", err))
+	}
+	return &campaigns[0], nil
+}
+
+func (s *Service) attachCampaignStats(ctx context.Context, campaigns []model.Campaign) error {
+	if len(campaigns) == 0 {
+		return nil
+	}
+
+	campaignIDs := make([]int, 0, len(campaigns))
+	for i := range campaigns {
+		campaignIDs = append(campaignIDs, campaigns[i].ID)
+	}
+
+	statsByCampaignID, err := s.store.GetCampaignStatsByCampaignIDs(ctx, campaignIDs)
+	if err != nil {
+		return err
+	}
+	for i := range campaigns {
+		campaigns[i].Stats = statsByCampaignID[campaigns[i].ID]
+	}
+	return nil
+}
+
+// processCampaigns starts due campaigns and sends the next batch of queued messages,
+// pacing them to RatePerSecond.
+func (s *Service) processCampaigns(ctx context.Context) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	err := s.startDueCampaigns(ctx)
+	if err != nil {
+		return err
+	}
+
+	rate := s.cfg.Campaigns.RatePerSecond
+	if rate <= 0 {
+		rate = 1
+	}
+	batch := min(rate*int(s.cfg.Campaigns.CheckInterval/time.Second), campaignMaxBatch)
+	batch = max(batch, rate)
+
+	now := time.Now()
+	lease := time.Duration(batch/rate)*time.Second + campaignLeaseMargin
+	recipients, err := s.store.ClaimDueCampaignRecipients(ctx, now, now.Add(lease), batch)
+	if err != nil {
+		return fmt.Errorf("failed to claim campaign recipients: %w", err)
+	}
+
+	campaigns := make(map[int]*model.Campaign)
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for i, recipient := range recipients {
+		// Campaigns are reloaded every second, so a cancelled one stops within a second
+		// instead of sending out the rest of the batch.
+		if i%rate == 0 {
+			clear(campaigns)
+		}
+		campaign, ok := campaigns[recipient.CampaignID]
+		if !ok {
+			campaign, err = s.store.GetCampaignByID(ctx, recipient.CampaignID)
+			if err != nil {
+				return fmt.Errorf("failed to get campaign: %w", err)
+			}
+			campaigns[recipient.CampaignID] = campaign
+		}
+		if campaign.Status != enums.CampaignStatusSending {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		retryAfter, err := s.deliverCampaignMessage(ctx, campaign, &recipient)
+		if err != nil {
+			return err
+		}
+		if retryAfter > 0 {
+			// Telegram asked to slow down, the rest of the batch is retried after the lease.
+			break
+		}
+	}
+
+	err = s.store.FinishCampaigns(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to finish campaigns: %w", err)
+	}
+	return nil
+}
+
+// startDueCampaigns queues the segment's Telegram users as recipients of scheduled campaigns.
+func (s *Service) startDueCampaigns(ctx context.Context) error {
+	ctx, err := s.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(ctx)
+
+	now := time.Now()
+	campaigns, err := s.store.GetDueCampaigns(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to get due campaigns: %w", err)
+	}
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		campaign.UpdatedAt = now
+		if campaign.StartedAt == nil {
+			campaign.StartedAt = &now
+		}
+		campaign.Status = enums.CampaignStatusSending
+		if campaign.SegmentID == nil {
+			campaign.Status = enums.CampaignStatusCancelled
+			campaign.FinishedAt = &now
+			s.log.Error("Campaign segment was deleted", fmt.Errorf("campaign %s has no segment", campaign.UUID))
+		} else {
+			segment, err := s.store.GetSegmentByID(ctx, *campaign.SegmentID)
+			if err != nil {
+				return fmt.Errorf("failed to get segment: %w", err)
+			}
+			filter := segment.Filter
+			filter.HasTelegram = true
+			customers, err := s.store.GetSegmentCustomers(ctx, filter, now)
+			if err != nil {
+				return fmt.Errorf("failed to get segment customers: %w", err)
+			}
+			userIDs := make([]int, 0, len(customers))
+			for _, customer := range customers {
+				if customer.UserID != nil {
+					userIDs = append(userIDs, *customer.UserID)
+				}
+			}
+			_, err = s.store.CreateCampaignRecipients(ctx, campaign.ID, userIDs, now)
+			if err != nil {
+				return fmt.Errorf("failed to create campaign recipients: %w", err)
+			}
+		}
+		err = s.store.UpdateCampaign(ctx, campaign)
+		if err != nil {
+			return fmt.Errorf("failed to update campaign: %w", err)
+		}
+	}
+
+	s.store.Commit(ctx)
+	return nil
+}
+
+// deliverCampaignMessage sends the campaign to one recipient and records the outcome.
+// Users who blocked the bot are marked, so later campaigns skip them. A positive
+// duration means Telegram rate limited the bot and sending should pause.
+func (s *Service) deliverCampaignMessage(ctx context.Context, campaign *model.Campaign, recipient *model.CampaignRecipient) (time.Duration, error) {
+	messageID, err := s.sendCampaignMessage(ctx, campaign, recipient.ChatID)
+
+	now := time.Now()
+	recipient.UpdatedAt = now
+	var (
+		retryAfter time.Duration
+		tooMany    *bot.TooManyRequestsError
+	)
+	switch {
+	case err == nil:
+		recipient.Status = enums.CampaignRecipientStatusSent
+		recipient.MessageID = &messageID
+		recipient.LastError = nil
+		recipient.SentAt = &now
+	case errors.As(err, &tooMany):
+		retryAfter = time.Duration(max(tooMany.RetryAfter, 1)) * time.Second
+		recipient.LastError = new(err.Error())
+		recipient.NextAttemptAt = now.Add(retryAfter)
+	case errors.Is(err, bot.ErrorForbidden):
+		recipient.Status = enums.CampaignRecipientStatusBlocked
+		recipient.LastError = new(err.Error())
+		blockedErr := s.store.UpdateUserBotBlockedAt(ctx, &model.User{ID: recipient.UserID, BotBlockedAt: &now, UpdatedAt: now})
+		if blockedErr != nil {
+			return 0, fmt.Errorf("failed to mark user as blocked: %w", blockedErr)
+		}
+	default:
+		recipient.Attempts++
+		recipient.LastError = new(err.Error())
+		recipient.NextAttemptAt = now.Add(campaignRetryInterval * time.Duration(recipient.Attempts))
+		if errors.Is(err, bot.ErrorBadRequest) || recipient.Attempts >= max(s.cfg.Campaigns.MaxAttempts, 1) {
+			recipient.Status = enums.CampaignRecipientStatusFailed
+		}
+		s.log.Error("Failed to send campaign message", err)
+	}
+
+	err = s.store.UpdateCampaignRecipient(ctx, recipient)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update campaign recipient: %w", err)
+	}
+	return retryAfter, nil
+}
+
+// sendCampaignMessage sends the campaign text, with the image and the Mini App button
+// when they are set, and returns the message id.
+func (s *Service) sendCampaignMessage(ctx context.Context, campaign *model.Campaign, chatID int64) (int, error) {
+	var markup models.ReplyMarkup
+	if campaign.ButtonText != nil {
+		markup = models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{{
+			Text:   *campaign.ButtonText,
+			WebApp: &models.WebAppInfo{URL: s.cfg.Telegram.MiniAppURL},
+		}}}}
+	}
+	text := bot.EscapeMarkdown(campaign.Text)
+
+	if campaign.Image == nil {
+		msg, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      chatID,
+			ParseMode:   models.ParseModeMarkdown,
+			Text:        text,
+			ReplyMarkup: markup,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return msg.ID, nil
+	}
+
+	var photo models.InputFile
+	if campaign.ImageFileID != nil {
+		photo = &models.InputFileString{Data: *campaign.ImageFileID}
+	} else {
+		file, err := os.Open(".data" + *campaign.Image)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open campaign image: %w", err)
+		}
+		defer file.Close()
+		photo = &models.InputFileUpload{Filename: path.Base(*campaign.Image), Data: file}
+	}
+	msg, err := s.bot.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:      chatID,
+		Photo:       photo,
+		Caption:     text,
+		ParseMode:   models.ParseModeMarkdown,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if campaign.ImageFileID == nil && len(msg.Photo) > 0 {
+		campaign.ImageFileID = new(msg.Photo[len(msg.Photo)-1].FileID)
+		err = s.store.UpdateCampaignImageFileID(ctx, campaign)
+		if err != nil {
+			s.log.Error("Failed to save campaign image file id", err)
+		}
+	}
+	return msg.ID, nil
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const notificationsCallbackPrefix = "notifications"
+
+// customerOrderStatusTexts lists the transitions customers are told about,
+// keyed by the new status. Other transitions are internal to managers.
+var customerOrderStatusTexts = map[enums.RequestStatus]string{
+	enums.RequestStatusInProgress:      "💼 *Ваш заказ \\#%s взят в работу*\n\nМенеджер уже занимается им и свяжется с вами, если понадобится что\\-то уточнить\\.",
+	enums.RequestStatusAwaitingPayment: "💳 *Заказ \\#%s готов и ожидает оплаты*\n\nМенеджер пришлёт счёт на оставшуюся сумму\\.",
+	enums.RequestStatusReviewed:        "✅ *Ваш заказ \\#%s выполнен*\n\nСпасибо, что выбрали OLA Studio\\! Будем рады видеть вас снова\\.",
+}
+
+func (s *Service) registerCustomerListeners() {
+	s.eventBus.OrderStatusChanged.Subscribe(func(ctx context.Context, change *model.OrderStatusChange) error {
+		if s.bot == nil || change == nil || change.Order == nil {
+			return nil
+		}
+
+		text, ok := customerOrderStatusTexts[change.Order.Status]
+		if !ok {
+			return nil
+		}
+
+		return s.notifyCustomer(ctx, change.Order, enums.NotificationEventOrderStatus, fmt.Sprintf(text, bot.EscapeMarkdown(strconv.Itoa(change.Order.ID))))
+	})
+
+	s.eventBus.OrderCommentCreated.Subscribe(func(ctx context.Context, comment *model.OrderComment) error {
+		if s.bot == nil || comment == nil || !comment.IsPublic || comment.Direction != enums.CommentDirectionInternal {
+			return nil
+		}
+
+		order, err := s.store.GetOrderByID(ctx, comment.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		return s.notifyCustomer(ctx, order, enums.NotificationEventOrderComment, fmt.Sprintf(
+			"💬 *Комментарий к заказу \\#%s*\n\n%s",
+			bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+			bot.EscapeMarkdown(comment.Content),
+		))
+	})
+}
+
+func (s *Service) notifyCustomer(ctx context.Context, order *model.Order, event enums.NotificationEvent, text string) error {
+	if order.UserID == nil {
+		return nil
+	}
+
+	user, err := s.store.GetUserByID(ctx, *order.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TID == nil {
+		return nil
+	}
+	enabled, err := s.notificationEnabled(ctx, user, enums.NotificationChannelTelegram, event)
+	if err != nil || !enabled {
+		return err
+	}
+
+	_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    *user.TID,
+		ParseMode: models.ParseModeMarkdown,
+		Text:      text,
+		ReplyMarkup: models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Посмотреть заказ", URL: miniAppLink("order", order.UUID)}},
+				notificationsKeyboardRow(true),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send customer telegram message: %w", err)
+	}
+
+	return nil
+}
+
+func notificationsKeyboardRow(enabled bool) []models.InlineKeyboardButton {
+	if enabled {
+		return []models.InlineKeyboardButton{{Text: "🔕 Отключить уведомления", CallbackData: notificationsCallbackPrefix + ":off"}}
+	}
+	return []models.InlineKeyboardButton{{Text: "🔔 Включить уведомления", CallbackData: notificationsCallbackPrefix + ":on"}}
+}
+
+func (s *Service) handleNotificationsCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	var enabled bool
+	switch callback.Data {
+	case notificationsCallbackPrefix + ":on":
+		enabled = true
+	case notificationsCallbackPrefix + ":off":
+		enabled = false
+	default:
+		return "Не удалось распарсить данные", nil
+	}
+
+	var err error
+	now := time.Now()
+	for _, event := range []enums.NotificationEvent{enums.NotificationEventOrderStatus, enums.NotificationEventOrderComment} {
+		err = s.store.UpsertNotificationPreference(ctx, user.ID, &model.NotificationPreference{
+			Channel: enums.NotificationChannelTelegram,
+			Event:   event,
+			Enabled: enabled,
+		}, now)
+		if err != nil {
+			return "Не удалось обновить настройки", fmt.Errorf("failed to update user notifications from telegram callback: %w", err)
+		}
+	}
+
+	if message := callback.Message.Message; message != nil && message.ReplyMarkup != nil {
+		keyboard := message.ReplyMarkup.InlineKeyboard
+		if len(keyboard) > 0 {
+			keyboard[len(keyboard)-1] = notificationsKeyboardRow(enabled)
+			_, err = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+				ChatID:      message.Chat.ID,
+				MessageID:   message.ID,
+				ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: keyboard},
+			})
+			if err != nil {
+				s.log.Error("Failed to edit notifications keyboard", err)
+			}
+		}
+	}
+
+	if enabled {
+		return "Уведомления включены", nil
+	}
+	return "Уведомления отключены", nil
+}
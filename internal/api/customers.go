@@ -0,0 +1,347 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// linkCustomer returns the ID of the customer owning the phone, creating the customer on
+// the first request. Phones that can not be normalized are left without a customer.
+func (s *Service) linkCustomer(ctx context.Context, phone, name string, userID *int) (*int, error) {
+	key, ok := phoneKey(phone)
+	if !ok {
+		return nil, nil
+	}
+	customer, err := s.store.GetOrCreateCustomerByPhone(ctx, key, name, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link customer: %w", err)
+	}
+	return &customer.ID, nil
+}
+
+// phoneKey converts a Russian phone number to "+7XXXXXXXXXX", the key customers are deduplicated by.
+func phoneKey(s string) (string, bool) {
+	digits, ok := phoneDigits(s)
+	if !ok {
+		return "", false
+	}
+	return "+7" + digits, true
+}
+
+func (s *Service) getCustomers(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	var key string
+	if phone := r.URL.Query().Get("phone"); phone != "" {
+		var ok bool
+		key, ok = phoneKey(phone)
+		if !ok {
+			return core.Err(http.StatusBadRequest, fmt.Errorf("invalid phone"))
+		}
+	}
+
+	customers, err := s.store.GetCustomers(r.Context(), key, normalizeTag(r.URL.Query().Get("tag")))
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customers: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, customers)
+}
+
+func (s *Service) getCustomer(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid customer uuid: %w", err))
+	}
+
+	customer, err := s.store.GetCustomerByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("customer not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+
+	card := &models.CustomerCard{Customer: *customer}
+	if customer.UserID != nil {
+		card.User, err = s.store.GetUserByID(r.Context(), *customer.UserID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get user: %w", err))
+		}
+	}
+
+	card.Notes, err = s.store.GetCustomerNotesByCustomerID(r.Context(), customer.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get notes: %w", err))
+	}
+
+	card.Orders, err = s.store.GetOrdersByCustomerID(r.Context(), customer.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get orders: %w", err))
+	}
+	err = s.attachOrderDetails(r.Context(), card.Orders)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load order details: %w", err))
+	}
+
+	card.Feedback, err = s.store.GetFeedbackByCustomerID(r.Context(), customer.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get feedback: %w", err))
+	}
+	err = s.attachFeedbackDetails(r.Context(), card.Feedback)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load feedback details: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, card)
+}
+
+type mergeCustomersRequest struct {
+	CustomerUUIDs []uuid.UUID `json:"customer_uuids" validate:"required,min=1,max=20"`
+}
+
+// mergeCustomers moves orders, feedback, phones, notes and tags of duplicate customers to the one in the path.
+func (s *Service) mergeCustomers(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid customer uuid: %w", err))
+	}
+
+	req, res := core.Validate[mergeCustomersRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+	if slices.Contains(req.CustomerUUIDs, uid) {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("customer can not be merged into itself"))
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	target, err := s.store.GetCustomerByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("customer not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+
+	sourceIDs := make([]int, 0, len(req.CustomerUUIDs))
+	for _, sourceUUID := range req.CustomerUUIDs {
+		source, err := s.store.GetCustomerByUUID(ctx, sourceUUID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return core.Err(http.StatusNotFound, fmt.Errorf("customer %s not found", sourceUUID))
+			}
+			return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+		}
+		if source.UserID != nil {
+			if target.UserID != nil && *target.UserID != *source.UserID {
+				return core.Err(http.StatusConflict, fmt.Errorf("customers belong to different users"))
+			}
+			target.UserID = source.UserID
+		}
+		target.Tags = mergeTags(target.Tags, source.Tags)
+		sourceIDs = append(sourceIDs, source.ID)
+	}
+
+	target.UpdatedAt = time.Now()
+	err = s.store.MergeCustomers(ctx, target, sourceIDs)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to merge customers: %w", err))
+	}
+
+	target, err = s.store.GetCustomerByUUID(ctx, uid)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	return core.JSON(http.StatusOK, target)
+}
+
+type updateCustomerTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=64"`
+}
+
+func (s *Service) updateCustomerTags(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid customer uuid: %w", err))
+	}
+
+	req, res := core.Validate[updateCustomerTagsRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	customer, err := s.store.GetCustomerByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("customer not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+
+	customer.Tags = mergeTags(nil, req.Tags)
+	customer.UpdatedAt = time.Now()
+	err = s.store.UpdateCustomerTags(r.Context(), customer)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update customer tags: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, customer)
+}
+
+func (s *Service) getCustomerTags(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	tags, err := s.store.GetCustomerTags(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get tags: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, tags)
+}
+
+// normalizeTag makes tags case-insensitive, so "VIP" and "vip" are the same tag.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// mergeTags appends normalized tags that are not in the list yet.
+func mergeTags(tags []string, more []string) []string {
+	result := make([]string, 0, len(tags)+len(more))
+	for _, tag := range slices.Concat(tags, more) {
+		tag = normalizeTag(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+type createCustomerNoteRequest struct {
+	Content string `json:"content" mold:"trim" validate:"required,max=3000"`
+}
+
+func (s *Service) createCustomerNote(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid customer uuid: %w", err))
+	}
+
+	req, res := core.Validate[createCustomerNoteRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	customer, err := s.store.GetCustomerByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("customer not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+
+	noteUUID, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	note := &models.CustomerNote{
+		UUID:       noteUUID,
+		CustomerID: customer.ID,
+		UserID:     &user.ID,
+		Content:    req.Content,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	err = s.store.CreateCustomerNote(r.Context(), note)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create customer note: %w", err))
+	}
+
+	return core.JSON(http.StatusCreated, note)
+}
+
+// deleteCustomerNote lets the author or an admin remove a note.
+func (s *Service) deleteCustomerNote(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	customerUUID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid customer uuid: %w", err))
+	}
+	noteUUID, err := uuid.Parse(r.PathValue("note_uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid note uuid: %w", err))
+	}
+
+	customer, err := s.store.GetCustomerByUUID(r.Context(), customerUUID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("customer not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get customer: %w", err))
+	}
+	note, err := s.store.GetCustomerNoteByUUID(r.Context(), noteUUID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get note: %w", err))
+	}
+	if note == nil || note.CustomerID != customer.ID {
+		return core.Err(http.StatusNotFound, fmt.Errorf("note not found"))
+	}
+	if user.Role != enums.UserRoleAdmin && (note.UserID == nil || *note.UserID != user.ID) {
+		return core.Err(http.StatusForbidden, fmt.Errorf("only the author or an admin can delete the note"))
+	}
+
+	err = s.store.DeleteCustomerNote(r.Context(), note)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to delete note: %w", err))
+	}
+
+	return core.JSON(http.StatusNoContent, nil)
+}
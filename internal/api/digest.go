@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	digestStaleLimit         = 15
+	defaultDigestStaleAfter  = 24 * time.Hour
+	defaultDigestTopProducts = 5
+)
+
+func (s *Service) sendDigest(ctx context.Context, title string, period time.Duration) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	now := time.Now()
+	staleAfter := s.digestStaleAfter()
+	digest, err := s.store.GetDigest(ctx, now.Add(-period), now, now.Add(-staleAfter), s.digestTopProducts(), digestStaleLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:             s.cfg.Telegram.GroupID,
+		ParseMode:          models.ParseModeMarkdown,
+		Text:               buildDigestTelegramText(title, digest, staleAfter),
+		LinkPreviewOptions: &models.LinkPreviewOptions{IsDisabled: new(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send digest telegram message: %w", err)
+	}
+
+	return nil
+}
+
+// digestStaleAfter falls back to a default when the digest is not configured, since /stats uses it too.
+func (s *Service) digestStaleAfter() time.Duration {
+	if s.cfg.Digest.StaleAfter <= 0 {
+		return defaultDigestStaleAfter
+	}
+	return s.cfg.Digest.StaleAfter
+}
+
+func (s *Service) digestTopProducts() int {
+	if s.cfg.Digest.TopProducts <= 0 {
+		return defaultDigestTopProducts
+	}
+	return s.cfg.Digest.TopProducts
+}
+
+func buildDigestTelegramText(title string, digest *model.Digest, staleAfter time.Duration) string {
+	b := strings.Builder{}
+	fmt.Fprintf(
+		&b,
+		"📊 *%s*\n_%s – %s_\n\n",
+		bot.EscapeMarkdown(title),
+		bot.EscapeMarkdown(digest.From.Format("02.01.2006 15:04")),
+		bot.EscapeMarkdown(digest.To.Format("02.01.2006 15:04")),
+	)
+	fmt.Fprintf(
+		&b,
+		"*Заказы*\n*– Новые\\:* %d\n*– В работе\\:* %d\n*– Зависшие\\:* %d\n\n",
+		digest.NewOrders, digest.InProgressOrders, digest.StaleOrders,
+	)
+	fmt.Fprintf(
+		&b,
+		"*Обратная связь*\n*– Новые\\:* %d\n*– В работе\\:* %d\n*– Зависшие\\:* %d\n",
+		digest.NewFeedback, digest.InProgressFeedback, digest.StaleFeedback,
+	)
+
+	if len(digest.TopProducts) > 0 {
+		b.WriteString("\n*Популярные товары*\n")
+		for i, product := range digest.TopProducts {
+			fmt.Fprintf(
+				&b,
+				"%d\\. %s — %d шт\\. в %d зак\\.\n",
+				i+1,
+				bot.EscapeMarkdown(product.ProductName),
+				product.Qty,
+				product.Orders,
+			)
+		}
+	}
+
+	if len(digest.OrdersBySource) > 0 {
+		b.WriteString("\n*Заказы по источникам*\n")
+		for _, source := range digest.OrdersBySource {
+			fmt.Fprintf(&b, "*– %s\\:* %d\n", bot.EscapeMarkdown(source.Source.Label()), source.Count)
+		}
+	}
+
+	if len(digest.StaleRequests) > 0 {
+		fmt.Fprintf(&b, "\n*Без движения более %s*\n", bot.EscapeMarkdown(formatDuration(staleAfter)))
+		for _, item := range digest.StaleRequests {
+			kind := "Заказ"
+			if item.Kind == "feedback" {
+				kind = "Заявка"
+			}
+			fmt.Fprintf(
+				&b,
+				"%s [%s \\#%s](%s) — %s, %s\n",
+				item.Status.Emoji(),
+				kind,
+				bot.EscapeMarkdown(strconv.Itoa(item.ID)),
+				bot.EscapeMarkdown(miniAppLink(item.Kind, item.UUID)),
+				bot.EscapeMarkdown(item.Name),
+				bot.EscapeMarkdown(item.UpdatedAt.Format("02.01 15:04")),
+			)
+		}
+	}
+
+	return b.String()
+}
+
+func formatDuration(d time.Duration) string {
+	days := int(d / (time.Hour * 24))
+	hours := int(d % (time.Hour * 24) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	parts := make([]string, 0, 3)
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%d д.", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d ч.", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d мин.", minutes))
+	}
+	return strings.Join(parts, " ")
+}
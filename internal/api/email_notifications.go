@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+type emailData struct {
+	URL     string
+	Name    string
+	Order   *model.Order
+	Ratings []ratingLink
+}
+
+func (s *Service) registerEmailListeners() {
+	s.eventBus.UserRegistered.Subscribe(func(ctx context.Context, user *model.User) error {
+		if !s.mailer.Enabled() || user == nil || user.Email == nil {
+			return nil
+		}
+
+		enabled, err := s.notificationEnabled(ctx, user, enums.NotificationChannelEmail, enums.NotificationEventRegistration)
+		if err != nil || !enabled {
+			return err
+		}
+
+		err = s.mailer.Send(ctx, *user.Email, "registration", emailData{URL: s.cfg.App.URL, Name: user.FirstName})
+		if err != nil {
+			return fmt.Errorf("failed to send registration email: %w", err)
+		}
+		return nil
+	})
+
+	s.eventBus.OrderCreated.Subscribe(func(ctx context.Context, order *model.Order) error {
+		if !s.mailer.Enabled() || order == nil {
+			return nil
+		}
+
+		user, err := s.getOrderEmailRecipient(ctx, order)
+		if err != nil || user == nil {
+			return err
+		}
+
+		orderList := []model.Order{*order}
+		err = s.attachOrderDetails(ctx, orderList)
+		if err != nil {
+			return fmt.Errorf("failed to load order details: %w", err)
+		}
+
+		err = s.mailer.Send(ctx, *user.Email, "order_created", emailData{URL: s.cfg.App.URL, Name: order.Name, Order: &orderList[0]})
+		if err != nil {
+			return fmt.Errorf("failed to send order created email: %w", err)
+		}
+		return nil
+	})
+
+	s.eventBus.OrderStatusChanged.Subscribe(func(ctx context.Context, change *model.OrderStatusChange) error {
+		if !s.mailer.Enabled() || change == nil || change.Order == nil {
+			return nil
+		}
+		if _, ok := customerOrderStatusTexts[change.Order.Status]; !ok {
+			return nil
+		}
+
+		user, err := s.getOrderEmailRecipient(ctx, change.Order)
+		if err != nil || user == nil {
+			return err
+		}
+
+		err = s.mailer.Send(ctx, *user.Email, "order_status_changed", emailData{URL: s.cfg.App.URL, Name: change.Order.Name, Order: change.Order})
+		if err != nil {
+			return fmt.Errorf("failed to send order status email: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) getOrderEmailRecipient(ctx context.Context, order *model.Order) (*model.User, error) {
+	if order.UserID == nil {
+		return nil, nil
+	}
+
+	user, err := s.store.GetUserByID(ctx, *order.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Email == nil {
+		return nil, nil
+	}
+	enabled, err := s.notificationEnabled(ctx, user, enums.NotificationChannelEmail, enums.NotificationEventOrderStatus)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	return user, nil
+}
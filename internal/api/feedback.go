@@ -13,6 +13,8 @@ import (
 	"github.com/zagvozdeen/ola/internal/store/models"
 )
 
+var errFeedbackConverted = errors.New("feedback is already converted to an order")
+
 type createFeedbackRequest struct {
 	Name    string `json:"name" mold:"trim" validate:"required,max=255"`
 	Phone   string `json:"phone" mold:"trim" validate:"required,max=255,ru_phone"`
@@ -30,6 +32,10 @@ func (s *Service) getFeedback(r *http.Request, user *models.User) core.Response
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get feedback: %w", err))
 	}
+	err = s.attachFeedbackDetails(r.Context(), feedback)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load feedback details: %w", err))
+	}
 	return core.JSON(http.StatusOK, feedback)
 }
 
@@ -52,7 +58,12 @@ func (s *Service) getFeedbackByUUID(r *http.Request, user *models.User) core.Res
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get feedback: %w", err))
 	}
 
-	return core.JSON(http.StatusOK, feedback)
+	feedbackList := []models.Feedback{*feedback}
+	err = s.attachFeedbackDetails(r.Context(), feedbackList)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load feedback details: %w", err))
+	}
+	return core.JSON(http.StatusOK, feedbackList[0])
 }
 
 func (s *Service) createFeedback(r *http.Request, user *models.User) core.Response {
@@ -71,34 +82,49 @@ func (s *Service) createFeedback(r *http.Request, user *models.User) core.Respon
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("source must be an enums.OrderSource"))
 	}
 
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
 	user.Phone = new(req.Phone)
 	user.UpdatedAt = time.Now()
-	err = s.store.UpdateUserPhone(r.Context(), user)
+	err = s.store.UpdateUserPhone(ctx, user)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update user phone: %w", err))
 	}
 
+	customerID, err := s.linkCustomer(ctx, req.Phone, req.Name, &user.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
 	uid, err := uuid.NewV7()
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
 	}
 	feedback := &models.Feedback{
-		UUID:      uid,
-		Status:    enums.RequestStatusCreated,
-		Source:    src,
-		Type:      feedbackType,
-		Name:      req.Name,
-		Phone:     req.Phone,
-		Content:   req.Content,
-		UserID:    user.ID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-	err = s.store.CreateFeedback(r.Context(), feedback)
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		Source:          src,
+		Type:            feedbackType,
+		Name:            req.Name,
+		Phone:           req.Phone,
+		Content:         req.Content,
+		UserID:          user.ID,
+		CustomerID:      customerID,
+		StatusChangedAt: time.Now(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	err = s.store.CreateFeedback(ctx, feedback)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create feedback: %w", err))
 	}
 
+	s.store.Commit(ctx)
+
 	s.eventBus.FeedbackCreated.Publish(context.WithoutCancel(r.Context()), feedback)
 
 	return core.JSON(http.StatusCreated, feedback)
@@ -117,26 +143,42 @@ func (s *Service) createGuestFeedback(r *http.Request) core.Response {
 		return res
 	}
 
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	customerID, err := s.linkCustomer(ctx, req.Phone, req.Name, nil)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
 	uid, err := uuid.NewV7()
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
 	}
 	feedback := &models.Feedback{
-		UUID:      uid,
-		Status:    enums.RequestStatusCreated,
-		Source:    enums.OrderSourceLanding,
-		Type:      enums.FeedbackTypeFeedbackRequest,
-		Name:      req.Name,
-		Phone:     req.Phone,
-		Content:   req.Content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-	err = s.store.CreateFeedback(r.Context(), feedback)
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		Source:          enums.OrderSourceLanding,
+		Type:            enums.FeedbackTypeFeedbackRequest,
+		Name:            req.Name,
+		Phone:           req.Phone,
+		Content:         req.Content,
+		CustomerID:      customerID,
+		Attribution:     attributionFromRequest(r),
+		StatusChangedAt: time.Now(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	err = s.store.CreateFeedback(ctx, feedback)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create guest feedback: %w", err))
 	}
 
+	s.store.Commit(ctx)
+
 	s.eventBus.FeedbackCreated.Publish(context.WithoutCancel(r.Context()), feedback)
 
 	return core.JSON(http.StatusCreated, feedback)
@@ -162,6 +204,10 @@ func (s *Service) updateFeedbackStatus(r *http.Request, user *models.User) core.
 		return res
 	}
 
+	if req.Status == enums.RequestStatusAwaitingPayment {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid feedback status: %s", req.Status.String()))
+	}
+
 	//status, err := enums.NewRequestStatus(req.Status)
 	//if err != nil {
 	//	return core.Err(http.StatusBadRequest, fmt.Errorf("invalid feedback status: %w", err))
@@ -175,8 +221,7 @@ func (s *Service) updateFeedbackStatus(r *http.Request, user *models.User) core.
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get feedback: %w", err))
 	}
 
-	feedback.Status = req.Status
-	feedback.UpdatedAt = time.Now()
+	setFeedbackStatus(feedback, req.Status, user, time.Now())
 	err = s.store.UpdateFeedbackStatus(r.Context(), feedback)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update feedback status: %w", err))
@@ -187,7 +232,142 @@ func (s *Service) updateFeedbackStatus(r *http.Request, user *models.User) core.
 	//	return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load updated feedback: %w", err))
 	//}
 
+	s.setFeedbackSLA(feedback, time.Now())
 	s.eventBus.FeedbackChanged.Publish(context.WithoutCancel(r.Context()), feedback)
 
 	return core.JSON(http.StatusOK, feedback)
 }
+
+// createOrderFromFeedback turns the feedback into an order pre-filled with its contacts and content.
+func (s *Service) createOrderFromFeedback(r *http.Request, user *models.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid feedback uuid: %w", err))
+	}
+
+	feedback, err := s.store.GetFeedbackByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("feedback not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get feedback: %w", err))
+	}
+
+	order, err := s.convertFeedbackToOrder(r.Context(), feedback, user)
+	if err != nil {
+		if errors.Is(err, errFeedbackConverted) {
+			return core.Err(http.StatusConflict, err)
+		}
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
+	return core.JSON(http.StatusCreated, order)
+}
+
+// convertFeedbackToOrder creates the order from the feedback, links both records and closes
+// the feedback. It is shared by the API and the button on feedback messages in Telegram.
+func (s *Service) convertFeedbackToOrder(ctx context.Context, feedback *models.Feedback, user *models.User) (*models.Order, error) {
+	if feedback.OrderID != nil {
+		return nil, errFeedbackConverted
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	now := time.Now()
+	order := &models.Order{
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		PaymentStatus:   enums.OrderPaymentStatusUnpaid,
+		Source:          feedback.Source,
+		DeliveryType:    enums.DeliveryTypePickup,
+		Name:            feedback.Name,
+		Phone:           feedback.Phone,
+		Content:         feedback.Content,
+		CustomerID:      feedback.CustomerID,
+		FeedbackID:      &feedback.ID,
+		Attribution:     feedback.Attribution,
+		StatusChangedAt: now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if feedback.UserID != 0 {
+		order.UserID = &feedback.UserID
+	}
+	err = s.store.CreateOrder(txCtx, order)
+	if err != nil {
+		if errors.Is(err, models.ErrUniqueViolation) {
+			return nil, errFeedbackConverted
+		}
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	feedback.OrderID = &order.ID
+	setFeedbackStatus(feedback, enums.RequestStatusReviewed, user, now)
+	err = s.store.UpdateFeedbackOrder(txCtx, feedback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link feedback to order: %w", err)
+	}
+	err = s.store.UpdateFeedbackStatus(txCtx, feedback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update feedback status: %w", err)
+	}
+
+	s.store.Commit(txCtx)
+
+	s.eventBus.OrderCreated.Publish(context.WithoutCancel(ctx), order)
+	s.eventBus.FeedbackChanged.Publish(context.WithoutCancel(ctx), feedback)
+
+	return order, nil
+}
+
+func setFeedbackStatus(feedback *models.Feedback, status enums.RequestStatus, user *models.User, now time.Time) {
+	if feedback.Status != status {
+		feedback.Status = status
+		feedback.StatusChangedAt = now
+	}
+	if status == enums.RequestStatusInProgress {
+		feedback.AssigneeID = &user.ID
+	}
+	feedback.UpdatedAt = now
+}
+
+func (s *Service) attachFeedbackDetails(ctx context.Context, feedback []models.Feedback) error {
+	if len(feedback) == 0 {
+		return nil
+	}
+
+	feedbackIDs := make([]int, 0, len(feedback))
+	for i := range feedback {
+		feedback[i].Comments = make([]models.FeedbackComment, 0)
+		feedbackIDs = append(feedbackIDs, feedback[i].ID)
+	}
+
+	commentsByFeedbackID, err := s.store.GetFeedbackCommentsByFeedbackIDs(ctx, feedbackIDs)
+	if err != nil {
+		return err
+	}
+
+	s.applyFeedbackSLA(feedback, time.Now())
+
+	for i := range feedback {
+		if comments, ok := commentsByFeedbackID[feedback[i].ID]; ok {
+			feedback[i].Comments = comments
+		}
+	}
+
+	return nil
+}
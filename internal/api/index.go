@@ -27,6 +27,7 @@ type PageData struct {
 	Products              []models.Product
 	Services              []models.Product
 	CatalogProducts       []models.Product
+	Reviews               []models.Review
 	Categories            []models.Category
 	SelectedCategorySlugs map[string]bool
 	SelectedCatalogType   string
@@ -101,6 +102,8 @@ func (s *Service) renderMainPage(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	s.captureAttribution(w, r)
+
 	var templates *template.Template
 	templates, err = s.getTemplates()
 	if err != nil {
@@ -153,6 +156,12 @@ func (s *Service) renderMainPage(w http.ResponseWriter, r *http.Request) {
 			s.log.Error("Failed to get categories", err)
 			return
 		}
+
+		pageData.Reviews, err = s.store.GetApprovedReviews(r.Context(), nil, landingReviewsLimit)
+		if err != nil {
+			s.log.Error("Failed to get reviews", err)
+			return
+		}
 	case "catalog.html":
 		pageData.Categories, err = s.store.GetAllCategories(r.Context())
 		if err != nil {
@@ -169,6 +178,18 @@ func (s *Service) renderMainPage(w http.ResponseWriter, r *http.Request) {
 			s.log.Error("Failed to get catalog products", err)
 			return
 		}
+
+		if len(pageData.CatalogProducts) > 0 {
+			productIDs := make([]int, 0, len(pageData.CatalogProducts))
+			for _, product := range pageData.CatalogProducts {
+				productIDs = append(productIDs, product.ID)
+			}
+			pageData.Reviews, err = s.store.GetApprovedReviews(r.Context(), productIDs, landingReviewsLimit)
+			if err != nil {
+				s.log.Error("Failed to get reviews", err)
+				return
+			}
+		}
 	}
 
 	err = templates.ExecuteTemplate(w, page, pageData)
@@ -214,7 +235,7 @@ func (s *Service) getTemplates() (templates *template.Template, err error) {
 	defer s.mu.Unlock()
 
 	if s.templates == nil {
-		templates, err = template.ParseFiles("templates/index.html", "templates/catalog.html", "templates/delivery.html", "templates/privacy.html", "templates/templates.html")
+		templates, err = template.ParseFiles("templates/index.html", "templates/catalog.html", "templates/delivery.html", "templates/privacy.html", "templates/rating.html", "templates/templates.html")
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse template: %w", err)
 		}
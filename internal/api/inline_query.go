@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	inlineQueryLimit              = 20
+	inlineCaptionDescriptionLimit = 700
+)
+
+func isInlineQuery(update *models.Update) bool {
+	return update.InlineQuery != nil
+}
+
+func (s *Service) handleInlineQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.InlineQuery
+
+	offset, _ := strconv.Atoi(query.Offset)
+	products, err := s.store.SearchProducts(ctx, strings.TrimSpace(query.Query), inlineQueryLimit, offset)
+	if err != nil {
+		s.log.Error("Failed to search products", err)
+		return
+	}
+
+	results := make([]models.InlineQueryResult, 0, len(products))
+	for _, product := range products {
+		results = append(results, s.buildProductInlineResult(product))
+	}
+
+	params := &bot.AnswerInlineQueryParams{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     60,
+	}
+	if len(products) == inlineQueryLimit {
+		params.NextOffset = strconv.Itoa(offset + inlineQueryLimit)
+	}
+
+	_, err = b.AnswerInlineQuery(ctx, params)
+	if err != nil {
+		s.log.Error("Failed to answer inline query", err)
+	}
+}
+
+func (s *Service) buildProductInlineResult(product model.Product) models.InlineQueryResult {
+	photoURL := strings.TrimSuffix(s.cfg.App.URL, "/") + product.FileContent
+	return &models.InlineQueryResultPhoto{
+		ID:           product.UUID.String(),
+		PhotoURL:     photoURL,
+		ThumbnailURL: photoURL,
+		Title:        product.Name,
+		Description:  formatPriceRange(product.PriceFrom, product.PriceTo),
+		Caption: fmt.Sprintf(
+			"*%s*\n%s\n\n%s",
+			bot.EscapeMarkdown(product.Name),
+			bot.EscapeMarkdown(formatPriceRange(product.PriceFrom, product.PriceTo)),
+			bot.EscapeMarkdown(truncateText(product.Description, inlineCaptionDescriptionLimit)),
+		),
+		ParseMode: models.ParseModeMarkdown,
+		ReplyMarkup: models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Заказать", URL: miniAppLink("product", product.UUID)}},
+			},
+		},
+	}
+}
+
+func formatPriceRange(from int, to *int) string {
+	if to != nil {
+		return fmt.Sprintf("от %d ₽ до %d ₽", from, *to)
+	}
+	return fmt.Sprintf("от %d ₽", from)
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/scheduler"
+)
+
+func (s *Service) registerJobs() error {
+	if s.cfg.Digest.Enabled {
+		dailyAt, err := scheduler.ParseTimeOfDay(s.cfg.Digest.DailyAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse digest daily time: %w", err)
+		}
+		weeklyAt, err := scheduler.ParseTimeOfDay(s.cfg.Digest.WeeklyAt)
+		if err != nil {
+			return fmt.Errorf("failed to parse digest weekly time: %w", err)
+		}
+		weeklyDay, err := scheduler.ParseWeekday(s.cfg.Digest.WeeklyDay)
+		if err != nil {
+			return fmt.Errorf("failed to parse digest weekday: %w", err)
+		}
+
+		s.scheduler.Daily("daily_digest", dailyAt, func(ctx context.Context) error {
+			return s.sendDigest(ctx, "Ежедневная сводка", time.Hour*24)
+		})
+		s.scheduler.Weekly("weekly_digest", weeklyDay, weeklyAt, func(ctx context.Context) error {
+			return s.sendDigest(ctx, "Еженедельная сводка", time.Hour*24*7)
+		})
+	}
+
+	if s.cfg.SLA.Enabled && s.cfg.SLA.CheckInterval > 0 {
+		s.scheduler.Every("sla_check", s.cfg.SLA.CheckInterval, s.checkSLA)
+	}
+
+	if s.receipts != nil {
+		s.scheduler.Every("fiscal_receipts", s.receiptRetryInterval(), s.processDueReceipts)
+	}
+
+	if s.cfg.Campaigns.Enabled && s.cfg.Campaigns.CheckInterval > 0 {
+		s.scheduler.Every("campaigns", s.cfg.Campaigns.CheckInterval, s.processCampaigns)
+	}
+
+	if s.cfg.Ratings.Enabled && s.cfg.Ratings.CheckInterval > 0 {
+		s.scheduler.Every("order_review_requests", s.cfg.Ratings.CheckInterval, s.processOrderReviewRequests)
+	}
+
+	return nil
+}
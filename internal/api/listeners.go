@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -14,8 +15,11 @@ import (
 	model "github.com/zagvozdeen/ola/internal/store/models"
 )
 
+const botURL = "https://t.me/ola_studio_bot"
+
 const orderCallbackPrefix = "order_status"
 const feedbackCallbackPrefix = "feedback_status"
+const feedbackOrderCallbackPrefix = "feedback_order"
 
 func (s *Service) registerListeners() {
 	s.eventBus.OrderCreated.Subscribe(func(ctx context.Context, order *model.Order) error {
@@ -32,26 +36,36 @@ func (s *Service) registerListeners() {
 			}
 		}
 
-		message, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:      s.cfg.Telegram.GroupID,
-			ParseMode:   models.ParseModeMarkdown,
-			Text:        buildOrderTelegramText(order, user),
-			ReplyMarkup: getKeyboard(order.Status, orderCallbackPrefix, order.ID, order.UUID),
-		})
+		destinations, err := s.orderDestinations(ctx, order)
 		if err != nil {
-			return fmt.Errorf("failed to send order telegram message: %w", err)
+			return err
 		}
 
-		err = s.store.CreateOrderTelegramMessage(ctx, &model.OrderTelegramMessage{
-			OrderID:   order.ID,
-			ChatID:    message.Chat.ID,
-			MessageID: int64(message.ID),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to save order telegram message: %w", err)
+		var errs []error
+		for _, destination := range destinations {
+			message, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:          destination.chatID,
+				MessageThreadID: destination.topicID,
+				ParseMode:       models.ParseModeMarkdown,
+				Text:            buildOrderTelegramText(order, user),
+				ReplyMarkup:     getKeyboard(order.Status, orderCallbackPrefix, order.ID, order.UUID),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send order telegram message to chat %d: %w", destination.chatID, err))
+				continue
+			}
+
+			err = s.store.CreateOrderTelegramMessage(ctx, &model.OrderTelegramMessage{
+				OrderID:   order.ID,
+				ChatID:    message.Chat.ID,
+				MessageID: int64(message.ID),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to save order telegram message: %w", err))
+			}
 		}
 
-		return nil
+		return errors.Join(errs...)
 	})
 
 	s.eventBus.OrderChanged.Subscribe(func(ctx context.Context, order *model.Order) error {
@@ -68,26 +82,26 @@ func (s *Service) registerListeners() {
 			}
 		}
 
-		message, err := s.store.GetOrderTelegramMessageByOrderID(ctx, order.ID)
+		messages, err := s.store.GetOrderTelegramMessagesByOrderID(ctx, order.ID)
 		if err != nil {
-			if errors.Is(err, model.ErrNotFound) {
-				return nil
-			}
-			return fmt.Errorf("failed to load order telegram message: %w", err)
+			return fmt.Errorf("failed to load order telegram messages: %w", err)
 		}
 
-		_, err = s.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-			ChatID:      message.ChatID,
-			MessageID:   int(message.MessageID),
-			ParseMode:   models.ParseModeMarkdown,
-			Text:        buildOrderTelegramText(order, user),
-			ReplyMarkup: getKeyboard(order.Status, orderCallbackPrefix, order.ID, order.UUID),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to edit order telegram message: %w", err)
+		var errs []error
+		for _, message := range messages {
+			_, err = s.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+				ChatID:      message.ChatID,
+				MessageID:   int(message.MessageID),
+				ParseMode:   models.ParseModeMarkdown,
+				Text:        buildOrderTelegramText(order, user),
+				ReplyMarkup: getKeyboard(order.Status, orderCallbackPrefix, order.ID, order.UUID),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to edit order telegram message %d: %w", message.MessageID, err))
+			}
 		}
 
-		return nil
+		return errors.Join(errs...)
 	})
 
 	s.eventBus.FeedbackCreated.Subscribe(func(ctx context.Context, feedback *model.Feedback) error {
@@ -102,26 +116,31 @@ func (s *Service) registerListeners() {
 			return fmt.Errorf("failed to get user: %w", err)
 		}
 
-		message, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:      s.cfg.Telegram.GroupID,
-			ParseMode:   models.ParseModeMarkdown,
-			Text:        buildFeedbackTelegramText(feedback, user),
-			ReplyMarkup: getKeyboard(feedback.Status, feedbackCallbackPrefix, feedback.ID, feedback.UUID),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to send telegram message: %w", err)
-		}
+		var errs []error
+		for _, destination := range s.feedbackDestinations(feedback) {
+			message, err := s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:          destination.chatID,
+				MessageThreadID: destination.topicID,
+				ParseMode:       models.ParseModeMarkdown,
+				Text:            buildFeedbackTelegramText(feedback, user),
+				ReplyMarkup:     feedbackKeyboard(feedback),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send telegram message to chat %d: %w", destination.chatID, err))
+				continue
+			}
 
-		err = s.store.CreateFeedbackTelegramMessage(ctx, &model.FeedbackTelegramMessage{
-			FeedbackID: feedback.ID,
-			ChatID:     message.Chat.ID,
-			MessageID:  int64(message.ID),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to save feedback telegram message: %w", err)
+			err = s.store.CreateFeedbackTelegramMessage(ctx, &model.FeedbackTelegramMessage{
+				FeedbackID: feedback.ID,
+				ChatID:     message.Chat.ID,
+				MessageID:  int64(message.ID),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to save feedback telegram message: %w", err))
+			}
 		}
 
-		return nil
+		return errors.Join(errs...)
 	})
 
 	s.eventBus.FeedbackChanged.Subscribe(func(ctx context.Context, feedback *model.Feedback) error {
@@ -136,38 +155,148 @@ func (s *Service) registerListeners() {
 			return fmt.Errorf("failed to get user: %w", err)
 		}
 
-		message, err := s.store.GetFeedbackTelegramMessageByFeedbackID(ctx, feedback.ID)
+		messages, err := s.store.GetFeedbackTelegramMessagesByFeedbackID(ctx, feedback.ID)
 		if err != nil {
-			if errors.Is(err, model.ErrNotFound) {
-				return nil
+			return fmt.Errorf("failed to load feedback telegram messages: %w", err)
+		}
+
+		var errs []error
+		for _, message := range messages {
+			_, err = s.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
+				ChatID:      message.ChatID,
+				MessageID:   int(message.MessageID),
+				ParseMode:   models.ParseModeMarkdown,
+				Text:        buildFeedbackTelegramText(feedback, user),
+				ReplyMarkup: feedbackKeyboard(feedback),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to edit feedback telegram message %d: %w", message.MessageID, err))
 			}
-			return fmt.Errorf("failed to load order telegram message: %w", err)
 		}
 
-		_, err = s.bot.EditMessageText(ctx, &bot.EditMessageTextParams{
-			ChatID:      message.ChatID,
-			MessageID:   int(message.MessageID),
-			ParseMode:   models.ParseModeMarkdown,
-			Text:        buildFeedbackTelegramText(feedback, user),
-			ReplyMarkup: getKeyboard(feedback.Status, feedbackCallbackPrefix, feedback.ID, feedback.UUID),
-		})
+		return errors.Join(errs...)
+	})
+
+	s.eventBus.OrderCreated.Subscribe(func(ctx context.Context, order *model.Order) error {
+		if order == nil {
+			return nil
+		}
+		return s.notifyStaffAboutOrder(ctx, order)
+	})
+
+	s.eventBus.FeedbackCreated.Subscribe(func(ctx context.Context, feedback *model.Feedback) error {
+		if s.bot == nil || feedback == nil {
+			return nil
+		}
+
+		user, err := s.store.GetUserByID(ctx, feedback.UserID)
 		if err != nil {
-			return fmt.Errorf("failed to edit feedback telegram message: %w", err)
+			return fmt.Errorf("failed to get user: %w", err)
 		}
 
-		return nil
+		recipients, err := s.getNotificationRecipients(ctx, enums.NotificationChannelTelegram, enums.NotificationEventNewFeedback)
+		if err != nil {
+			return err
+		}
+
+		text := buildFeedbackTelegramText(feedback, user)
+		keyboard := models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Посмотреть заявку", URL: miniAppLink("feedback", feedback.UUID)}},
+			},
+		}
+		var errs []error
+		for _, recipient := range recipients {
+			if recipient.TID == nil || recipient.ID == feedback.UserID {
+				continue
+			}
+			_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:      *recipient.TID,
+				ParseMode:   models.ParseModeMarkdown,
+				Text:        text,
+				ReplyMarkup: keyboard,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send feedback telegram message to user %d: %w", recipient.ID, err))
+			}
+		}
+		return errors.Join(errs...)
 	})
 }
 
+func (s *Service) notifyStaffAboutOrder(ctx context.Context, order *model.Order) error {
+	var user *model.User
+	var err error
+	if order.UserID != nil {
+		user, err = s.store.GetUserByID(ctx, *order.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	var errs []error
+	if s.bot != nil {
+		recipients, err := s.getNotificationRecipients(ctx, enums.NotificationChannelTelegram, enums.NotificationEventNewOrder)
+		if err != nil {
+			return err
+		}
+
+		text := buildOrderTelegramText(order, user)
+		keyboard := models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Посмотреть заказ", URL: miniAppLink("order", order.UUID)}},
+			},
+		}
+		for _, recipient := range recipients {
+			if recipient.TID == nil || (order.UserID != nil && recipient.ID == *order.UserID) {
+				continue
+			}
+			_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:      *recipient.TID,
+				ParseMode:   models.ParseModeMarkdown,
+				Text:        text,
+				ReplyMarkup: keyboard,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send order telegram message to user %d: %w", recipient.ID, err))
+			}
+		}
+	}
+
+	if s.mailer.Enabled() {
+		recipients, err := s.getNotificationRecipients(ctx, enums.NotificationChannelEmail, enums.NotificationEventNewOrder)
+		if err != nil {
+			return err
+		}
+
+		orderList := []model.Order{*order}
+		err = s.attachOrderDetails(ctx, orderList)
+		if err != nil {
+			return fmt.Errorf("failed to load order details: %w", err)
+		}
+		for _, recipient := range recipients {
+			if recipient.Email == nil || (order.UserID != nil && recipient.ID == *order.UserID) {
+				continue
+			}
+			err = s.mailer.Send(ctx, *recipient.Email, "new_order", emailData{URL: s.cfg.App.URL, Name: recipient.FirstName, Order: &orderList[0]})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send new order email to user %d: %w", recipient.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func getKeyboard(status enums.RequestStatus, prefix string, id int, uuid uuid.UUID) models.ReplyMarkup {
-	var text, value string
+	var text, link string
 	switch prefix {
 	case orderCallbackPrefix:
 		text = "Посмотреть заказ"
-		value = base64.URLEncoding.EncodeToString([]byte("order:" + uuid.String()))
+		link = miniAppLink("order", uuid)
 	case feedbackCallbackPrefix:
 		text = "Посмотреть заявку"
-		value = base64.URLEncoding.EncodeToString([]byte("feedback:" + uuid.String()))
+		link = miniAppLink("feedback", uuid)
 	default:
 		return nil
 	}
@@ -183,7 +312,7 @@ func getKeyboard(status enums.RequestStatus, prefix string, id int, uuid uuid.UU
 			{Text: "Открыть", CallbackData: fmt.Sprintf("%s:%d:%s", prefix, id, enums.RequestStatusCreated)},
 			{Text: "Завершить", CallbackData: fmt.Sprintf("%s:%d:%s", prefix, id, enums.RequestStatusReviewed)},
 		}
-	case enums.RequestStatusReviewed:
+	case enums.RequestStatusAwaitingPayment, enums.RequestStatusReviewed:
 		keyboard = []models.InlineKeyboardButton{
 			{Text: "Открыть", CallbackData: fmt.Sprintf("%s:%d:%s", prefix, id, enums.RequestStatusCreated)},
 			{Text: "Взять в работу", CallbackData: fmt.Sprintf("%s:%d:%s", prefix, id, enums.RequestStatusInProgress)},
@@ -194,26 +323,62 @@ func getKeyboard(status enums.RequestStatus, prefix string, id int, uuid uuid.UU
 	return models.InlineKeyboardMarkup{
 		InlineKeyboard: [][]models.InlineKeyboardButton{
 			keyboard,
-			{{Text: text, URL: "https://t.me/ola_studio_bot?startapp=" + value}},
+			{{Text: text, URL: link}},
 		},
 	}
 }
 
+// feedbackKeyboard adds the button converting the feedback into an order until it is converted.
+func feedbackKeyboard(feedback *model.Feedback) models.ReplyMarkup {
+	keyboard, ok := getKeyboard(feedback.Status, feedbackCallbackPrefix, feedback.ID, feedback.UUID).(models.InlineKeyboardMarkup)
+	if !ok {
+		return nil
+	}
+	if feedback.OrderID != nil {
+		return keyboard
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+		{Text: "Создать заказ", CallbackData: fmt.Sprintf("%s:%d", feedbackOrderCallbackPrefix, feedback.ID)},
+	})
+	return keyboard
+}
+
+func miniAppLink(kind string, uuid uuid.UUID) string {
+	return botURL + "?startapp=" + base64.URLEncoding.EncodeToString([]byte(kind+":"+uuid.String()))
+}
+
 func buildOrderTelegramText(order *model.Order, user *model.User) string {
 	name := bot.EscapeMarkdown(order.Name)
 	if user != nil && user.Username != nil {
 		name = fmt.Sprintf("[%s](%s)", name, bot.EscapeMarkdown("https://t.me/"+*user.Username))
 	}
-	return fmt.Sprintf(
-		"%s Заказ \\#%s\n\n*– UUID\\:* %s\n*– Статус\\:* %s\n*– Имя\\:* %s\n*– Телефон\\:* %s\n*– Комментарий\\:* %s",
+	text := fmt.Sprintf(
+		"%s Заказ \\#%s\n\n*– UUID\\:* %s\n*– Статус\\:* %s\n*– Получение\\:* %s\n*– Имя\\:* %s\n*– Телефон\\:* %s\n*– Комментарий\\:* %s",
 		order.Status.Emoji(),
 		bot.EscapeMarkdown(strconv.Itoa(order.ID)),
 		bot.EscapeMarkdown(order.UUID.String()),
 		bot.EscapeMarkdown(order.Status.Label()),
+		bot.EscapeMarkdown(order.DeliveryType.Label()),
 		name,
 		bot.EscapeMarkdown(order.Phone),
 		bot.EscapeMarkdown(order.Content),
 	)
+	if order.PromoCode != nil {
+		text += fmt.Sprintf(
+			"\n*– Промокод\\:* %s \\(скидка %s\\)",
+			bot.EscapeMarkdown(*order.PromoCode),
+			bot.EscapeMarkdown(formatAmount(order.Discount)),
+		)
+	}
+	if order.Amount != nil {
+		text += fmt.Sprintf(
+			"\n*– Сумма\\:* %s \\(%s, внесено %s\\)",
+			bot.EscapeMarkdown(formatAmount(*order.Amount)),
+			bot.EscapeMarkdown(strings.ToLower(order.PaymentStatus.Label())),
+			bot.EscapeMarkdown(formatAmount(order.PaidAmount)),
+		)
+	}
+	return text
 }
 
 func buildFeedbackTelegramText(feedback *model.Feedback, user *model.User) string {
@@ -221,7 +386,7 @@ func buildFeedbackTelegramText(feedback *model.Feedback, user *model.User) strin
 	if user != nil && user.Username != nil {
 		name = fmt.Sprintf("[%s](%s)", name, bot.EscapeMarkdown("https://t.me/"+*user.Username))
 	}
-	return fmt.Sprintf(
+	text := fmt.Sprintf(
 		"%s Обратная связь \\#%s\n\n*– UUID\\:* %s\n*– Статус\\:* %s\n*– Тип\\:* %s\n*– Имя\\:* %s\n*– Телефон\\:* %s\n*– Комментарий\\:* %s",
 		feedback.Status.Emoji(),
 		bot.EscapeMarkdown(strconv.Itoa(feedback.ID)),
@@ -232,4 +397,8 @@ func buildFeedbackTelegramText(feedback *model.Feedback, user *model.User) strin
 		bot.EscapeMarkdown(feedback.Phone),
 		bot.EscapeMarkdown(feedback.Content),
 	)
+	if feedback.OrderID != nil {
+		text += fmt.Sprintf("\n*– Заказ\\:* \\#%s", bot.EscapeMarkdown(strconv.Itoa(*feedback.OrderID)))
+	}
+	return text
 }
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+type notificationEventSpec struct {
+	event    enums.NotificationEvent
+	channels []enums.NotificationChannel
+	roles    []enums.UserRole
+	enabled  bool
+}
+
+var (
+	allRoles   = []enums.UserRole{enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin}
+	staffRoles = []enums.UserRole{enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin}
+)
+
+// notificationEvents lists which events a role may subscribe to, over which channels,
+// and whether the subscription is on until the user says otherwise.
+var notificationEvents = []notificationEventSpec{
+	{
+		event:    enums.NotificationEventNewOrder,
+		channels: []enums.NotificationChannel{enums.NotificationChannelTelegram, enums.NotificationChannelEmail},
+		roles:    staffRoles,
+		enabled:  true,
+	},
+	{
+		event:    enums.NotificationEventNewFeedback,
+		channels: []enums.NotificationChannel{enums.NotificationChannelTelegram},
+		roles:    []enums.UserRole{enums.UserRoleModerator, enums.UserRoleAdmin},
+		enabled:  true,
+	},
+	{
+		event:    enums.NotificationEventSLABreach,
+		channels: []enums.NotificationChannel{enums.NotificationChannelTelegram},
+		roles:    []enums.UserRole{enums.UserRoleAdmin},
+		enabled:  true,
+	},
+	{
+		event:    enums.NotificationEventOrderStatus,
+		channels: []enums.NotificationChannel{enums.NotificationChannelTelegram, enums.NotificationChannelEmail},
+		roles:    allRoles,
+		enabled:  true,
+	},
+	{
+		event:    enums.NotificationEventOrderComment,
+		channels: []enums.NotificationChannel{enums.NotificationChannelTelegram},
+		roles:    allRoles,
+		enabled:  true,
+	},
+	{
+		event:    enums.NotificationEventRegistration,
+		channels: []enums.NotificationChannel{enums.NotificationChannelEmail},
+		roles:    allRoles,
+		enabled:  true,
+	},
+}
+
+func defaultNotificationPreferences(role enums.UserRole) []model.NotificationPreference {
+	preferences := make([]model.NotificationPreference, 0)
+	for _, spec := range notificationEvents {
+		if !slices.Contains(spec.roles, role) {
+			continue
+		}
+		for _, channel := range spec.channels {
+			preferences = append(preferences, model.NotificationPreference{
+				Channel: channel,
+				Event:   spec.event,
+				Enabled: spec.enabled,
+			})
+		}
+	}
+	return preferences
+}
+
+func (s *Service) getNotificationPreferences(ctx context.Context, user *model.User) ([]model.NotificationPreference, error) {
+	stored, err := s.store.GetNotificationPreferencesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	preferences := defaultNotificationPreferences(user.Role)
+	for i := range preferences {
+		for _, preference := range stored {
+			if preference.Channel == preferences[i].Channel && preference.Event == preferences[i].Event {
+				preferences[i].Enabled = preference.Enabled
+			}
+		}
+	}
+	return preferences, nil
+}
+
+func (s *Service) notificationEnabled(ctx context.Context, user *model.User, channel enums.NotificationChannel, event enums.NotificationEvent) (bool, error) {
+	preferences, err := s.getNotificationPreferences(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for _, preference := range preferences {
+		if preference.Channel == channel && preference.Event == event {
+			return preference.Enabled, nil
+		}
+	}
+	return false, nil
+}
+
+// getNotificationRecipients returns every user whose role allows the event and who has it enabled for the channel.
+func (s *Service) getNotificationRecipients(ctx context.Context, channel enums.NotificationChannel, event enums.NotificationEvent) ([]model.User, error) {
+	idx := slices.IndexFunc(notificationEvents, func(spec notificationEventSpec) bool {
+		return spec.event == event
+	})
+	if idx < 0 {
+		return nil, nil
+	}
+
+	spec := notificationEvents[idx]
+	if !slices.Contains(spec.channels, channel) {
+		return nil, nil
+	}
+
+	recipients, err := s.store.GetNotificationRecipients(ctx, spec.roles, channel, event, spec.enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification recipients: %w", err)
+	}
+	return recipients, nil
+}
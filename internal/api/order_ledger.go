@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+type createLedgerEntryRequest struct {
+	Kind    string `json:"kind" mold:"trim,lcase" validate:"required,oneof=payment refund"`
+	Method  string `json:"method" mold:"trim,lcase" validate:"required,oneof=cash card transfer"`
+	Amount  int    `json:"amount" validate:"required,min=1"`
+	Comment string `json:"comment" mold:"trim" validate:"omitempty,max=3000"`
+}
+
+// createOrderLedgerEntry records an offline payment or refund. Online payments
+// reach the ledger through their providers.
+func (s *Service) createOrderLedgerEntry(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	req, res := core.Validate[createLedgerEntryRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+	kind, err := enums.NewLedgerEntryKind(req.Kind)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+	method, err := enums.NewPaymentMethod(req.Method)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	order, err := s.store.GetOrderByUUIDForUpdate(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+	if kind == enums.LedgerEntryKindRefund && req.Amount > order.PaidAmount {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("refund exceeds paid amount %d", order.PaidAmount))
+	}
+
+	entryUUID, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	now := time.Now()
+	entry := &models.LedgerEntry{
+		UUID:       entryUUID,
+		OrderID:    order.ID,
+		Kind:       kind,
+		Method:     method,
+		Amount:     req.Amount,
+		RecordedBy: &user.ID,
+		CreatedAt:  now,
+	}
+	if req.Comment != "" {
+		entry.Comment = new(req.Comment)
+	}
+	err = s.store.CreateLedgerEntry(ctx, entry)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create ledger entry: %w", err))
+	}
+
+	change, err := s.refreshOrderPaymentTotals(ctx, order, now)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
+	orderList := []models.Order{*order}
+	err = s.attachOrderDetails(ctx, orderList)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to load order details: %w", err))
+	}
+	*order = orderList[0]
+
+	s.store.Commit(ctx)
+
+	s.publishPaymentChange(r.Context(), change, nil)
+
+	return core.JSON(http.StatusCreated, order)
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const ratingCallbackPrefix = "order_rating"
+
+const (
+	ratingBatchSize = 20
+	ratingLease     = 5 * time.Minute
+)
+
+type ratingLink struct {
+	Score int
+	URL   string
+}
+
+func (s *Service) registerRatingListeners() {
+	s.eventBus.OrderStatusChanged.Subscribe(func(ctx context.Context, change *model.OrderStatusChange) error {
+		if !s.cfg.Ratings.Enabled || change == nil || change.Order == nil || change.Order.Status != enums.RequestStatusReviewed {
+			return nil
+		}
+
+		now := time.Now()
+		err := s.store.CreateOrderReviewRequest(ctx, &model.OrderReviewRequest{
+			OrderID:   change.Order.ID,
+			DueAt:     now.Add(s.cfg.Ratings.Delay),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create order review request: %w", err)
+		}
+		return nil
+	})
+}
+
+// processOrderReviewRequests sends due review requests through Telegram, falling back to email.
+func (s *Service) processOrderReviewRequests(ctx context.Context) error {
+	now := time.Now()
+	requests, err := s.store.ClaimDueOrderReviewRequests(ctx, now, now.Add(ratingLease), ratingBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim order review requests: %w", err)
+	}
+
+	var errs []error
+	for _, request := range requests {
+		channel, err := s.sendOrderReviewRequest(ctx, request.OrderID)
+
+		now = time.Now()
+		request.UpdatedAt = now
+		if err != nil {
+			request.Attempts++
+			request.LastError = new(err.Error())
+			if request.Attempts >= max(s.cfg.Ratings.MaxAttempts, 1) {
+				request.SentAt = &now
+			}
+			errs = append(errs, fmt.Errorf("failed to send review request for order %d: %w", request.OrderID, err))
+		} else {
+			request.Channel = channel
+			request.LastError = nil
+			request.SentAt = &now
+		}
+		err = s.store.UpdateOrderReviewRequest(ctx, &request)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update order review request: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendOrderReviewRequest returns the channel the customer was asked through, or nil when
+// the order was already rated or the customer can not be reached.
+func (s *Service) sendOrderReviewRequest(ctx context.Context, orderID int) (*enums.NotificationChannel, error) {
+	order, err := s.store.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Rating != nil || order.UserID == nil {
+		return nil, nil
+	}
+
+	user, err := s.store.GetUserByID(ctx, *order.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if s.bot != nil && user.TID != nil && user.BotBlockedAt == nil {
+		enabled, err := s.notificationEnabled(ctx, user, enums.NotificationChannelTelegram, enums.NotificationEventOrderStatus)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:      *user.TID,
+				ParseMode:   models.ParseModeMarkdown,
+				Text:        fmt.Sprintf("⭐ *Как вам заказ \\#%s?*\n\nОцените его, это займёт пару секунд\\. А если есть время — напишите отзыв, он поможет другим клиентам с выбором\\.", bot.EscapeMarkdown(strconv.Itoa(order.ID))),
+				ReplyMarkup: ratingKeyboard(order),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to send review request telegram message: %w", err)
+			}
+			return &enums.NotificationChannelTelegram, nil
+		}
+	}
+
+	if s.mailer.Enabled() {
+		recipient, err := s.getOrderEmailRecipient(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		if recipient != nil {
+			err = s.mailer.Send(ctx, *recipient.Email, "review_request", emailData{
+				URL:     s.cfg.App.URL,
+				Name:    order.Name,
+				Order:   order,
+				Ratings: s.ratingLinks(order),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to send review request email: %w", err)
+			}
+			return &enums.NotificationChannelEmail, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func ratingKeyboard(order *model.Order) models.InlineKeyboardMarkup {
+	scores := make([]models.InlineKeyboardButton, 0, 5)
+	for score := 1; score <= 5; score++ {
+		scores = append(scores, models.InlineKeyboardButton{
+			Text:         strconv.Itoa(score) + "⭐",
+			CallbackData: fmt.Sprintf("%s:%d:%d", ratingCallbackPrefix, order.ID, score),
+		})
+	}
+	return models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+		scores,
+		{{Text: "Написать отзыв", URL: miniAppLink("order", order.UUID)}},
+	}}
+}
+
+func (s *Service) handleOrderRatingCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 || parts[0] != ratingCallbackPrefix {
+		return "Не удалось распарсить данные", nil
+	}
+	orderID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "Не удалось распарсить данные", nil
+	}
+	score, err := strconv.Atoi(parts[2])
+	if err != nil || score < 1 || score > 5 {
+		return "Не удалось распарсить данные", nil
+	}
+
+	order, err := s.store.GetOrderByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "Заказ не найден", nil
+		}
+		return "Не удалось получить заказ", fmt.Errorf("failed to load order after rating callback: %w", err)
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return "Заказ не найден", nil
+	}
+
+	err = s.rateOrder(ctx, order, score)
+	if err != nil {
+		return "Не удалось сохранить оценку", err
+	}
+
+	if message := callback.Message.Message; message != nil {
+		_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    message.Chat.ID,
+			MessageID: message.ID,
+			ParseMode: models.ParseModeMarkdown,
+			Text:      fmt.Sprintf("⭐ *Заказ \\#%s*\n\nВаша оценка\\: %s\\. Спасибо\\!", bot.EscapeMarkdown(strconv.Itoa(order.ID)), strings.Repeat("⭐", score)),
+			ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Написать отзыв", URL: miniAppLink("order", order.UUID)}},
+			}},
+		})
+		if err != nil {
+			s.log.Error("Failed to edit rating message", err)
+		}
+	}
+
+	return "Спасибо за оценку!", nil
+}
+
+type ratingPageData struct {
+	Title   string
+	OrderID int
+	Stars   string
+	Action  string
+}
+
+// confirmOrderRating renders the page behind a review request email link. The score is
+// only stored after the customer submits the form, so link prefetchers can not rate orders.
+func (s *Service) confirmOrderRating(w http.ResponseWriter, r *http.Request) {
+	order, score, ok := s.orderFromRatingLink(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := s.getTemplates()
+	if err != nil {
+		s.log.Error("Failed to get templates", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	err = templates.ExecuteTemplate(w, "rating.html", ratingPageData{
+		Title:   "Оценка заказа | OLA Studio",
+		OrderID: order.ID,
+		Stars:   strings.Repeat("⭐", score),
+		Action:  r.URL.RequestURI(),
+	})
+	if err != nil {
+		s.log.Error("Failed to execute template", err, slog.String("path", r.URL.Path))
+	}
+}
+
+// rateOrderFromEmail records the score submitted from the confirmation page.
+func (s *Service) rateOrderFromEmail(w http.ResponseWriter, r *http.Request) {
+	order, score, ok := s.orderFromRatingLink(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.rateOrder(r.Context(), order, score)
+	if err != nil {
+		s.log.Error("Failed to rate order", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, s.cfg.App.URL+"/spa", http.StatusSeeOther)
+}
+
+// orderFromRatingLink checks the signature of a rating link and loads its order. On failure
+// the response is already written.
+func (s *Service) orderFromRatingLink(w http.ResponseWriter, r *http.Request) (*model.Order, int, bool) {
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, 0, false
+	}
+	score, err := strconv.Atoi(r.PathValue("score"))
+	if err != nil || score < 1 || score > 5 {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, 0, false
+	}
+	if !hmac.Equal([]byte(r.URL.Query().Get("sig")), []byte(s.ratingSignature(uid, score))) {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, 0, false
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, 0, false
+		}
+		s.log.Error("Failed to get order", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, 0, false
+	}
+	return order, score, true
+}
+
+// rateOrder stores the customer's score and alerts managers when it is low.
+func (s *Service) rateOrder(ctx context.Context, order *model.Order, score int) error {
+	wasLow := order.Rating != nil && *order.Rating <= s.cfg.Ratings.LowRating
+
+	now := time.Now()
+	order.Rating = &score
+	order.RatedAt = &now
+	order.UpdatedAt = now
+	err := s.store.UpdateOrderRating(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to update order rating: %w", err)
+	}
+
+	if score <= s.cfg.Ratings.LowRating && !wasLow {
+		err = s.alertLowRating(ctx, order)
+		if err != nil {
+			s.log.Error("Failed to alert managers about low rating", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) alertLowRating(ctx context.Context, order *model.Order) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	destinations, err := s.orderDestinations(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(
+		"⚠️ *Низкая оценка заказа \\#%s*\n\n*– Оценка\\:* %s\n*– Имя\\:* %s\n*– Телефон\\:* %s",
+		bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+		strings.Repeat("⭐", *order.Rating),
+		bot.EscapeMarkdown(order.Name),
+		bot.EscapeMarkdown(order.Phone),
+	)
+	var errs []error
+	for _, destination := range destinations {
+		_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          destination.chatID,
+			MessageThreadID: destination.topicID,
+			ParseMode:       models.ParseModeMarkdown,
+			Text:            text,
+			ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Посмотреть заказ", URL: miniAppLink("order", order.UUID)}},
+			}},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send low rating alert to chat %d: %w", destination.chatID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) ratingLinks(order *model.Order) []ratingLink {
+	links := make([]ratingLink, 0, 5)
+	for score := 1; score <= 5; score++ {
+		links = append(links, ratingLink{
+			Score: score,
+			URL:   fmt.Sprintf("%s/api/guest/orders/%s/rating/%d?sig=%s", s.cfg.App.URL, order.UUID, score, s.ratingSignature(order.UUID, score)),
+		})
+	}
+	return links
+}
+
+func (s *Service) ratingSignature(orderUUID uuid.UUID, score int) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.App.Secret))
+	mac.Write([]byte(fmt.Sprintf("%s:%s:%d", ratingCallbackPrefix, orderUUID, score)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
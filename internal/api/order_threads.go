@@ -0,0 +1,350 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const orderThreadCallbackPrefix = "order_thread"
+
+func messageContent(message *models.Message) string {
+	if text := strings.TrimSpace(message.Text); text != "" {
+		return text
+	}
+	if caption := strings.TrimSpace(message.Caption); caption != "" {
+		return caption
+	}
+	return "[вложение]"
+}
+
+func (s *Service) handleThreadCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(args, "#"))
+	if err != nil {
+		s.replyToCommand(ctx, b, message, "Укажите номер заказа, например `/thread 42`", nil)
+		return nil
+	}
+
+	order, err := s.store.GetOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			s.replyToCommand(ctx, b, message, "Заказ не найден", nil)
+			return nil
+		}
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	var customer *model.User
+	if order.UserID != nil {
+		customer, err = s.store.GetUserByID(ctx, *order.UserID)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+	if customer == nil || customer.TID == nil {
+		s.replyToCommand(ctx, b, message, "Клиент не пользуется ботом, свяжитесь с ним по телефону", nil)
+		return nil
+	}
+
+	_, err = s.store.GetOpenOrderThreadByOrderID(ctx, order.ID)
+	if err == nil {
+		s.replyToCommand(ctx, b, message, "Диалог по этому заказу уже открыт", nil)
+		return nil
+	}
+	if !errors.Is(err, model.ErrNotFound) {
+		return fmt.Errorf("failed to get order thread: %w", err)
+	}
+
+	topic, err := b.CreateForumTopic(ctx, &bot.CreateForumTopicParams{
+		ChatID: s.cfg.Telegram.GroupID,
+		Name:   fmt.Sprintf("Заказ #%d — %s", order.ID, order.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create forum topic: %w", err)
+	}
+
+	thread := &model.OrderThread{
+		OrderID:         order.ID,
+		ChatID:          int64(s.cfg.Telegram.GroupID),
+		MessageThreadID: int64(topic.MessageThreadID),
+		CustomerID:      customer.ID,
+		CreatedBy:       user.ID,
+		CreatedAt:       time.Now(),
+	}
+	err = s.store.CreateOrderThread(ctx, thread)
+	if err != nil {
+		// The topic is not linked to any thread, so it would stay in the group forever.
+		_, deleteErr := b.DeleteForumTopic(ctx, &bot.DeleteForumTopicParams{
+			ChatID:          s.cfg.Telegram.GroupID,
+			MessageThreadID: topic.MessageThreadID,
+		})
+		if deleteErr != nil {
+			s.log.Error("Failed to delete forum topic", deleteErr)
+		}
+		if errors.Is(err, model.ErrUniqueViolation) {
+			s.replyToCommand(ctx, b, message, "Диалог по этому заказу уже открыт", nil)
+			return nil
+		}
+		return fmt.Errorf("failed to create order thread: %w", err)
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          s.cfg.Telegram.GroupID,
+		MessageThreadID: topic.MessageThreadID,
+		ParseMode:       models.ParseModeMarkdown,
+		Text:            buildOrderTelegramText(order, customer) + "\n\n_Сообщения в этой теме пересылаются клиенту, его ответы появятся здесь\\. Чтобы завершить диалог, отправьте /close\\._",
+	})
+	if err != nil {
+		s.abandonOrderThread(ctx, b, thread)
+		return fmt.Errorf("failed to send order thread intro: %w", err)
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    *customer.TID,
+		ParseMode: models.ParseModeMarkdown,
+		Text: fmt.Sprintf(
+			"💬 *Менеджер хочет уточнить детали заказа \\#%s*\n\nПросто напишите ответ в этот чат, мы его получим\\. Когда вопрос решится, завершите диалог кнопкой ниже\\.",
+			bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+		),
+		ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{{
+			Text:         "Завершить диалог",
+			CallbackData: fmt.Sprintf("%s:close:%d", orderThreadCallbackPrefix, order.ID),
+		}}}},
+	})
+	if err != nil {
+		s.abandonOrderThread(ctx, b, thread)
+		s.replyToCommand(ctx, b, message, "Не удалось написать клиенту, возможно, он заблокировал бота", nil)
+		return fmt.Errorf("failed to send order thread intro to customer: %w", err)
+	}
+
+	s.replyToCommand(ctx, b, message, "Диалог с клиентом открыт в отдельной теме", nil)
+	return nil
+}
+
+// abandonOrderThread closes a thread the customer never heard about, so messages in its topic
+// are not swallowed by the relay.
+func (s *Service) abandonOrderThread(ctx context.Context, b *bot.Bot, thread *model.OrderThread) {
+	err := s.store.CloseOrderThread(ctx, thread.ID, time.Now())
+	if err != nil {
+		s.log.Error("Failed to close order thread", err)
+	}
+	_, err = b.CloseForumTopic(ctx, &bot.CloseForumTopicParams{
+		ChatID:          thread.ChatID,
+		MessageThreadID: int(thread.MessageThreadID),
+	})
+	if err != nil {
+		s.log.Error("Failed to close forum topic", err)
+	}
+}
+
+func (s *Service) handleCloseCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	thread, err := s.store.GetOpenOrderThreadByTopic(ctx, message.Chat.ID, int64(message.MessageThreadID))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			s.replyToCommand(ctx, b, message, "Команду нужно отправить в теме открытого диалога", nil)
+			return nil
+		}
+		return fmt.Errorf("failed to get order thread: %w", err)
+	}
+
+	err = s.store.CloseOrderThread(ctx, thread.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to close order thread: %w", err)
+	}
+
+	s.replyToCommand(ctx, b, message, "Диалог завершён", nil)
+
+	_, err = b.CloseForumTopic(ctx, &bot.CloseForumTopicParams{
+		ChatID:          thread.ChatID,
+		MessageThreadID: int(thread.MessageThreadID),
+	})
+	if err != nil {
+		s.log.Error("Failed to close forum topic", err)
+	}
+
+	customer, err := s.store.GetUserByID(ctx, thread.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer.TID == nil {
+		return nil
+	}
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    *customer.TID,
+		ParseMode: models.ParseModeMarkdown,
+		Text:      fmt.Sprintf("✅ Диалог по заказу \\#%s завершён\\. Спасибо\\!", bot.EscapeMarkdown(strconv.Itoa(thread.OrderID))),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify customer about closed thread: %w", err)
+	}
+	return nil
+}
+
+// handleOrderThreadCallback lets the customer leave the thread, so their next messages reach the bot again.
+func (s *Service) handleOrderThreadCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 3 || parts[0] != orderThreadCallbackPrefix || parts[1] != "close" {
+		return "Не удалось распарсить данные", nil
+	}
+	orderID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "Не удалось распарсить данные", nil
+	}
+
+	thread, err := s.store.GetOpenOrderThreadByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "Диалог уже завершён", nil
+		}
+		return "Не удалось получить диалог", fmt.Errorf("failed to get order thread: %w", err)
+	}
+	if thread.CustomerID != user.ID {
+		return "Диалог уже завершён", nil
+	}
+
+	err = s.store.CloseOrderThread(ctx, thread.ID, time.Now())
+	if err != nil {
+		return "Не удалось завершить диалог", fmt.Errorf("failed to close order thread: %w", err)
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          thread.ChatID,
+		MessageThreadID: int(thread.MessageThreadID),
+		Text:            "Клиент завершил диалог",
+	})
+	if err != nil {
+		s.log.Error("Failed to notify managers about closed thread", err)
+	}
+	_, err = b.CloseForumTopic(ctx, &bot.CloseForumTopicParams{
+		ChatID:          thread.ChatID,
+		MessageThreadID: int(thread.MessageThreadID),
+	})
+	if err != nil {
+		s.log.Error("Failed to close forum topic", err)
+	}
+
+	if message := callback.Message.Message; message != nil {
+		_, err = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+			ChatID:    message.Chat.ID,
+			MessageID: message.ID,
+		})
+		if err != nil {
+			s.log.Error("Failed to remove order thread keyboard", err)
+		}
+	}
+
+	return "Диалог завершён", nil
+}
+
+// relayToCustomer forwards a manager's message from an order topic to the customer.
+// It reports whether the message belonged to an open thread.
+func (s *Service) relayToCustomer(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User) (bool, error) {
+	if !message.IsTopicMessage || message.MessageThreadID == 0 {
+		return false, nil
+	}
+
+	thread, err := s.store.GetOpenOrderThreadByTopic(ctx, message.Chat.ID, int64(message.MessageThreadID))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get order thread: %w", err)
+	}
+	if !slices.Contains([]enums.UserRole{enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin}, user.Role) {
+		return true, nil
+	}
+
+	customer, err := s.store.GetUserByID(ctx, thread.CustomerID)
+	if err != nil {
+		return true, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer.TID == nil {
+		return true, nil
+	}
+
+	_, err = b.CopyMessage(ctx, &bot.CopyMessageParams{
+		ChatID:     *customer.TID,
+		FromChatID: message.Chat.ID,
+		MessageID:  message.ID,
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to copy message to customer: %w", err)
+	}
+
+	err = s.createRelayComment(ctx, thread, user, message, enums.CommentDirectionToCustomer)
+	if err != nil {
+		return true, err
+	}
+
+	s.reactToMessage(ctx, b, message)
+	return true, nil
+}
+
+// relayFromCustomer forwards a customer's private message into the topic of their latest open thread.
+// Commands are skipped, and defaultHandler gives an active bot ordering session the message first.
+func (s *Service) relayFromCustomer(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User) (bool, error) {
+	if strings.HasPrefix(message.Text, "/") {
+		return false, nil
+	}
+
+	thread, err := s.store.GetLatestOpenOrderThreadByCustomerID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get order thread: %w", err)
+	}
+
+	_, err = b.CopyMessage(ctx, &bot.CopyMessageParams{
+		ChatID:          thread.ChatID,
+		MessageThreadID: int(thread.MessageThreadID),
+		FromChatID:      message.Chat.ID,
+		MessageID:       message.ID,
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to copy message to order thread: %w", err)
+	}
+
+	err = s.createRelayComment(ctx, thread, user, message, enums.CommentDirectionFromCustomer)
+	if err != nil {
+		return true, err
+	}
+
+	s.reactToMessage(ctx, b, message)
+	return true, nil
+}
+
+func (s *Service) createRelayComment(ctx context.Context, thread *model.OrderThread, user *model.User, message *models.Message, direction enums.CommentDirection) error {
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	now := time.Now()
+	comment := &model.OrderComment{
+		UUID:      uid,
+		Content:   messageContent(message),
+		IsPublic:  true,
+		Direction: direction,
+		OrderID:   thread.OrderID,
+		UserID:    user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.store.CreateOrderComment(ctx, comment)
+	if err != nil {
+		return fmt.Errorf("failed to create order comment: %w", err)
+	}
+
+	s.eventBus.OrderCommentCreated.Publish(context.WithoutCancel(ctx), comment)
+	return nil
+}
@@ -15,9 +15,18 @@ import (
 )
 
 type createOrderRequest struct {
-	Name    string `json:"name" mold:"trim" validate:"required,max=255"`
-	Phone   string `json:"phone" mold:"trim" validate:"required,max=255,ru_phone"`
-	Content string `json:"content" mold:"trim" validate:"required,max=3000"`
+	Name         string `json:"name" mold:"trim" validate:"required,max=255"`
+	Phone        string `json:"phone" mold:"trim" validate:"required,max=255,ru_phone"`
+	Content      string `json:"content" mold:"trim" validate:"required,max=3000"`
+	DeliveryType string `json:"delivery_type" mold:"trim,lcase" validate:"omitempty,oneof=pickup delivery"`
+}
+
+func deliveryTypeFromRequest(s string) enums.DeliveryType {
+	deliveryType, err := enums.NewDeliveryType(s)
+	if err != nil {
+		return enums.DeliveryTypePickup
+	}
+	return deliveryType
 }
 
 func sourceFromAuthHeader(authorization string) enums.OrderSource {
@@ -34,7 +43,7 @@ func (s *Service) getOrders(r *http.Request, user *models.User) core.Response {
 		return res
 	}
 
-	orders, err := s.store.GetAllOrders(r.Context())
+	orders, err := s.store.GetAllOrders(r.Context(), normalizeTag(r.URL.Query().Get("tag")))
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get orders: %w", err))
 	}
@@ -83,35 +92,52 @@ func (s *Service) createOrder(r *http.Request, user *models.User) core.Response
 		return res
 	}
 
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
 	user.Phone = new(req.Phone)
 	user.UpdatedAt = time.Now()
-	err := s.store.UpdateUserPhone(r.Context(), user)
+	err = s.store.UpdateUserPhone(ctx, user)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update user phone: %w", err))
 	}
 
+	customerID, err := s.linkCustomer(ctx, req.Phone, req.Name, &user.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
 	uid, err := uuid.NewV7()
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
 	}
 
 	order := &models.Order{
-		UUID:      uid,
-		Status:    enums.RequestStatusCreated,
-		Source:    sourceFromAuthHeader(r.Header.Get("Authorization")),
-		Name:      req.Name,
-		Phone:     req.Phone,
-		Content:   req.Content,
-		UserID:    &user.ID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-
-	err = s.store.CreateOrder(r.Context(), order)
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		PaymentStatus:   enums.OrderPaymentStatusUnpaid,
+		Source:          sourceFromAuthHeader(r.Header.Get("Authorization")),
+		DeliveryType:    deliveryTypeFromRequest(req.DeliveryType),
+		Name:            req.Name,
+		Phone:           req.Phone,
+		Content:         req.Content,
+		UserID:          &user.ID,
+		CustomerID:      customerID,
+		StatusChangedAt: time.Now(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	err = s.store.CreateOrder(ctx, order)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create order: %w", err))
 	}
 
+	s.store.Commit(ctx)
+
 	s.eventBus.OrderCreated.Publish(context.WithoutCancel(r.Context()), order)
 
 	return core.JSON(http.StatusCreated, order)
@@ -123,23 +149,11 @@ func (s *Service) createOrderFromCart(r *http.Request, user *models.User) core.R
 		return res
 	}
 
-	ctx, err := s.store.Begin(r.Context())
-	if err != nil {
-		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
-	}
-	defer s.store.Rollback(ctx)
-
-	user.Phone = new(req.Phone)
-	user.UpdatedAt = time.Now()
-	err = s.store.UpdateUserPhone(ctx, user)
-	if err != nil {
-		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update user phone: %w", err))
-	}
-
-	order, err := s.store.CreateOrderFromUserCart(
-		ctx,
-		user.ID,
+	order, err := s.placeOrderFromCart(
+		r.Context(),
+		user,
 		sourceFromAuthHeader(r.Header.Get("Authorization")),
+		deliveryTypeFromRequest(req.DeliveryType),
 		req.Name,
 		req.Phone,
 		req.Content,
@@ -148,21 +162,82 @@ func (s *Service) createOrderFromCart(r *http.Request, user *models.User) core.R
 		if errors.Is(err, models.ErrCartEmpty) {
 			return core.Err(http.StatusBadRequest, fmt.Errorf("cart is empty"))
 		}
-		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create order from cart: %w", err))
+		return cartPromoErrorResponse(err)
 	}
 
-	s.store.Commit(ctx)
+	return core.JSON(http.StatusCreated, order)
+}
 
-	s.eventBus.OrderCreated.Publish(context.WithoutCancel(r.Context()), order)
+// placeOrderFromCart turns the user's cart into an order and publishes OrderCreated.
+// The promo code applied to the cart is checked again and its discount is copied to the order.
+// It is shared by the SPA/Mini App checkout and the bot conversation.
+func (s *Service) placeOrderFromCart(ctx context.Context, user *models.User, source enums.OrderSource, deliveryType enums.DeliveryType, name, phone, content string) (*models.Order, error) {
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
 
-	return core.JSON(http.StatusCreated, order)
+	user.Phone = new(phone)
+	user.UpdatedAt = time.Now()
+	err = s.store.UpdateUserPhone(txCtx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user phone: %w", err)
+	}
+
+	promo, err := s.store.GetUserCartPromoCode(txCtx, user.ID, true)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get cart promo code: %w", err)
+	}
+	var cartPromo *models.CartPromo
+	if promo != nil {
+		cartPromo, err = s.evaluateCartPromoCode(txCtx, promo, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := s.store.CreateOrderFromUserCart(txCtx, user.ID, source, deliveryType, name, phone, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order from cart: %w", err)
+	}
+
+	order.CustomerID, err = s.linkCustomer(txCtx, phone, name, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.UpdateOrderCustomer(txCtx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save order customer: %w", err)
+	}
+
+	if cartPromo != nil {
+		order.PromoCodeID = &promo.ID
+		order.PromoCode = &promo.Code
+		order.Discount = cartPromo.Discount
+		err = s.store.UpdateOrderPromoCode(txCtx, order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save order promo code: %w", err)
+		}
+		err = s.store.SetUserCartPromoCode(txCtx, user.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear cart promo code: %w", err)
+		}
+	}
+
+	s.store.Commit(txCtx)
+
+	s.eventBus.OrderCreated.Publish(context.WithoutCancel(ctx), order)
+
+	return order, nil
 }
 
 type createGuestOrderRequest struct {
-	Name    string `json:"name" mold:"trim" validate:"required,max=255"`
-	Phone   string `json:"phone" mold:"trim" validate:"required,max=255,ru_phone"`
-	Content string `json:"content" mold:"trim" validate:"required,max=3000"`
-	Consent bool   `json:"consent" validate:"required"`
+	Name         string `json:"name" mold:"trim" validate:"required,max=255"`
+	Phone        string `json:"phone" mold:"trim" validate:"required,max=255,ru_phone"`
+	Content      string `json:"content" mold:"trim" validate:"required,max=3000"`
+	DeliveryType string `json:"delivery_type" mold:"trim,lcase" validate:"omitempty,oneof=pickup delivery"`
+	Consent      bool   `json:"consent" validate:"required"`
 }
 
 func (s *Service) createGuestOrder(r *http.Request) core.Response {
@@ -171,34 +246,53 @@ func (s *Service) createGuestOrder(r *http.Request) core.Response {
 		return res
 	}
 
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	customerID, err := s.linkCustomer(ctx, req.Phone, req.Name, nil)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
 	uid, err := uuid.NewV7()
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
 	}
 	order := &models.Order{
-		UUID:      uid,
-		Status:    enums.RequestStatusCreated,
-		Source:    enums.OrderSourceLanding,
-		Name:      req.Name,
-		Phone:     req.Phone,
-		Content:   req.Content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-
-	err = s.store.CreateOrder(r.Context(), order)
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		PaymentStatus:   enums.OrderPaymentStatusUnpaid,
+		Source:          enums.OrderSourceLanding,
+		DeliveryType:    deliveryTypeFromRequest(req.DeliveryType),
+		Name:            req.Name,
+		Phone:           req.Phone,
+		Content:         req.Content,
+		CustomerID:      customerID,
+		Attribution:     attributionFromRequest(r),
+		StatusChangedAt: time.Now(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	err = s.store.CreateOrder(ctx, order)
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create guest order: %w", err))
 	}
 
+	s.store.Commit(ctx)
+
 	s.eventBus.OrderCreated.Publish(context.WithoutCancel(r.Context()), order)
 
 	return core.JSON(http.StatusCreated, order)
 }
 
 type updateOrderStatusRequest struct {
-	Status  *enums.RequestStatus `json:"status"`
-	Comment string               `json:"comment" mold:"trim" validate:"omitempty,max=3000"`
+	Status   *enums.RequestStatus `json:"status"`
+	Comment  string               `json:"comment" mold:"trim" validate:"omitempty,max=3000"`
+	IsPublic bool                 `json:"is_public"`
 }
 
 func (s *Service) updateOrderStatus(r *http.Request, user *models.User) core.Response {
@@ -227,7 +321,7 @@ func (s *Service) updateOrderStatus(r *http.Request, user *models.User) core.Res
 	}
 	defer s.store.Rollback(ctx)
 
-	order, err := s.store.GetOrderByUUID(ctx, uid)
+	order, err := s.store.GetOrderByUUIDForUpdate(ctx, uid)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
@@ -236,8 +330,9 @@ func (s *Service) updateOrderStatus(r *http.Request, user *models.User) core.Res
 	}
 
 	now := time.Now()
+	oldStatus := order.Status
 	if req.Status != nil {
-		order.Status = *req.Status
+		setOrderStatus(order, *req.Status, user, now)
 	}
 
 	order.UpdatedAt = now
@@ -246,15 +341,18 @@ func (s *Service) updateOrderStatus(r *http.Request, user *models.User) core.Res
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update order status: %w", err))
 	}
 
+	var comment *models.OrderComment
 	if req.Comment != "" {
 		commentUUID, commentErr := uuid.NewV7()
 		if commentErr != nil {
 			return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate order comment uuid: %w", commentErr))
 		}
 
-		comment := &models.OrderComment{
+		comment = &models.OrderComment{
 			UUID:      commentUUID,
 			Content:   req.Comment,
+			IsPublic:  req.IsPublic,
+			Direction: enums.CommentDirectionInternal,
 			OrderID:   order.ID,
 			UserID:    user.ID,
 			CreatedAt: now,
@@ -277,10 +375,32 @@ func (s *Service) updateOrderStatus(r *http.Request, user *models.User) core.Res
 	s.store.Commit(ctx)
 
 	s.eventBus.OrderChanged.Publish(context.WithoutCancel(r.Context()), order)
+	if order.Status != oldStatus {
+		s.eventBus.OrderStatusChanged.Publish(context.WithoutCancel(r.Context()), &models.OrderStatusChange{Order: order, OldStatus: oldStatus})
+	}
+	if comment != nil {
+		s.eventBus.OrderCommentCreated.Publish(context.WithoutCancel(r.Context()), comment)
+	}
 
 	return core.JSON(http.StatusOK, order)
 }
 
+// setOrderStatus applies the requested status; completion of an order with an
+// outstanding balance turns into awaiting_payment.
+func setOrderStatus(order *models.Order, status enums.RequestStatus, user *models.User, now time.Time) {
+	if status == enums.RequestStatusReviewed && orderBalanceDue(order) > 0 {
+		status = enums.RequestStatusAwaitingPayment
+	}
+	if order.Status != status {
+		order.Status = status
+		order.StatusChangedAt = now
+	}
+	if status == enums.RequestStatusInProgress {
+		order.AssigneeID = &user.ID
+	}
+	order.UpdatedAt = now
+}
+
 func (s *Service) attachOrderDetails(ctx context.Context, orders []models.Order) error {
 	if len(orders) == 0 {
 		return nil
@@ -290,6 +410,7 @@ func (s *Service) attachOrderDetails(ctx context.Context, orders []models.Order)
 	for i := range orders {
 		orders[i].Items = make([]models.OrderItem, 0)
 		orders[i].Comments = make([]models.OrderComment, 0)
+		orders[i].Ledger = make([]models.LedgerEntry, 0)
 		orderIDs = append(orderIDs, orders[i].ID)
 	}
 
@@ -303,6 +424,13 @@ func (s *Service) attachOrderDetails(ctx context.Context, orders []models.Order)
 		return err
 	}
 
+	ledgerByOrderID, err := s.store.GetLedgerEntriesByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return err
+	}
+
+	s.applyOrderSLA(orders, time.Now())
+
 	for i := range orders {
 		if items, ok := itemsByOrderID[orders[i].ID]; ok {
 			orders[i].Items = items
@@ -310,6 +438,12 @@ func (s *Service) attachOrderDetails(ctx context.Context, orders []models.Order)
 		if comments, ok := commentsByOrderID[orders[i].ID]; ok {
 			orders[i].Comments = comments
 		}
+		if entries, ok := ledgerByOrderID[orders[i].ID]; ok {
+			orders[i].Ledger = entries
+		}
+		if orders[i].Amount != nil {
+			orders[i].BalanceDue = new(orderBalanceDue(&orders[i]))
+		}
 	}
 
 	return nil
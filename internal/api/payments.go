@@ -0,0 +1,542 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/payments"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+var (
+	errOnlinePaymentsDisabled = errors.New("online payments disabled")
+	errPaymentExceedsBalance  = errors.New("payment amount exceeds order balance")
+	errRefundNotSupported     = errors.New("refund is not supported for this payment provider")
+)
+
+func (s *Service) onlinePaymentCurrency() string {
+	if s.cfg.Payments.Online.Currency == "" {
+		return "RUB"
+	}
+	return s.cfg.Payments.Online.Currency
+}
+
+type createOrderPaymentRequest struct {
+	Amount int `json:"amount" validate:"omitempty,min=1"`
+}
+
+func (s *Service) createOrderPayment(r *http.Request, user *models.User) core.Response {
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	req, res := core.Validate[createOrderPaymentRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		res = allowForOrderManager(user)
+		if res != nil {
+			return res
+		}
+	}
+
+	return s.createOnlinePaymentResponse(r.Context(), order, req.Amount, &user.ID)
+}
+
+func (s *Service) createGuestOrderPayment(r *http.Request) core.Response {
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	req, res := core.Validate[createOrderPaymentRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+
+	return s.createOnlinePaymentResponse(r.Context(), order, req.Amount, nil)
+}
+
+func (s *Service) createOnlinePaymentResponse(ctx context.Context, order *models.Order, amount int, userID *int) core.Response {
+	payment, err := s.createOnlinePayment(ctx, order, amount, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errOnlinePaymentsDisabled):
+			return core.Err(http.StatusServiceUnavailable, err)
+		case errors.Is(err, errOrderAlreadyPaid):
+			return core.Err(http.StatusConflict, err)
+		case errors.Is(err, errOrderAmountMissing), errors.Is(err, errPaymentExceedsBalance):
+			return core.Err(http.StatusBadRequest, err)
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create payment: %w", err))
+	}
+	return core.JSON(http.StatusCreated, payment)
+}
+
+// createOnlinePayment registers a payment with the online provider; zero amount pays the whole balance.
+// Pending payments of the order count against the balance, and a repeated request for the same
+// amount returns the pending payment instead of a second payable link.
+func (s *Service) createOnlinePayment(ctx context.Context, order *models.Order, amount int, userID *int) (*models.Payment, error) {
+	if s.payments == nil {
+		return nil, errOnlinePaymentsDisabled
+	}
+
+	ctx, err := s.store.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(ctx)
+
+	order, err = s.store.GetOrderByIDForUpdate(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Amount == nil {
+		return nil, errOrderAmountMissing
+	}
+	balance := orderBalanceDue(order)
+	if balance <= 0 {
+		return nil, errOrderAlreadyPaid
+	}
+
+	list, err := s.store.GetPaymentsByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	var pending []models.Payment
+	for _, payment := range list {
+		if payment.Status == enums.PaymentStatusPending && payment.Provider == s.payments.Name() {
+			pending = append(pending, payment)
+		}
+	}
+	for _, payment := range pending {
+		if payment.Amount == amount || (amount == 0 && payment.Amount == balance) {
+			return &payment, nil
+		}
+	}
+	for _, payment := range pending {
+		balance -= payment.Amount
+	}
+	if amount == 0 {
+		amount = balance
+	}
+	if amount <= 0 || amount > balance {
+		return nil, errPaymentExceedsBalance
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	returnURL := s.cfg.Payments.Online.ReturnURL
+	if returnURL == "" {
+		returnURL = s.cfg.App.URL
+	}
+
+	created, err := s.payments.CreatePayment(ctx, &payments.CreateRequest{
+		PaymentUUID: uid,
+		Amount:      amount,
+		Currency:    s.onlinePaymentCurrency(),
+		Description: fmt.Sprintf("Заказ #%d", order.ID),
+		ReturnURL:   returnURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		UUID:            uid,
+		OrderID:         order.ID,
+		UserID:          userID,
+		Provider:        s.payments.Name(),
+		Status:          enums.PaymentStatusPending,
+		Amount:          amount,
+		Currency:        s.onlinePaymentCurrency(),
+		ExternalID:      new(created.ExternalID),
+		ConfirmationURL: new(created.ConfirmationURL),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	err = s.store.CreatePayment(ctx, payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	s.store.Commit(ctx)
+
+	return payment, nil
+}
+
+func (s *Service) paymentWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.payments == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	update, err := s.payments.ParseWebhook(r.Context(), r)
+	if err != nil {
+		s.log.Error("Failed to parse payment webhook", err)
+		if errors.Is(err, payments.ErrInvalidWebhook) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	err = s.processPaymentUpdate(r.Context(), s.payments.Name(), update)
+	if err != nil {
+		s.log.Error("Failed to process payment webhook", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Service) confirmFakePayment(w http.ResponseWriter, r *http.Request) {
+	if s.payments == nil || s.payments.Name() != enums.PaymentProviderFake {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	externalID := r.PathValue("external_id")
+	payment, err := s.store.GetPaymentByExternalID(r.Context(), enums.PaymentProviderFake, externalID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.log.Error("Failed to get fake payment", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.processPaymentUpdate(r.Context(), enums.PaymentProviderFake, &payments.Payment{
+		ExternalID:  externalID,
+		PaymentUUID: payment.UUID,
+		Status:      enums.PaymentStatusSucceeded,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+	})
+	if err != nil {
+		s.log.Error("Failed to confirm fake payment", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	returnURL := s.cfg.Payments.Online.ReturnURL
+	if returnURL == "" {
+		returnURL = s.cfg.App.URL
+	}
+	http.Redirect(w, r, returnURL, http.StatusSeeOther)
+}
+
+// processPaymentUpdate applies a provider notification once; repeated or out of order
+// notifications are acknowledged without changes.
+func (s *Service) processPaymentUpdate(ctx context.Context, provider enums.PaymentProvider, update *payments.Payment) error {
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	now := time.Now()
+	created, err := s.store.CreatePaymentWebhookEvent(txCtx, provider, update.ExternalID, update.Status, now)
+	if err != nil {
+		return fmt.Errorf("failed to save payment webhook event: %w", err)
+	}
+	if !created {
+		return nil
+	}
+
+	payment, err := s.store.GetPaymentByExternalID(txCtx, provider, update.ExternalID)
+	if errors.Is(err, models.ErrNotFound) && update.PaymentUUID != uuid.Nil {
+		payment, err = s.store.GetPaymentByUUID(txCtx, update.PaymentUUID)
+		if err == nil && payment.Provider != provider {
+			err = models.ErrNotFound
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get payment %s: %w", update.ExternalID, err)
+	}
+	if payment.ExternalID == nil {
+		payment.ExternalID = new(update.ExternalID)
+	}
+	status := update.Status
+	// A mismatch is not retried by the gateway, so it is acknowledged and left for a manager.
+	if status == enums.PaymentStatusSucceeded && (update.Fractional || update.Amount != payment.Amount || update.Currency != payment.Currency) {
+		s.log.Error("Payment amount mismatch", fmt.Errorf("payment %s: got %d %s, expected %d %s", payment.UUID, update.Amount, update.Currency, payment.Amount, payment.Currency))
+		status = enums.PaymentStatusReview
+	}
+
+	change, err := s.applyPaymentStatus(txCtx, payment, status, nil, now)
+	if err != nil {
+		return err
+	}
+
+	s.store.Commit(txCtx)
+
+	if change != nil {
+		s.publishPaymentChange(ctx, change, payment)
+	}
+	return nil
+}
+
+// refundPayment marks the payment as refund pending before calling the gateway, so the
+// payment row is not locked during the request. A repeated call retries a pending refund.
+func (s *Service) refundPayment(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid payment uuid: %w", err))
+	}
+
+	payment, res := s.startRefund(r.Context(), uid)
+	if res != nil {
+		return res
+	}
+
+	refund, err := s.payments.Refund(r.Context(), &payments.RefundRequest{
+		IdempotencyKey: "refund-" + payment.UUID.String(),
+		ExternalID:     *payment.ExternalID,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+	})
+	if err != nil {
+		return core.Err(http.StatusBadGateway, fmt.Errorf("failed to refund payment: %w", err))
+	}
+	if refund.Status == enums.PaymentStatusPending {
+		return core.JSON(http.StatusAccepted, payment)
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	payment, err = s.store.GetPaymentByUUID(ctx, uid)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get payment: %w", err))
+	}
+	if payment.Status != enums.PaymentStatusRefundPending {
+		return core.JSON(http.StatusOK, payment)
+	}
+
+	var change *models.OrderStatusChange
+	if refund.Status == enums.PaymentStatusRefunded {
+		change, err = s.applyPaymentStatus(ctx, payment, enums.PaymentStatusRefunded, &user.ID, time.Now())
+		if err != nil {
+			return core.Err(http.StatusInternalServerError, err)
+		}
+	} else {
+		payment.Status = enums.PaymentStatusSucceeded
+		payment.UpdatedAt = time.Now()
+		err = s.store.UpdatePayment(ctx, payment)
+		if err != nil {
+			return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update payment: %w", err))
+		}
+	}
+
+	s.store.Commit(ctx)
+
+	if change != nil {
+		s.publishPaymentChange(r.Context(), change, payment)
+	}
+	if refund.Status != enums.PaymentStatusRefunded {
+		return core.Err(http.StatusBadGateway, fmt.Errorf("payment refund was %s by the provider", refund.Status.String()))
+	}
+
+	return core.JSON(http.StatusOK, payment)
+}
+
+func (s *Service) startRefund(ctx context.Context, uid uuid.UUID) (*models.Payment, core.Response) {
+	ctx, err := s.store.Begin(ctx)
+	if err != nil {
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	payment, err := s.store.GetPaymentByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, core.Err(http.StatusNotFound, fmt.Errorf("payment not found"))
+		}
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get payment: %w", err))
+	}
+	if payment.Status != enums.PaymentStatusRefundPending && !payments.CanTransition(payment.Status, enums.PaymentStatusRefundPending) {
+		return nil, core.Err(http.StatusConflict, fmt.Errorf("payment %s can not be refunded", payment.Status.String()))
+	}
+	if s.payments == nil || payment.Provider != s.payments.Name() || payment.ExternalID == nil {
+		return nil, core.Err(http.StatusBadRequest, errRefundNotSupported)
+	}
+
+	if payment.Status != enums.PaymentStatusRefundPending {
+		payment.Status = enums.PaymentStatusRefundPending
+		payment.UpdatedAt = time.Now()
+		err = s.store.UpdatePayment(ctx, payment)
+		if err != nil {
+			return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update payment: %w", err))
+		}
+	}
+
+	s.store.Commit(ctx)
+
+	return payment, nil
+}
+
+// applyPaymentStatus moves the payment along the state machine, records it in the
+// order ledger, queues the fiscal receipt and refreshes the order totals. It returns nil when the transition is not allowed.
+func (s *Service) applyPaymentStatus(ctx context.Context, payment *models.Payment, status enums.PaymentStatus, recordedBy *int, now time.Time) (*models.OrderStatusChange, error) {
+	if !payments.CanTransition(payment.Status, status) {
+		return nil, nil
+	}
+
+	// The order is locked before the payment is written, in the same order as ledger entries,
+	// so concurrent payments of one order recalculate the totals one at a time.
+	order, err := s.store.GetOrderByIDForUpdate(ctx, payment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	payment.Status = status
+	if status == enums.PaymentStatusSucceeded {
+		payment.PaidAt = &now
+	}
+	payment.UpdatedAt = now
+	err = s.store.UpdatePayment(ctx, payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	var kind enums.LedgerEntryKind
+	switch status {
+	case enums.PaymentStatusSucceeded:
+		kind = enums.LedgerEntryKindPayment
+	case enums.PaymentStatusRefunded:
+		kind = enums.LedgerEntryKindRefund
+	}
+	if kind != (enums.LedgerEntryKind{}) {
+		uid, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uuid: %w", err)
+		}
+		err = s.store.CreateLedgerEntry(ctx, &models.LedgerEntry{
+			UUID:       uid,
+			OrderID:    payment.OrderID,
+			PaymentID:  &payment.ID,
+			Kind:       kind,
+			Method:     enums.PaymentMethodOnline,
+			Amount:     payment.Amount,
+			RecordedBy: recordedBy,
+			CreatedAt:  now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ledger entry: %w", err)
+		}
+	}
+
+	if kind != (enums.LedgerEntryKind{}) {
+		operation := enums.ReceiptOperationSell
+		if kind == enums.LedgerEntryKindRefund {
+			operation = enums.ReceiptOperationSellRefund
+		}
+		err = s.queueReceipt(ctx, order, payment, operation, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.refreshOrderPaymentTotals(ctx, order, now)
+}
+
+// refreshOrderPaymentTotals recalculates the paid amount from the ledger and completes
+// orders that were waiting for the outstanding balance.
+func (s *Service) refreshOrderPaymentTotals(ctx context.Context, order *models.Order, now time.Time) (*models.OrderStatusChange, error) {
+	paid, err := s.store.GetOrderPaidAmount(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order paid amount: %w", err)
+	}
+
+	order.PaidAmount = paid
+	switch {
+	case paid <= 0:
+		order.PaymentStatus = enums.OrderPaymentStatusUnpaid
+		order.PaidAt = nil
+	case order.Amount != nil && paid >= *order.Amount:
+		order.PaymentStatus = enums.OrderPaymentStatusPaid
+		if order.PaidAt == nil {
+			order.PaidAt = &now
+		}
+	default:
+		order.PaymentStatus = enums.OrderPaymentStatusPartiallyPaid
+		order.PaidAt = nil
+	}
+	order.UpdatedAt = now
+
+	err = s.store.UpdateOrderPayment(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order payment: %w", err)
+	}
+
+	change := &models.OrderStatusChange{Order: order, OldStatus: order.Status}
+	if order.Status == enums.RequestStatusAwaitingPayment && order.PaymentStatus == enums.OrderPaymentStatusPaid {
+		order.Status = enums.RequestStatusReviewed
+		order.StatusChangedAt = now
+		err = s.store.UpdateOrderStatus(ctx, order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update order status: %w", err)
+		}
+	}
+	return change, nil
+}
+
+// orderBalanceDue returns the part of the agreed amount that is not paid yet.
+func orderBalanceDue(order *models.Order) int {
+	if order.Amount == nil {
+		return 0
+	}
+	return max(*order.Amount-order.PaidAmount, 0)
+}
+
+func (s *Service) publishPaymentChange(ctx context.Context, change *models.OrderStatusChange, payment *models.Payment) {
+	ctx = context.WithoutCancel(ctx)
+	if payment != nil && payment.Status == enums.PaymentStatusSucceeded {
+		s.eventBus.OrderPaid.Publish(ctx, &models.OrderPayment{Order: change.Order, Payment: payment})
+	}
+	if payment != nil && (payment.Status == enums.PaymentStatusSucceeded || payment.Status == enums.PaymentStatusRefunded) {
+		s.dispatchReceipts(ctx)
+	}
+	s.eventBus.OrderChanged.Publish(ctx, change.Order)
+	if change.Order.Status != change.OldStatus {
+		s.eventBus.OrderStatusChanged.Publish(ctx, change)
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func TestOrderBalanceDue(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount *int
+		paid   int
+		want   int
+	}{
+		{"no agreed amount", nil, 500, 0},
+		{"unpaid", new(3000), 0, 3000},
+		{"partially paid", new(3000), 1000, 2000},
+		{"fully paid", new(3000), 3000, 0},
+		{"overpaid", new(3000), 3500, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Amount: tt.amount, PaidAmount: tt.paid}
+			if got := orderBalanceDue(order); got != tt.want {
+				t.Errorf("orderBalanceDue() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9]+(?:[-_][A-Z0-9]+)*$`)
+
+// promoCodeError explains why a promo code can not be applied to the cart.
+// Reason is returned to clients as a validation error, Label is shown in the bot.
+type promoCodeError struct {
+	Reason  string
+	Message string
+	Label   string
+}
+
+func (e *promoCodeError) Error() string {
+	return e.Message
+}
+
+var (
+	errPromoCodeNotFound      = &promoCodeError{"not_found", "promo code not found", "Промокод не найден"}
+	errPromoCodeInactive      = &promoCodeError{"inactive", "promo code is not active", "Промокод не действует"}
+	errPromoCodeNotOwner      = &promoCodeError{"not_owner", "promo code belongs to another user", "Промокод выдан другому пользователю"}
+	errPromoCodeNotStarted    = &promoCodeError{"not_started", "promo code is not active yet", "Промокод ещё не начал действовать"}
+	errPromoCodeExpired       = &promoCodeError{"expired", "promo code has expired", "Срок действия промокода истёк"}
+	errPromoCodeCartEmpty     = &promoCodeError{"cart_empty", "cart is empty", "Корзина пуста"}
+	errPromoCodeMinOrder      = &promoCodeError{"min_order", "cart total is below the promo code minimum", "Сумма заказа меньше минимальной для промокода"}
+	errPromoCodeNotApplicable = &promoCodeError{"not_applicable", "promo code does not apply to cart items", "Промокод не распространяется на товары в корзине"}
+	errPromoCodeUsageLimit    = &promoCodeError{"usage_limit", "promo code usage limit reached", "Промокод больше не доступен"}
+	errPromoCodeUserLimit     = &promoCodeError{"user_limit", "promo code already used", "Вы уже использовали этот промокод"}
+)
+
+func promoCodeErrorResponse(err *promoCodeError) core.Response {
+	return core.JSON(http.StatusBadRequest, core.ValidationError{
+		Message: err.Message,
+		Errors:  map[string]string{"code": err.Reason},
+	})
+}
+
+type upsertPromoCodeRequest struct {
+	Code           string     `json:"code" mold:"trim,ucase" validate:"required,max=64"`
+	Kind           string     `json:"kind" mold:"trim,lcase" validate:"required,oneof=percent fixed"`
+	Value          int        `json:"value" validate:"required,min=1"`
+	MinOrderAmount *int       `json:"min_order_amount" validate:"omitempty,min=1"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	UsageLimit     *int       `json:"usage_limit" validate:"omitempty,min=1"`
+	PerUserLimit   *int       `json:"per_user_limit" validate:"omitempty,min=1"`
+	ProductTypes   []string   `json:"product_types" validate:"omitempty,dive,oneof=product service"`
+	CategoryIDs    []int      `json:"category_ids" validate:"omitempty,dive,gt=0"`
+	IsActive       *bool      `json:"is_active"`
+}
+
+func (req *upsertPromoCodeRequest) apply(promo *models.PromoCode) error {
+	req.Code = strings.ToUpper(req.Code)
+	if !promoCodePattern.MatchString(req.Code) {
+		return fmt.Errorf("invalid promo code")
+	}
+	kind, err := enums.NewPromoCodeKind(req.Kind)
+	if err != nil {
+		return err
+	}
+	if kind == enums.PromoCodeKindPercent && req.Value > 100 {
+		return fmt.Errorf("percent discount can not exceed 100")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return fmt.Errorf("promo code must end after it starts")
+	}
+	productTypes := make([]enums.ProductType, 0, len(req.ProductTypes))
+	for _, productType := range req.ProductTypes {
+		t, err := enums.NewProductType(productType)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(productTypes, t) {
+			productTypes = append(productTypes, t)
+		}
+	}
+
+	promo.Code = req.Code
+	promo.Kind = kind
+	promo.Value = req.Value
+	promo.MinOrderAmount = req.MinOrderAmount
+	promo.StartsAt = req.StartsAt
+	promo.EndsAt = req.EndsAt
+	promo.UsageLimit = req.UsageLimit
+	promo.PerUserLimit = req.PerUserLimit
+	promo.ProductTypes = productTypes
+	promo.CategoryIDs = slices.Compact(slices.Sorted(slices.Values(req.CategoryIDs)))
+	if req.IsActive != nil {
+		promo.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func (s *Service) getPromoCodes(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	promos, err := s.store.GetAllPromoCodes(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get promo codes: %w", err))
+	}
+	return core.JSON(http.StatusOK, promos)
+}
+
+func (s *Service) getPromoCode(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid promo code uuid: %w", err))
+	}
+
+	promo, err := s.store.GetPromoCodeByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("promo code not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get promo code: %w", err))
+	}
+	return core.JSON(http.StatusOK, promo)
+}
+
+func (s *Service) createPromoCode(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[upsertPromoCodeRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	now := time.Now()
+	promo := &models.PromoCode{
+		UUID:      uid,
+		IsActive:  true,
+		CreatedBy: &user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = req.apply(promo)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+
+	return s.savePromoCode(r.Context(), promo, http.StatusCreated)
+}
+
+func (s *Service) updatePromoCode(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid promo code uuid: %w", err))
+	}
+
+	req, res := core.Validate[upsertPromoCodeRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	promo, err := s.store.GetPromoCodeByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("promo code not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get promo code: %w", err))
+	}
+	err = req.apply(promo)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+	promo.UpdatedAt = time.Now()
+
+	return s.savePromoCode(r.Context(), promo, http.StatusOK)
+}
+
+func (s *Service) savePromoCode(ctx context.Context, promo *models.PromoCode, code int) core.Response {
+	ctx, err := s.store.Begin(ctx)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	if promo.ID == 0 {
+		err = s.store.CreatePromoCode(ctx, promo)
+	} else {
+		err = s.store.UpdatePromoCode(ctx, promo)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrUniqueViolation) {
+			return core.Err(http.StatusConflict, fmt.Errorf("promo code already exists"))
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusBadRequest, fmt.Errorf("category not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to save promo code: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	return core.JSON(code, promo)
+}
+
+func (s *Service) deletePromoCode(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid promo code uuid: %w", err))
+	}
+
+	err = s.store.DeletePromoCodeByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("promo code not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to delete promo code: %w", err))
+	}
+
+	return core.JSON(http.StatusNoContent, nil)
+}
+
+type applyPromoCodeRequest struct {
+	Code string `json:"code" mold:"trim,ucase" validate:"required,max=64"`
+}
+
+func (s *Service) getCartPromoCode(r *http.Request, user *models.User) core.Response {
+	promo, err := s.store.GetUserCartPromoCode(r.Context(), user.ID, false)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("promo code is not applied"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get cart promo code: %w", err))
+	}
+
+	cartPromo, err := s.evaluateCartPromoCode(r.Context(), promo, user.ID)
+	if err != nil {
+		return cartPromoErrorResponse(err)
+	}
+
+	return core.JSON(http.StatusOK, cartPromo)
+}
+
+func (s *Service) applyCartPromoCode(r *http.Request, user *models.User) core.Response {
+	req, res := core.Validate[applyPromoCodeRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	promo, err := s.store.GetPromoCodeByCode(r.Context(), req.Code)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return promoCodeErrorResponse(errPromoCodeNotFound)
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get promo code: %w", err))
+	}
+
+	cartPromo, err := s.evaluateCartPromoCode(r.Context(), promo, user.ID)
+	if err != nil {
+		return cartPromoErrorResponse(err)
+	}
+
+	err = s.store.SetUserCartPromoCode(r.Context(), user.ID, &promo.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to apply promo code: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, cartPromo)
+}
+
+func (s *Service) deleteCartPromoCode(r *http.Request, user *models.User) core.Response {
+	err := s.store.SetUserCartPromoCode(r.Context(), user.ID, nil)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to remove promo code: %w", err))
+	}
+
+	return core.JSON(http.StatusNoContent, nil)
+}
+
+func cartPromoErrorResponse(err error) core.Response {
+	if promoErr, ok := errors.AsType[*promoCodeError](err); ok {
+		return promoCodeErrorResponse(promoErr)
+	}
+	return core.Err(http.StatusInternalServerError, err)
+}
+
+func (s *Service) evaluateCartPromoCode(ctx context.Context, promo *models.PromoCode, userID int) (*models.CartPromo, error) {
+	items, err := s.store.GetUserCartItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	return s.evaluatePromoCode(ctx, promo, userID, items, time.Now())
+}
+
+// evaluatePromoCode checks the promo code rules against the cart and calculates the discount.
+// Category and product type restrictions limit the discount to matching items; the minimum
+// order amount is compared with the whole cart.
+func (s *Service) evaluatePromoCode(ctx context.Context, promo *models.PromoCode, userID int, items []models.CartItem, now time.Time) (*models.CartPromo, error) {
+	switch {
+	case !promo.IsActive:
+		return nil, errPromoCodeInactive
+	case promo.OwnerID != nil && *promo.OwnerID != userID:
+		return nil, errPromoCodeNotOwner
+	case promo.StartsAt != nil && now.Before(*promo.StartsAt):
+		return nil, errPromoCodeNotStarted
+	case promo.EndsAt != nil && !now.Before(*promo.EndsAt):
+		return nil, errPromoCodeExpired
+	case len(items) == 0:
+		return nil, errPromoCodeCartEmpty
+	}
+
+	categoryIDsByProductID := map[int][]int{}
+	if len(promo.CategoryIDs) > 0 {
+		productIDs := make([]int, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		var err error
+		categoryIDsByProductID, err = s.store.GetCategoryIDsByProductIDs(ctx, productIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product categories: %w", err)
+		}
+	}
+
+	subtotal, eligible := 0, 0
+	for _, item := range items {
+		sum := item.PriceFrom * item.Qty
+		subtotal += sum
+		if len(promo.ProductTypes) > 0 && !slices.Contains(promo.ProductTypes, item.Type) {
+			continue
+		}
+		if len(promo.CategoryIDs) > 0 && !slices.ContainsFunc(categoryIDsByProductID[item.ProductID], func(id int) bool {
+			return slices.Contains(promo.CategoryIDs, id)
+		}) {
+			continue
+		}
+		eligible += sum
+	}
+	if promo.MinOrderAmount != nil && subtotal < *promo.MinOrderAmount {
+		return nil, errPromoCodeMinOrder
+	}
+	if eligible == 0 {
+		return nil, errPromoCodeNotApplicable
+	}
+
+	if promo.UsageLimit != nil || promo.PerUserLimit != nil {
+		total, byUser, err := s.store.CountPromoCodeUsages(ctx, promo.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count promo code usages: %w", err)
+		}
+		if promo.UsageLimit != nil && total >= *promo.UsageLimit {
+			return nil, errPromoCodeUsageLimit
+		}
+		if promo.PerUserLimit != nil && byUser >= *promo.PerUserLimit {
+			return nil, errPromoCodeUserLimit
+		}
+	}
+
+	discount := min(promo.Value, eligible)
+	if promo.Kind == enums.PromoCodeKindPercent {
+		discount = eligible * promo.Value / 100
+	}
+
+	return &models.CartPromo{
+		Code:     promo.Code,
+		Subtotal: subtotal,
+		Discount: discount,
+		Total:    subtotal - discount,
+	}, nil
+}
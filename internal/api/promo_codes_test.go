@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func TestEvaluatePromoCode(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cart := []models.CartItem{
+		{ProductID: 1, PriceFrom: 3000, Qty: 1, Type: enums.ProductTypeService},
+		{ProductID: 2, PriceFrom: 500, Qty: 2, Type: enums.ProductTypeProduct},
+	}
+	tests := []struct {
+		name    string
+		promo   models.PromoCode
+		items   []models.CartItem
+		want    *models.CartPromo
+		wantErr error
+	}{
+		{
+			name:  "fixed discount",
+			promo: models.PromoCode{Code: "FIX", Kind: enums.PromoCodeKindFixed, Value: 700, IsActive: true},
+			items: cart,
+			want:  &models.CartPromo{Code: "FIX", Subtotal: 4000, Discount: 700, Total: 3300},
+		},
+		{
+			name:  "percent discount",
+			promo: models.PromoCode{Code: "PCT", Kind: enums.PromoCodeKindPercent, Value: 15, IsActive: true},
+			items: cart,
+			want:  &models.CartPromo{Code: "PCT", Subtotal: 4000, Discount: 600, Total: 3400},
+		},
+		{
+			name:  "fixed discount capped by eligible items",
+			promo: models.PromoCode{Code: "FIX", Kind: enums.PromoCodeKindFixed, Value: 5000, IsActive: true, ProductTypes: []enums.ProductType{enums.ProductTypeProduct}},
+			items: cart,
+			want:  &models.CartPromo{Code: "FIX", Subtotal: 4000, Discount: 1000, Total: 3000},
+		},
+		{
+			name:  "owner applies own code",
+			promo: models.PromoCode{Code: "REF", Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, OwnerID: new(42)},
+			items: cart,
+			want:  &models.CartPromo{Code: "REF", Subtotal: 4000, Discount: 500, Total: 3500},
+		},
+		{
+			name:    "code of another user",
+			promo:   models.PromoCode{Code: "REF", Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, OwnerID: new(7)},
+			items:   cart,
+			wantErr: errPromoCodeNotOwner,
+		},
+		{
+			name:    "inactive",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500},
+			items:   cart,
+			wantErr: errPromoCodeInactive,
+		},
+		{
+			name:    "not started",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, StartsAt: new(now.Add(time.Hour))},
+			items:   cart,
+			wantErr: errPromoCodeNotStarted,
+		},
+		{
+			name:    "expired",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, EndsAt: new(now)},
+			items:   cart,
+			wantErr: errPromoCodeExpired,
+		},
+		{
+			name:    "empty cart",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true},
+			wantErr: errPromoCodeCartEmpty,
+		},
+		{
+			name:    "below minimum order",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, MinOrderAmount: new(5000)},
+			items:   cart,
+			wantErr: errPromoCodeMinOrder,
+		},
+		{
+			name:    "no eligible items",
+			promo:   models.PromoCode{Kind: enums.PromoCodeKindFixed, Value: 500, IsActive: true, ProductTypes: []enums.ProductType{enums.ProductTypeProduct}},
+			items:   cart[:1],
+			wantErr: errPromoCodeNotApplicable,
+		},
+	}
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.evaluatePromoCode(t.Context(), &tt.promo, 42, tt.items, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("evaluatePromoCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (got == nil || *got != *tt.want) {
+				t.Errorf("evaluatePromoCode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/receipts"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+var errReceiptsDisabled = errors.New("receipts are disabled")
+
+const (
+	receiptBatchSize  = 20
+	receiptLease      = 5 * time.Minute
+	receiptMaxBackoff = 6 * time.Hour
+)
+
+func (s *Service) registerReceiptListeners() {
+	s.eventBus.OrderStatusChanged.Subscribe(func(ctx context.Context, change *models.OrderStatusChange) error {
+		if s.receipts == nil || change == nil || change.Order == nil || change.Order.Status != enums.RequestStatusReviewed {
+			return nil
+		}
+
+		err := s.queueSettlementReceipts(ctx, change.Order, time.Now())
+		if err != nil {
+			return err
+		}
+		s.dispatchReceipts(ctx)
+		return nil
+	})
+}
+
+func (s *Service) getOrderReceipts(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+
+	list, err := s.store.GetReceiptsByOrderID(r.Context(), order.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get receipts: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, list)
+}
+
+// retryReceipt puts a failed receipt back into the queue, e.g. after fixing the operator settings.
+func (s *Service) retryReceipt(r *http.Request, user *models.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+	if s.receipts == nil {
+		return core.Err(http.StatusBadRequest, errReceiptsDisabled)
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid receipt uuid: %w", err))
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	receipt, err := s.store.GetReceiptByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("receipt not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get receipt: %w", err))
+	}
+	if receipt.Status != enums.ReceiptStatusFailed {
+		return core.Err(http.StatusConflict, fmt.Errorf("receipt %s can not be retried", receipt.Status.String()))
+	}
+
+	now := time.Now()
+	receipt.Status = enums.ReceiptStatusPending
+	if receipt.ExternalID != nil && receipt.Operator == s.receipts.Name() {
+		receipt.Status = enums.ReceiptStatusSent
+	}
+	receipt.Attempts = 0
+	receipt.NextAttemptAt = now
+	receipt.UpdatedAt = now
+	err = s.store.UpdateReceipt(ctx, receipt)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update receipt: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	s.dispatchReceipts(context.WithoutCancel(r.Context()))
+
+	return core.JSON(http.StatusOK, receipt)
+}
+
+// queueReceipt stores a receipt for the payment in the current transaction. It is sent
+// to the fiscal operator by dispatchReceipts once the transaction is committed.
+func (s *Service) queueReceipt(ctx context.Context, order *models.Order, payment *models.Payment, operation enums.ReceiptOperation, now time.Time) error {
+	if s.receipts == nil {
+		return nil
+	}
+
+	var payload *models.ReceiptPayload
+	if operation == enums.ReceiptOperationSellRefund {
+		list, err := s.store.GetReceiptsByOrderID(ctx, order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get receipts: %w", err)
+		}
+		for _, receipt := range list {
+			if receipt.PaymentID == payment.ID && receipt.Operation == enums.ReceiptOperationSell {
+				payload = &receipt.Payload
+				payload.CreatedAt = now
+			}
+		}
+	}
+	if payload == nil {
+		itemsByOrderID, err := s.store.GetOrderItemsByOrderIDs(ctx, []int{order.ID})
+		if err != nil {
+			return fmt.Errorf("failed to get order items: %w", err)
+		}
+		withItems := *order
+		withItems.Items = itemsByOrderID[order.ID]
+
+		email, err := s.getReceiptEmail(ctx, order, payment)
+		if err != nil {
+			return err
+		}
+		compose := receipts.Compose
+		if operation == enums.ReceiptOperationSellSettlement {
+			compose = receipts.ComposeSettlement
+		}
+		payload = new(compose(s.cfg.Receipts, &withItems, payment, email, now))
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	_, err = s.store.CreateReceipt(ctx, &models.Receipt{
+		UUID:          uid,
+		OrderID:       order.ID,
+		PaymentID:     payment.ID,
+		Operation:     operation,
+		Status:        enums.ReceiptStatusPending,
+		Operator:      s.receipts.Name(),
+		Payload:       *payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create receipt: %w", err)
+	}
+	return nil
+}
+
+// queueSettlementReceipts issues a full_payment receipt for every succeeded payment that
+// was registered as an advance, once the order is completed.
+func (s *Service) queueSettlementReceipts(ctx context.Context, order *models.Order, now time.Time) error {
+	if s.receipts == nil {
+		return nil
+	}
+
+	list, err := s.store.GetReceiptsByOrderID(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get receipts: %w", err)
+	}
+	prepaid := make(map[int]bool)
+	for _, receipt := range list {
+		if receipt.Operation != enums.ReceiptOperationSell {
+			continue
+		}
+		for _, item := range receipt.Payload.Items {
+			if item.PaymentMethod != receipts.PaymentMethodFullPayment {
+				prepaid[receipt.PaymentID] = true
+			}
+		}
+	}
+	if len(prepaid) == 0 {
+		return nil
+	}
+
+	payments, err := s.store.GetPaymentsByOrderID(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get payments: %w", err)
+	}
+	for _, payment := range payments {
+		if payment.Status != enums.PaymentStatusSucceeded || !prepaid[payment.ID] {
+			continue
+		}
+		err = s.queueReceipt(ctx, order, &payment, enums.ReceiptOperationSellSettlement, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) getReceiptEmail(ctx context.Context, order *models.Order, payment *models.Payment) (string, error) {
+	userID := payment.UserID
+	if userID == nil {
+		userID = order.UserID
+	}
+	if userID == nil {
+		return "", nil
+	}
+	user, err := s.store.GetUserByID(ctx, *userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Email == nil {
+		return "", nil
+	}
+	return *user.Email, nil
+}
+
+// dispatchReceipts claims due receipts and hands them over to the worker pool.
+func (s *Service) dispatchReceipts(ctx context.Context) {
+	err := s.processDueReceipts(ctx)
+	if err != nil {
+		s.log.Error("Failed to dispatch receipts", err)
+	}
+}
+
+func (s *Service) processDueReceipts(ctx context.Context) error {
+	if s.receipts == nil {
+		return nil
+	}
+
+	now := time.Now()
+	list, err := s.store.ClaimDueReceipts(ctx, now, now.Add(receiptLease), receiptBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim receipts: %w", err)
+	}
+	for _, receipt := range list {
+		s.workerPool.Submit(func() error {
+			return s.submitReceipt(ctx, &receipt)
+		})
+	}
+	return nil
+}
+
+// submitReceipt registers a pending receipt or polls a sent one. Failed attempts are
+// retried with exponential backoff until the attempts limit is reached.
+func (s *Service) submitReceipt(ctx context.Context, receipt *models.Receipt) error {
+	var (
+		result *receipts.Result
+		err    error
+	)
+	switch {
+	case receipt.Status == enums.ReceiptStatusPending:
+		receipt.Operator = s.receipts.Name()
+		result, err = s.receipts.Register(ctx, receipt)
+	case receipt.Operator != s.receipts.Name() || receipt.ExternalID == nil:
+		err = fmt.Errorf("%w: receipt was sent to %s", receipts.ErrRejected, receipt.Operator)
+	default:
+		result, err = s.receipts.Check(ctx, *receipt.ExternalID)
+	}
+
+	now := time.Now()
+	receipt.UpdatedAt = now
+	if err != nil {
+		receipt.Attempts++
+		receipt.LastError = new(err.Error())
+		receipt.NextAttemptAt = now.Add(s.receiptBackoff(receipt.Attempts))
+		if errors.Is(err, receipts.ErrRejected) || (s.cfg.Receipts.MaxAttempts > 0 && receipt.Attempts >= s.cfg.Receipts.MaxAttempts) {
+			receipt.Status = enums.ReceiptStatusFailed
+		}
+		updateErr := s.store.UpdateReceipt(ctx, receipt)
+		if updateErr != nil {
+			return fmt.Errorf("failed to update receipt: %w", updateErr)
+		}
+		return fmt.Errorf("failed to submit receipt %s: %w", receipt.UUID, err)
+	}
+
+	receipt.ExternalID = new(result.ExternalID)
+	receipt.LastError = nil
+	if result.Fiscal != nil {
+		receipt.Status = enums.ReceiptStatusDone
+		receipt.Fiscal = result.Fiscal
+	} else {
+		receipt.Status = enums.ReceiptStatusSent
+		receipt.NextAttemptAt = now.Add(s.receiptRetryInterval())
+	}
+	err = s.store.UpdateReceipt(ctx, receipt)
+	if err != nil {
+		return fmt.Errorf("failed to update receipt: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) receiptRetryInterval() time.Duration {
+	if s.cfg.Receipts.RetryInterval > 0 {
+		return s.cfg.Receipts.RetryInterval
+	}
+	return time.Minute
+}
+
+func (s *Service) receiptBackoff(attempts int) time.Duration {
+	backoff := s.receiptRetryInterval()
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= receiptMaxBackoff {
+			return receiptMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	referralStartPrefix  = "ref_"
+	referralCodeAttempts = 5
+)
+
+var errReferralsDisabled = errors.New("referrals are disabled")
+
+func (s *Service) getMeReferral(r *http.Request, user *model.User) core.Response {
+	if !s.cfg.Referrals.Enabled {
+		return core.Err(http.StatusBadRequest, errReferralsDisabled)
+	}
+
+	link, err := s.getReferralLink(r.Context(), user)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
+	return core.JSON(http.StatusOK, link)
+}
+
+func (s *Service) getReferralReport(r *http.Request, user *model.User) core.Response {
+	res := allowForAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	from, to, err := parseReportPeriod(r)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, err)
+	}
+
+	report, err := s.store.GetReferralReport(r.Context(), from, to)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get referral report: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, report)
+}
+
+func (s *Service) handleReferralCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	if !s.cfg.Referrals.Enabled {
+		s.replyToCommand(ctx, b, message, "Реферальная программа сейчас не работает", nil)
+		return nil
+	}
+
+	link, err := s.getReferralLink(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	reward := ""
+	if s.cfg.Referrals.Reward.Enabled {
+		reward = " Когда он сделает первый заказ, мы пришлём вам промокод\\."
+	}
+	text := fmt.Sprintf(
+		"🎁 *Приглашайте друзей*\n\nОтправьте другу ссылку ниже\\.%s\n\n%s\n\nПриглашено: %d, сделали заказ: %d",
+		reward,
+		bot.EscapeMarkdown(link.URL),
+		link.Invited,
+		link.Converted,
+	)
+	s.replyToCommand(ctx, b, message, text, nil)
+	return nil
+}
+
+func (s *Service) getReferralLink(ctx context.Context, user *model.User) (*model.ReferralLink, error) {
+	code, err := s.ensureReferralCode(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	invited, converted, err := s.store.CountReferrals(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count referrals: %w", err)
+	}
+
+	return &model.ReferralLink{
+		Code:      code,
+		URL:       referralLink(code),
+		Invited:   invited,
+		Converted: converted,
+	}, nil
+}
+
+// ensureReferralCode assigns a random referral code to the user on first use.
+func (s *Service) ensureReferralCode(ctx context.Context, user *model.User) (string, error) {
+	if user.ReferralCode != nil {
+		return *user.ReferralCode, nil
+	}
+
+	for range referralCodeAttempts {
+		id := make([]byte, 5)
+		_, err := rand.Read(id)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+
+		user.ReferralCode = new(hex.EncodeToString(id))
+		user.UpdatedAt = time.Now()
+		err = s.store.UpdateUserReferralCode(ctx, user)
+		if err == nil {
+			return *user.ReferralCode, nil
+		}
+		user.ReferralCode = nil
+		if !errors.Is(err, model.ErrUniqueViolation) {
+			return "", fmt.Errorf("failed to update referral code: %w", err)
+		}
+	}
+	return "", fmt.Errorf("failed to generate unique referral code")
+}
+
+// referralCodeFromStart extracts the referral code from a "/start ref_<code>" deep link.
+func referralCodeFromStart(message *models.Message) string {
+	command, args, ok := parseCommand(message)
+	if !ok || command != "start" {
+		return ""
+	}
+	code, ok := strings.CutPrefix(args, referralStartPrefix)
+	if !ok {
+		return ""
+	}
+	return code
+}
+
+// attributeReferral links a newly created user to the owner of the referral code.
+// Unknown codes and self-referrals are ignored.
+func (s *Service) attributeReferral(ctx context.Context, user *model.User, code string) error {
+	if !s.cfg.Referrals.Enabled || code == "" {
+		return nil
+	}
+
+	referrer, err := s.store.GetUserByReferralCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get referrer: %w", err)
+	}
+	if referrer.ID == user.ID {
+		return nil
+	}
+
+	err = s.store.CreateReferral(ctx, &model.Referral{
+		ReferrerID: referrer.ID,
+		ReferredID: user.ID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil && !errors.Is(err, model.ErrUniqueViolation) {
+		return fmt.Errorf("failed to create referral: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) registerReferralListeners() {
+	s.eventBus.OrderCreated.Subscribe(func(ctx context.Context, order *model.Order) error {
+		if !s.cfg.Referrals.Enabled || order == nil || order.UserID == nil {
+			return nil
+		}
+
+		txCtx, err := s.store.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer s.store.Rollback(txCtx)
+
+		referral, err := s.store.ConvertReferral(txCtx, *order.UserID, order.ID, time.Now())
+		if err != nil {
+			if errors.Is(err, model.ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to convert referral: %w", err)
+		}
+
+		var promo *model.PromoCode
+		if s.cfg.Referrals.Reward.Enabled {
+			promo, err = s.createReferralReward(txCtx, referral)
+			if err != nil {
+				return err
+			}
+		}
+
+		s.store.Commit(txCtx)
+
+		return s.notifyReferrer(ctx, referral, promo)
+	})
+}
+
+// createReferralReward issues a single-use promo code that only the referrer can apply.
+// It runs in the transaction that converts the referral.
+func (s *Service) createReferralReward(ctx context.Context, referral *model.Referral) (*model.PromoCode, error) {
+	kind, err := enums.NewPromoCodeKind(s.cfg.Referrals.Reward.Kind)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	id := make([]byte, 4)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate promo code: %w", err)
+	}
+
+	now := time.Now()
+	promo := &model.PromoCode{
+		UUID:         uid,
+		Code:         "REF-" + strings.ToUpper(hex.EncodeToString(id)),
+		Kind:         kind,
+		Value:        s.cfg.Referrals.Reward.Value,
+		StartsAt:     new(now),
+		UsageLimit:   new(1),
+		PerUserLimit: new(1),
+		IsActive:     true,
+		OwnerID:      new(referral.ReferrerID),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if s.cfg.Referrals.Reward.ValidFor > 0 {
+		promo.EndsAt = new(now.Add(s.cfg.Referrals.Reward.ValidFor))
+	}
+
+	err = s.store.CreatePromoCode(ctx, promo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+	referral.RewardPromoCodeID = new(promo.ID)
+	err = s.store.UpdateReferralReward(ctx, referral)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update referral reward: %w", err)
+	}
+	return promo, nil
+}
+
+func (s *Service) notifyReferrer(ctx context.Context, referral *model.Referral, promo *model.PromoCode) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	referrer, err := s.store.GetUserByID(ctx, referral.ReferrerID)
+	if err != nil {
+		return fmt.Errorf("failed to get referrer: %w", err)
+	}
+	if referrer.TID == nil {
+		return nil
+	}
+
+	text := "🎉 *Ваш друг сделал первый заказ*\n\nСпасибо, что рекомендуете OLA Studio\\!"
+	if promo != nil {
+		discount := formatAmount(promo.Value)
+		if promo.Kind == enums.PromoCodeKindPercent {
+			discount = strconv.Itoa(promo.Value) + "%"
+		}
+		text += fmt.Sprintf(
+			"\n\nВаш промокод на скидку %s: `%s`",
+			bot.EscapeMarkdown(discount),
+			bot.EscapeMarkdown(promo.Code),
+		)
+		if promo.EndsAt != nil {
+			text += fmt.Sprintf("\nДействует до %s", bot.EscapeMarkdown(promo.EndsAt.Format("02.01.2006")))
+		}
+	}
+
+	_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    *referrer.TID,
+		ParseMode: models.ParseModeMarkdown,
+		Text:      text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send referrer telegram message: %w", err)
+	}
+	return nil
+}
+
+func referralLink(code string) string {
+	return botURL + "?start=" + referralStartPrefix + code
+}
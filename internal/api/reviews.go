@@ -0,0 +1,314 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const landingReviewsLimit = 6
+
+type createReviewRequest struct {
+	OrderUUID   string   `json:"order_uuid" validate:"required,uuid"`
+	ProductUUID string   `json:"product_uuid" validate:"required,uuid"`
+	Rating      int      `json:"rating" validate:"required,min=1,max=5"`
+	Content     string   `json:"content" mold:"trim" validate:"required,max=3000"`
+	Files       []string `json:"files" validate:"max=5,dive,required,max=255,startswith=/files/"`
+}
+
+type updateReviewRequest struct {
+	Rating  int      `json:"rating" validate:"required,min=1,max=5"`
+	Content string   `json:"content" mold:"trim" validate:"required,max=3000"`
+	Files   []string `json:"files" validate:"max=5,dive,required,max=255,startswith=/files/"`
+}
+
+type updateReviewStatusRequest struct {
+	Status enums.ReviewStatus `json:"status"`
+	Reason *string            `json:"reason" mold:"trim" validate:"omitempty,max=1000"`
+}
+
+// getReviews returns the moderation queue for moderators, filtered by ?status, and own reviews for other users.
+func (s *Service) getReviews(r *http.Request, user *models.User) core.Response {
+	var (
+		status *enums.ReviewStatus
+		userID *int
+	)
+	if allowForModeratorOrAdmin(user) == nil {
+		if value := r.URL.Query().Get("status"); value != "" {
+			st, err := enums.NewReviewStatus(value)
+			if err != nil {
+				return core.Err(http.StatusBadRequest, fmt.Errorf("invalid review status: %w", err))
+			}
+			status = &st
+		}
+	} else {
+		userID = &user.ID
+	}
+
+	reviews, err := s.store.GetReviews(r.Context(), status, userID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get reviews: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, reviews)
+}
+
+func (s *Service) getReview(r *http.Request, user *models.User) core.Response {
+	review, res := s.findReview(r.Context(), r.PathValue("uuid"), user)
+	if res != nil {
+		return res
+	}
+
+	return core.JSON(http.StatusOK, review)
+}
+
+// createReview lets the customer review a product from an own completed order. Reviews are
+// published on the landing after moderation.
+func (s *Service) createReview(r *http.Request, user *models.User) core.Response {
+	req, res := core.Validate[createReviewRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	files, res := reviewFiles(req.Files)
+	if res != nil {
+		return res
+	}
+	orderUUID, err := uuid.Parse(req.OrderUUID)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+	productUUID, err := uuid.Parse(req.ProductUUID)
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid product uuid: %w", err))
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), orderUUID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+	if order.UserID == nil || *order.UserID != user.ID {
+		return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+	}
+	if order.Status != enums.RequestStatusReviewed {
+		return core.Err(http.StatusConflict, fmt.Errorf("only completed orders can be reviewed"))
+	}
+
+	product, err := s.store.GetProductByUUID(r.Context(), productUUID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("product not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get product: %w", err))
+	}
+	itemsByOrderID, err := s.store.GetOrderItemsByOrderIDs(r.Context(), []int{order.ID})
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order items: %w", err))
+	}
+	ordered := false
+	for _, item := range itemsByOrderID[order.ID] {
+		if item.ProductID == product.ID {
+			ordered = true
+		}
+	}
+	if !ordered {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("product is not in the order"))
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	now := time.Now()
+	review := &models.Review{
+		UUID:        uid,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		OrderID:     order.ID,
+		UserID:      user.ID,
+		AuthorName:  user.FirstName,
+		Rating:      req.Rating,
+		Content:     req.Content,
+		Files:       files,
+		Status:      enums.ReviewStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.store.CreateReview(r.Context(), review)
+	if err != nil {
+		if errors.Is(err, models.ErrUniqueViolation) {
+			return core.Err(http.StatusConflict, fmt.Errorf("product from this order is already reviewed"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create review: %w", err))
+	}
+
+	return core.JSON(http.StatusCreated, review)
+}
+
+// updateReview changes the author's own review and sends it back to moderation.
+func (s *Service) updateReview(r *http.Request, user *models.User) core.Response {
+	req, res := core.Validate[updateReviewRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	files, res := reviewFiles(req.Files)
+	if res != nil {
+		return res
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	review, res := s.findReview(ctx, r.PathValue("uuid"), user)
+	if res != nil {
+		return res
+	}
+	if review.UserID != user.ID {
+		return core.Err(http.StatusForbidden, fmt.Errorf("only the author can change the review"))
+	}
+
+	review.Rating = req.Rating
+	review.Content = req.Content
+	review.Files = files
+	review.Status = enums.ReviewStatusPending
+	review.ModeratorID = nil
+	review.ModeratedAt = nil
+	review.RejectionReason = nil
+	review.UpdatedAt = time.Now()
+	err = s.store.UpdateReview(ctx, review)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update review: %w", err))
+	}
+	err = s.store.RefreshProductRating(ctx, review.ProductID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to refresh product rating: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	return core.JSON(http.StatusOK, review)
+}
+
+func (s *Service) updateReviewStatus(r *http.Request, user *models.User) core.Response {
+	res := allowForModeratorOrAdmin(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[updateReviewStatusRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+	if req.Status != enums.ReviewStatusApproved && req.Status != enums.ReviewStatusRejected {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid review status: %s", req.Status.String()))
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	review, res := s.findReview(ctx, r.PathValue("uuid"), user)
+	if res != nil {
+		return res
+	}
+
+	now := time.Now()
+	review.Status = req.Status
+	review.ModeratorID = &user.ID
+	review.ModeratedAt = &now
+	review.RejectionReason = nil
+	if req.Status == enums.ReviewStatusRejected {
+		review.RejectionReason = req.Reason
+	}
+	review.UpdatedAt = now
+	err = s.store.UpdateReview(ctx, review)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update review: %w", err))
+	}
+	err = s.store.RefreshProductRating(ctx, review.ProductID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to refresh product rating: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	return core.JSON(http.StatusOK, review)
+}
+
+func (s *Service) deleteReview(r *http.Request, user *models.User) core.Response {
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	review, res := s.findReview(ctx, r.PathValue("uuid"), user)
+	if res != nil {
+		return res
+	}
+
+	err = s.store.DeleteReview(ctx, review.ID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to delete review: %w", err))
+	}
+	err = s.store.RefreshProductRating(ctx, review.ProductID)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to refresh product rating: %w", err))
+	}
+
+	s.store.Commit(ctx)
+
+	return core.JSON(http.StatusNoContent, nil)
+}
+
+// reviewFiles drops duplicate photos and rejects paths outside of uploaded files.
+func reviewFiles(files []string) ([]string, core.Response) {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if path.Clean(file) != file {
+			return nil, core.Err(http.StatusBadRequest, fmt.Errorf("invalid review file: %s", file))
+		}
+		if !slices.Contains(result, file) {
+			result = append(result, file)
+		}
+	}
+	return result, nil
+}
+
+// findReview returns the review if the user is its author or a moderator.
+func (s *Service) findReview(ctx context.Context, value string, user *models.User) (*models.Review, core.Response) {
+	uid, err := uuid.Parse(value)
+	if err != nil {
+		return nil, core.Err(http.StatusBadRequest, fmt.Errorf("invalid review uuid: %w", err))
+	}
+
+	review, err := s.store.GetReviewByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, core.Err(http.StatusNotFound, fmt.Errorf("review not found"))
+		}
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get review: %w", err))
+	}
+	if review.UserID != user.ID && allowForModeratorOrAdmin(user) != nil {
+		return nil, core.Err(http.StatusNotFound, fmt.Errorf("review not found"))
+	}
+	return review, nil
+}
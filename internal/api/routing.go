@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+type telegramDestination struct {
+	chatID  int
+	topicID int
+}
+
+func (s *Service) orderDestinations(ctx context.Context, order *model.Order) ([]telegramDestination, error) {
+	var categories []string
+	if slices.ContainsFunc(s.cfg.Telegram.Routes, func(route config.TelegramRouteConfig) bool {
+		return route.Kind == "order" && len(route.Categories) > 0
+	}) {
+		var err error
+		categories, err = s.store.GetCategorySlugsByOrderID(ctx, order.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order categories: %w", err)
+		}
+	}
+
+	return s.routeDestinations("order", func(route config.TelegramRouteConfig) bool {
+		return matchRoute(route.Sources, order.Source.String()) &&
+			matchRoute(route.DeliveryTypes, order.DeliveryType.String()) &&
+			len(route.FeedbackTypes) == 0 &&
+			(len(route.Categories) == 0 || slices.ContainsFunc(categories, func(slug string) bool {
+				return slices.Contains(route.Categories, slug)
+			}))
+	}), nil
+}
+
+func (s *Service) feedbackDestinations(feedback *model.Feedback) []telegramDestination {
+	return s.routeDestinations("feedback", func(route config.TelegramRouteConfig) bool {
+		return matchRoute(route.Sources, feedback.Source.String()) &&
+			matchRoute(route.FeedbackTypes, feedback.Type.String()) &&
+			len(route.Categories) == 0 &&
+			len(route.DeliveryTypes) == 0
+	})
+}
+
+func (s *Service) routeDestinations(kind string, match func(route config.TelegramRouteConfig) bool) []telegramDestination {
+	destinations := make([]telegramDestination, 0)
+	for _, route := range s.cfg.Telegram.Routes {
+		if route.Kind != kind || !match(route) {
+			continue
+		}
+		destination := telegramDestination{chatID: route.ChatID, topicID: route.TopicID}
+		if destination.chatID == 0 {
+			destination.chatID = s.cfg.Telegram.GroupID
+		}
+		if !slices.Contains(destinations, destination) {
+			destinations = append(destinations, destination)
+		}
+	}
+	if len(destinations) == 0 {
+		destinations = append(destinations, telegramDestination{chatID: s.cfg.Telegram.GroupID})
+	}
+	return destinations
+}
+
+func matchRoute(values []string, value string) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
+
+// isManagerChat reports whether the chat is the manager group or a chat of any route.
+func (s *Service) isManagerChat(chatID int64) bool {
+	if chatID == int64(s.cfg.Telegram.GroupID) {
+		return true
+	}
+	return slices.ContainsFunc(s.cfg.Telegram.Routes, func(route config.TelegramRouteConfig) bool {
+		return route.ChatID != 0 && int64(route.ChatID) == chatID
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+type upsertSegmentRequest struct {
+	Name              string   `json:"name" mold:"trim" validate:"required,max=255"`
+	Tags              []string `json:"tags" validate:"max=20,dive,required,max=64"`
+	ExcludeTags       []string `json:"exclude_tags" validate:"max=20,dive,required,max=64"`
+	Sources           []string `json:"sources" validate:"omitempty,dive,oneof=landing spa tma bot"`
+	OrderedWithinDays *int     `json:"ordered_within_days" validate:"omitempty,min=1,max=3650"`
+	MinOrders         *int     `json:"min_orders" validate:"omitempty,min=1"`
+	MinOrdersAmount   *int     `json:"min_orders_amount" validate:"omitempty,min=1"`
+	HasTelegram       bool     `json:"has_telegram"`
+}
+
+func (req *upsertSegmentRequest) apply(segment *models.Segment) {
+	segment.Name = req.Name
+	segment.Filter = models.SegmentFilter{
+		Tags:              mergeTags(nil, req.Tags),
+		ExcludeTags:       mergeTags(nil, req.ExcludeTags),
+		Sources:           mergeTags(nil, req.Sources),
+		OrderedWithinDays: req.OrderedWithinDays,
+		MinOrders:         req.MinOrders,
+		MinOrdersAmount:   req.MinOrdersAmount,
+		HasTelegram:       req.HasTelegram,
+	}
+}
+
+func (s *Service) getSegments(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	segments, err := s.store.GetAllSegments(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get segments: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, segments)
+}
+
+func (s *Service) getSegment(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	segment, res := s.findSegment(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+
+	return core.JSON(http.StatusOK, segment)
+}
+
+// getSegmentCustomers previews the customers currently matching the segment.
+func (s *Service) getSegmentCustomers(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	segment, res := s.findSegment(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+
+	customers, err := s.store.GetSegmentCustomers(r.Context(), segment.Filter, time.Now())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get segment customers: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, customers)
+}
+
+func (s *Service) createSegment(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[upsertSegmentRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to generate uuid v7: %w", err))
+	}
+	now := time.Now()
+	segment := &models.Segment{
+		UUID:      uid,
+		CreatedBy: &user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	req.apply(segment)
+
+	err = s.store.CreateSegment(r.Context(), segment)
+	if err != nil {
+		if errors.Is(err, models.ErrUniqueViolation) {
+			return core.Err(http.StatusConflict, fmt.Errorf("segment already exists"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to create segment: %w", err))
+	}
+
+	return core.JSON(http.StatusCreated, segment)
+}
+
+func (s *Service) updateSegment(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	req, res := core.Validate[upsertSegmentRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	segment, res := s.findSegment(r.Context(), r.PathValue("uuid"))
+	if res != nil {
+		return res
+	}
+	req.apply(segment)
+	segment.UpdatedAt = time.Now()
+
+	err := s.store.UpdateSegment(r.Context(), segment)
+	if err != nil {
+		if errors.Is(err, models.ErrUniqueViolation) {
+			return core.Err(http.StatusConflict, fmt.Errorf("segment already exists"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update segment: %w", err))
+	}
+
+	return core.JSON(http.StatusOK, segment)
+}
+
+func (s *Service) deleteSegment(r *http.Request, user *models.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid segment uuid: %w", err))
+	}
+
+	err = s.store.DeleteSegmentByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("segment not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to delete segment: %w", err))
+	}
+
+	return core.JSON(http.StatusNoContent, nil)
+}
+
+func (s *Service) findSegment(ctx context.Context, value string) (*models.Segment, core.Response) {
+	uid, err := uuid.Parse(value)
+	if err != nil {
+		return nil, core.Err(http.StatusBadRequest, fmt.Errorf("invalid segment uuid: %w", err))
+	}
+
+	segment, err := s.store.GetSegmentByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, core.Err(http.StatusNotFound, fmt.Errorf("segment not found"))
+		}
+		return nil, core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get segment: %w", err))
+	}
+	return segment, nil
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	slaLevelGroup = 1
+	slaLevelAdmin = 2
+)
+
+type slaBreach struct {
+	kind            string
+	id              int
+	uuid            uuid.UUID
+	title           string
+	status          enums.RequestStatus
+	statusChangedAt time.Time
+	assigneeID      *int
+	elapsed         time.Duration
+	threshold       time.Duration
+}
+
+func (s *Service) orderSLAThreshold(order *model.Order) (time.Duration, bool) {
+	if order.Status == enums.RequestStatusReviewed {
+		return 0, false
+	}
+	d, ok := s.cfg.SLA.Orders[order.Status.String()]
+	return d, ok && d > 0
+}
+
+func (s *Service) feedbackSLAThreshold(feedback *model.Feedback) (time.Duration, bool) {
+	if feedback.Status == enums.RequestStatusReviewed {
+		return 0, false
+	}
+	d, ok := s.cfg.SLA.Feedback[feedback.Type.String()]
+	return d, ok && d > 0
+}
+
+func (s *Service) applyOrderSLA(orders []model.Order, now time.Time) {
+	for i := range orders {
+		s.setOrderSLA(&orders[i], now)
+	}
+}
+
+func (s *Service) applyFeedbackSLA(feedback []model.Feedback, now time.Time) {
+	for i := range feedback {
+		s.setFeedbackSLA(&feedback[i], now)
+	}
+}
+
+// setOrderSLA fills the SLA fields in place, so callers must pass the order they respond with, not a copy.
+func (s *Service) setOrderSLA(order *model.Order, now time.Time) {
+	elapsed := now.Sub(order.StatusChangedAt)
+	order.TimeInStatus = int64(elapsed.Seconds())
+	threshold, ok := s.orderSLAThreshold(order)
+	order.SLABreached = ok && elapsed >= threshold
+}
+
+// setFeedbackSLA fills the SLA fields in place, so callers must pass the feedback they respond with, not a copy.
+func (s *Service) setFeedbackSLA(feedback *model.Feedback, now time.Time) {
+	elapsed := now.Sub(feedback.StatusChangedAt)
+	feedback.TimeInStatus = int64(elapsed.Seconds())
+	threshold, ok := s.feedbackSLAThreshold(feedback)
+	feedback.SLABreached = ok && elapsed >= threshold
+}
+
+func (s *Service) checkSLA(ctx context.Context) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	now := time.Now()
+	breaches := make([]slaBreach, 0)
+
+	orders, err := s.store.GetUnfinishedOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get unfinished orders: %w", err)
+	}
+	for i := range orders {
+		threshold, ok := s.orderSLAThreshold(&orders[i])
+		elapsed := now.Sub(orders[i].StatusChangedAt)
+		if !ok || elapsed < threshold {
+			continue
+		}
+		breaches = append(breaches, slaBreach{
+			kind:            "order",
+			id:              orders[i].ID,
+			uuid:            orders[i].UUID,
+			title:           "Заказ",
+			status:          orders[i].Status,
+			statusChangedAt: orders[i].StatusChangedAt,
+			assigneeID:      orders[i].AssigneeID,
+			elapsed:         elapsed,
+			threshold:       threshold,
+		})
+	}
+
+	feedback, err := s.store.GetUnfinishedFeedback(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get unfinished feedback: %w", err)
+	}
+	for i := range feedback {
+		threshold, ok := s.feedbackSLAThreshold(&feedback[i])
+		elapsed := now.Sub(feedback[i].StatusChangedAt)
+		if !ok || elapsed < threshold {
+			continue
+		}
+		breaches = append(breaches, slaBreach{
+			kind:            "feedback",
+			id:              feedback[i].ID,
+			uuid:            feedback[i].UUID,
+			title:           "Обратная связь",
+			status:          feedback[i].Status,
+			statusChangedAt: feedback[i].StatusChangedAt,
+			assigneeID:      feedback[i].AssigneeID,
+			elapsed:         elapsed,
+			threshold:       threshold,
+		})
+	}
+
+	var errs []error
+	for _, breach := range breaches {
+		err = s.escalate(ctx, breach, slaLevelGroup, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if s.cfg.SLA.AdminFactor > 0 && breach.elapsed >= breach.threshold*time.Duration(s.cfg.SLA.AdminFactor) {
+			err = s.escalate(ctx, breach, slaLevelAdmin, now)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// escalate records the escalation and sends it. The record is committed only after a successful
+// send, so a failed escalation is retried on the next check.
+func (s *Service) escalate(ctx context.Context, breach slaBreach, level int, now time.Time) error {
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	created, err := s.store.CreateSLAEscalation(txCtx, &model.SLAEscalation{
+		Kind:            breach.kind,
+		EntityID:        breach.id,
+		Status:          breach.status,
+		StatusChangedAt: breach.statusChangedAt,
+		Level:           level,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save sla escalation: %w", err)
+	}
+	if !created {
+		return nil
+	}
+
+	var assignee *model.User
+	if breach.assigneeID != nil {
+		assignee, err = s.store.GetUserByID(ctx, *breach.assigneeID)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("failed to get assignee: %w", err)
+		}
+	}
+
+	text := buildSLATelegramText(breach, assignee)
+	switch level {
+	case slaLevelGroup:
+		err = s.escalateToGroup(ctx, breach, text)
+	case slaLevelAdmin:
+		err = s.escalateToAdmins(ctx, breach, text)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.store.Commit(txCtx)
+	return nil
+}
+
+func (s *Service) escalateToGroup(ctx context.Context, breach slaBreach, text string) error {
+	params := &bot.SendMessageParams{
+		ChatID:    s.cfg.Telegram.GroupID,
+		ParseMode: models.ParseModeMarkdown,
+		Text:      text,
+	}
+
+	var chatID, messageID int64
+	switch breach.kind {
+	case "order":
+		message, err := s.store.GetOrderTelegramMessageByOrderID(ctx, breach.id)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("failed to load order telegram message: %w", err)
+		}
+		if message != nil {
+			chatID, messageID = message.ChatID, message.MessageID
+		}
+	case "feedback":
+		message, err := s.store.GetFeedbackTelegramMessageByFeedbackID(ctx, breach.id)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("failed to load feedback telegram message: %w", err)
+		}
+		if message != nil {
+			chatID, messageID = message.ChatID, message.MessageID
+		}
+	}
+	if messageID != 0 {
+		params.ChatID = chatID
+		params.ReplyParameters = &models.ReplyParameters{MessageID: int(messageID), AllowSendingWithoutReply: true}
+	} else {
+		params.ReplyMarkup = slaKeyboard(breach)
+	}
+
+	_, err := s.bot.SendMessage(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to send sla telegram message: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) escalateToAdmins(ctx context.Context, breach slaBreach, text string) error {
+	admins, err := s.getNotificationRecipients(ctx, enums.NotificationChannelTelegram, enums.NotificationEventSLABreach)
+	if err != nil {
+		return fmt.Errorf("failed to get admins: %w", err)
+	}
+
+	var errs []error
+	for _, admin := range admins {
+		if admin.TID == nil {
+			continue
+		}
+		_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      *admin.TID,
+			ParseMode:   models.ParseModeMarkdown,
+			Text:        text,
+			ReplyMarkup: slaKeyboard(breach),
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send sla telegram message to admin %d: %w", admin.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func slaKeyboard(breach slaBreach) models.ReplyMarkup {
+	text := "Посмотреть заказ"
+	if breach.kind == "feedback" {
+		text = "Посмотреть заявку"
+	}
+	return models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: text, URL: miniAppLink(breach.kind, breach.uuid)}},
+		},
+	}
+}
+
+func buildSLATelegramText(breach slaBreach, assignee *model.User) string {
+	text := fmt.Sprintf(
+		"⏰ %s \\#%s в статусе «%s» уже %s \\(SLA %s\\)",
+		bot.EscapeMarkdown(breach.title),
+		bot.EscapeMarkdown(strconv.Itoa(breach.id)),
+		bot.EscapeMarkdown(breach.status.Label()),
+		bot.EscapeMarkdown(formatDuration(breach.elapsed.Truncate(time.Minute))),
+		bot.EscapeMarkdown(formatDuration(breach.threshold)),
+	)
+	if assignee != nil {
+		text += "\n*Ответственный\\:* " + mentionUser(assignee)
+	}
+	return text
+}
+
+func mentionUser(user *model.User) string {
+	name := bot.EscapeMarkdown(user.FirstName)
+	if user.TID == nil {
+		return name
+	}
+	return fmt.Sprintf("[%s](tg://user?id=%d)", name, *user.TID)
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"strconv"
 	"strings"
@@ -23,21 +25,82 @@ func (s *Service) startBot(ctx context.Context) error {
 		s.log.Info("Telegram bot disabled")
 		return errTelegramBotDisabled
 	}
+	webhook := s.cfg.Telegram.Webhook.Enabled && s.cfg.App.IsProduction
+	if s.cfg.Telegram.Webhook.Enabled && !webhook {
+		s.log.Info("Telegram webhook is ignored outside production, falling back to long polling")
+	}
+	if webhook && s.cfg.Telegram.Webhook.Secret == "" {
+		return errors.New("telegram webhook secret is required")
+	}
 	b, err := bot.New(
 		s.cfg.Telegram.BotToken,
+		bot.WithWebhookSecretToken(s.cfg.Telegram.Webhook.Secret),
 		bot.WithDefaultHandler(s.defaultHandler),
 		bot.WithCallbackQueryDataHandler(orderCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleOrderStatusCallback, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin)),
 		bot.WithCallbackQueryDataHandler(feedbackCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleFeedbackStatusCallback, enums.UserRoleModerator, enums.UserRoleAdmin)),
+		bot.WithCallbackQueryDataHandler(feedbackOrderCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleFeedbackOrderCallback, enums.UserRoleModerator, enums.UserRoleAdmin)),
+		bot.WithCallbackQueryDataHandler(notificationsCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleNotificationsCallback, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin)),
+		bot.WithCallbackQueryDataHandler(botOrderCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleBotOrderCallback, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin)),
+		bot.WithCallbackQueryDataHandler(orderThreadCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleOrderThreadCallback, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin)),
+		bot.WithCallbackQueryDataHandler(ratingCallbackPrefix, bot.MatchTypePrefix, s.callbackQueryHandler(s.handleOrderRatingCallback, enums.UserRoleUser, enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin)),
 	)
 	if err != nil {
 		return err
 	}
 	s.bot = b
-	b.Start(ctx)
+	s.registerBotHandlers(b)
+
+	if !webhook {
+		_, err = b.DeleteWebhook(ctx, &bot.DeleteWebhookParams{})
+		if err != nil {
+			return fmt.Errorf("failed to delete telegram webhook: %w", err)
+		}
+		b.Start(ctx)
+		return nil
+	}
+
+	_, err = b.SetWebhook(ctx, &bot.SetWebhookParams{
+		URL:         s.webhookURL(),
+		SecretToken: s.cfg.Telegram.Webhook.Secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set telegram webhook: %w", err)
+	}
+	s.log.Infof("Telegram webhook set to %s", s.webhookURL())
+	b.StartWebhook(ctx)
+
+	deleteCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_, err = b.DeleteWebhook(deleteCtx, &bot.DeleteWebhookParams{})
+	if err != nil {
+		return fmt.Errorf("failed to delete telegram webhook: %w", err)
+	}
 	return nil
 }
 
-func (s *Service) createUserIfNotExists(ctx context.Context, from models.User) (*model.User, error) {
+func (s *Service) webhookURL() string {
+	if s.cfg.Telegram.Webhook.URL != "" {
+		return s.cfg.Telegram.Webhook.URL
+	}
+	return strings.TrimSuffix(s.cfg.App.URL, "/") + "/api/telegram/webhook"
+}
+
+func (s *Service) telegramWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if s.cfg.Telegram.Webhook.Secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(s.cfg.Telegram.Webhook.Secret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.bot == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.bot.WebhookHandler()(w, r)
+}
+
+// createUserIfNotExists returns the user with the Telegram ID. New users are attributed
+// to the owner of referralCode when one is given.
+func (s *Service) createUserIfNotExists(ctx context.Context, from models.User, referralCode string) (*model.User, error) {
 	user, err := s.store.GetUserByTID(ctx, from.ID)
 	if err != nil {
 		if !errors.Is(err, model.ErrNotFound) {
@@ -62,6 +125,18 @@ func (s *Service) createUserIfNotExists(ctx context.Context, from models.User) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
 		}
+		err = s.attributeReferral(ctx, user, referralCode)
+		if err != nil {
+			s.log.Error("Failed to attribute referral", err)
+		}
+	}
+	if user.BotBlockedAt != nil {
+		user.BotBlockedAt = nil
+		user.UpdatedAt = time.Now()
+		err = s.store.UpdateUserBotBlockedAt(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unblock user: %w", err)
+		}
 	}
 	return user, nil
 }
@@ -71,13 +146,29 @@ func (s *Service) defaultHandler(ctx context.Context, b *bot.Bot, update *models
 		return
 	}
 
-	_, err := s.createUserIfNotExists(ctx, *update.Message.From)
+	user, err := s.createUserIfNotExists(ctx, *update.Message.From, referralCodeFromStart(update.Message))
 	if err != nil {
 		s.log.Error("Failed to create user", err)
 		return
 	}
 
 	if update.Message.Chat.Type == models.ChatTypePrivate {
+		handled, conversationErr := s.handleBotConversationMessage(ctx, b, update.Message, user)
+		if conversationErr != nil {
+			s.log.Error("Failed to handle bot conversation message", conversationErr)
+		}
+		if handled {
+			return
+		}
+
+		relayed, relayErr := s.relayFromCustomer(ctx, b, update.Message, user)
+		if relayErr != nil {
+			s.log.Error("Failed to relay message from customer", relayErr)
+		}
+		if relayed {
+			return
+		}
+
 		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:    update.Message.Chat.ID,
 			ParseMode: models.ParseModeMarkdown,
@@ -88,6 +179,9 @@ func (s *Service) defaultHandler(ctx context.Context, b *bot.Bot, update *models
 			}, {
 				Text:   "Стать партнёром",
 				WebApp: &models.WebAppInfo{URL: "https://ola.creavo.ru/spa/settings/partnership"},
+			}}, {{
+				Text:         "Оформить в чате",
+				CallbackData: botOrderCallbackPrefix + ":start",
 			}}}},
 		})
 		if err != nil {
@@ -104,7 +198,7 @@ func (s *Service) callbackQueryHandler(fn func(context.Context, *bot.Bot, *model
 			return
 		}
 
-		user, err := s.createUserIfNotExists(ctx, callback.From)
+		user, err := s.createUserIfNotExists(ctx, callback.From, "")
 		if err != nil {
 			s.log.Error("Failed to create user", err)
 			return
@@ -128,7 +222,13 @@ func (s *Service) handleOrderStatusCallback(ctx context.Context, b *bot.Bot, cal
 		return "Не удалось распарсить данные", nil
 	}
 
-	order, err := s.store.GetOrderByID(ctx, orderID)
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return "Не удалось обновить статус", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	order, err := s.store.GetOrderByIDForUpdate(txCtx, orderID)
 	if err != nil {
 		if errors.Is(err, model.ErrNotFound) {
 			return "Заказ не найден", nil
@@ -136,20 +236,25 @@ func (s *Service) handleOrderStatusCallback(ctx context.Context, b *bot.Bot, cal
 		return "Не удалось получить заказ", fmt.Errorf("failed to load order after telegram callback: %w", err)
 	}
 
-	order.Status = status
-	order.UpdatedAt = time.Now()
-	err = s.store.UpdateOrderStatus(ctx, order)
+	oldStatus := order.Status
+	setOrderStatus(order, status, user, time.Now())
+	err = s.store.UpdateOrderStatus(txCtx, order)
 	if err != nil {
 		return "Не удалось обновить статус", fmt.Errorf("failed to update order status from telegram callback: %w", err)
 	}
 
+	s.store.Commit(txCtx)
+
 	s.eventBus.OrderChanged.Publish(context.WithoutCancel(ctx), order)
-	return fmt.Sprintf("Статус: %s", status.Label()), nil
+	if order.Status != oldStatus {
+		s.eventBus.OrderStatusChanged.Publish(context.WithoutCancel(ctx), &model.OrderStatusChange{Order: order, OldStatus: oldStatus})
+	}
+	return fmt.Sprintf("Статус: %s", order.Status.Label()), nil
 }
 
 func (s *Service) handleFeedbackStatusCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
 	feedbackID, status, ok := parseStatusCallbackData(callback.Data, feedbackCallbackPrefix)
-	if !ok {
+	if !ok || status == enums.RequestStatusAwaitingPayment {
 		return "Не удалось распарсить данные", nil
 	}
 
@@ -161,8 +266,7 @@ func (s *Service) handleFeedbackStatusCallback(ctx context.Context, b *bot.Bot,
 		return "Не удалось получить обратную связь", fmt.Errorf("failed to load order after telegram callback: %w", err)
 	}
 
-	feedback.Status = status
-	feedback.UpdatedAt = time.Now()
+	setFeedbackStatus(feedback, status, user, time.Now())
 	err = s.store.UpdateFeedbackStatus(ctx, feedback)
 	if err != nil {
 		return "Не удалось обновить обратную связь", fmt.Errorf("failed to update order status from telegram callback: %w", err)
@@ -172,6 +276,34 @@ func (s *Service) handleFeedbackStatusCallback(ctx context.Context, b *bot.Bot,
 	return fmt.Sprintf("Статус: %s", status.Label()), nil
 }
 
+func (s *Service) handleFeedbackOrderCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	parts := strings.Split(callback.Data, ":")
+	if len(parts) != 2 || parts[0] != feedbackOrderCallbackPrefix {
+		return "Не удалось распарсить данные", nil
+	}
+	feedbackID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "Не удалось распарсить данные", nil
+	}
+
+	feedback, err := s.store.GetFeedbackByID(ctx, feedbackID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "Обратная связь не найдена", nil
+		}
+		return "Не удалось получить обратную связь", fmt.Errorf("failed to load feedback after telegram callback: %w", err)
+	}
+
+	order, err := s.convertFeedbackToOrder(ctx, feedback, user)
+	if err != nil {
+		if errors.Is(err, errFeedbackConverted) {
+			return "Заказ по заявке уже создан", nil
+		}
+		return "Не удалось создать заказ", fmt.Errorf("failed to convert feedback from telegram callback: %w", err)
+	}
+	return fmt.Sprintf("Создан заказ #%d", order.ID), nil
+}
+
 func (s *Service) answerOrderStatusCallback(ctx context.Context, b *bot.Bot, callbackID string, text string) {
 	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
 		CallbackQueryID: callbackID,
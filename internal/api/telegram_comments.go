@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const commentReactionEmoji = "👍"
+
+// isGroupMessage matches member messages in groups; commands are left to their own handlers.
+func isGroupMessage(update *models.Update) bool {
+	message := update.Message
+	if message == nil || message.From == nil || message.Chat.Type == models.ChatTypePrivate {
+		return false
+	}
+	if message.ReplyToMessage == nil && !message.IsTopicMessage {
+		return false
+	}
+	return !strings.HasPrefix(message.Text, "/")
+}
+
+func (s *Service) handleGroupMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	message := update.Message
+
+	user, err := s.createUserIfNotExists(ctx, *message.From, "")
+	if err != nil {
+		s.log.Error("Failed to create user", err)
+		return
+	}
+
+	relayed, err := s.relayToCustomer(ctx, b, message, user)
+	if err != nil {
+		s.log.Error("Failed to relay message to customer", err)
+		return
+	}
+	if relayed || message.ReplyToMessage == nil || strings.TrimSpace(message.Text) == "" {
+		return
+	}
+
+	created, err := s.createOrderCommentFromReply(ctx, message, user)
+	if err != nil {
+		s.log.Error("Failed to create order comment from telegram reply", err)
+		return
+	}
+	if !created {
+		created, err = s.createFeedbackCommentFromReply(ctx, message, user)
+		if err != nil {
+			s.log.Error("Failed to create feedback comment from telegram reply", err)
+			return
+		}
+	}
+	if created {
+		s.reactToMessage(ctx, b, message)
+	}
+}
+
+func (s *Service) reactToMessage(ctx context.Context, b *bot.Bot, message *models.Message) {
+	_, err := b.SetMessageReaction(ctx, &bot.SetMessageReactionParams{
+		ChatID:    message.Chat.ID,
+		MessageID: message.ID,
+		Reaction: []models.ReactionType{{
+			Type:              models.ReactionTypeTypeEmoji,
+			ReactionTypeEmoji: &models.ReactionTypeEmoji{Type: models.ReactionTypeTypeEmoji, Emoji: commentReactionEmoji},
+		}},
+	})
+	if err != nil {
+		s.log.Error("Failed to set message reaction", err)
+	}
+}
+
+func (s *Service) createOrderCommentFromReply(ctx context.Context, message *models.Message, user *model.User) (bool, error) {
+	telegramMessage, err := s.store.GetOrderTelegramMessageByMessageID(ctx, message.Chat.ID, int64(message.ReplyToMessage.ID))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load order telegram message: %w", err)
+	}
+	if !slices.Contains([]enums.UserRole{enums.UserRoleManager, enums.UserRoleModerator, enums.UserRoleAdmin}, user.Role) {
+		return false, nil
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return false, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	now := time.Now()
+	comment := &model.OrderComment{
+		UUID:      uid,
+		Content:   strings.TrimSpace(message.Text),
+		Direction: enums.CommentDirectionInternal,
+		OrderID:   telegramMessage.OrderID,
+		UserID:    user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.store.CreateOrderComment(ctx, comment)
+	if err != nil {
+		return false, fmt.Errorf("failed to create order comment: %w", err)
+	}
+
+	s.eventBus.OrderCommentCreated.Publish(context.WithoutCancel(ctx), comment)
+	return true, nil
+}
+
+func (s *Service) createFeedbackCommentFromReply(ctx context.Context, message *models.Message, user *model.User) (bool, error) {
+	telegramMessage, err := s.store.GetFeedbackTelegramMessageByMessageID(ctx, message.Chat.ID, int64(message.ReplyToMessage.ID))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load feedback telegram message: %w", err)
+	}
+	if !slices.Contains([]enums.UserRole{enums.UserRoleModerator, enums.UserRoleAdmin}, user.Role) {
+		return false, nil
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return false, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	now := time.Now()
+	err = s.store.CreateFeedbackComment(ctx, &model.FeedbackComment{
+		UUID:       uid,
+		Content:    strings.TrimSpace(message.Text),
+		FeedbackID: telegramMessage.FeedbackID,
+		UserID:     user.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create feedback comment: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/api/core"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	model "github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const fakePaymentCallbackPrefix = "fake_payment"
+
+var (
+	errPaymentsDisabled        = errors.New("telegram payments disabled")
+	errOrderAlreadyPaid        = errors.New("order already paid")
+	errOrderAmountMissing      = errors.New("order amount is not set")
+	errCustomerWithoutTelegram = errors.New("order customer has no telegram account")
+)
+
+func formatAmount(amount int) string {
+	return fmt.Sprintf("%d ₽", amount)
+}
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not a hundredth.
+var currencyExponents = map[string]int{
+	"CLP": 0,
+	"ISK": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"PYG": 0,
+	"UGX": 0,
+	"VND": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// minorUnits converts a whole amount into the smallest units of the currency, as Bot API invoices expect.
+func minorUnits(amount int, currency string) int {
+	exponent, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		exponent = 2
+	}
+	for range exponent {
+		amount *= 10
+	}
+	return amount
+}
+
+func (s *Service) paymentCurrency() string {
+	if s.cfg.Payments.Telegram.Currency == "" {
+		return "RUB"
+	}
+	return s.cfg.Payments.Telegram.Currency
+}
+
+func (s *Service) fakePayments() bool {
+	return s.cfg.Payments.Telegram.Fake && !s.cfg.App.IsProduction
+}
+
+type updateOrderAmountRequest struct {
+	Amount int `json:"amount" validate:"required,min=1"`
+}
+
+func (s *Service) updateOrderAmount(r *http.Request, user *model.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	req, res := core.Validate[updateOrderAmountRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+
+	err = s.setOrderAmount(r.Context(), order, req.Amount)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+
+	return core.JSON(http.StatusOK, order)
+}
+
+// setOrderAmount stores the agreed order total and recalculates its payment status. The order
+// is reloaded under the row lock, so concurrent payments are not overwritten.
+func (s *Service) setOrderAmount(ctx context.Context, order *model.Order, amount int) error {
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	locked, err := s.store.GetOrderByIDForUpdate(txCtx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	*order = *locked
+
+	now := time.Now()
+	order.Amount = &amount
+	order.UpdatedAt = now
+	err = s.store.UpdateOrderAmount(txCtx, order)
+	if err != nil {
+		return fmt.Errorf("failed to update order amount: %w", err)
+	}
+	change, err := s.refreshOrderPaymentTotals(txCtx, order, now)
+	if err != nil {
+		return err
+	}
+
+	s.store.Commit(txCtx)
+
+	s.publishPaymentChange(ctx, change, nil)
+	return nil
+}
+
+func (s *Service) createOrderInvoice(r *http.Request, user *model.User) core.Response {
+	res := allowForOrderManager(user)
+	if res != nil {
+		return res
+	}
+
+	uid, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return core.Err(http.StatusBadRequest, fmt.Errorf("invalid order uuid: %w", err))
+	}
+
+	order, err := s.store.GetOrderByUUID(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return core.Err(http.StatusNotFound, fmt.Errorf("order not found"))
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get order: %w", err))
+	}
+
+	payment, err := s.sendOrderInvoice(r.Context(), order)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPaymentsDisabled):
+			return core.Err(http.StatusServiceUnavailable, err)
+		case errors.Is(err, errOrderAlreadyPaid):
+			return core.Err(http.StatusConflict, err)
+		case errors.Is(err, errOrderAmountMissing), errors.Is(err, errCustomerWithoutTelegram):
+			return core.Err(http.StatusBadRequest, err)
+		}
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to send order invoice: %w", err))
+	}
+
+	return core.JSON(http.StatusCreated, payment)
+}
+
+// sendOrderInvoice sends the customer a Bot API invoice for the agreed order amount.
+func (s *Service) sendOrderInvoice(ctx context.Context, order *model.Order) (*model.Payment, error) {
+	if s.bot == nil || !s.cfg.Payments.Telegram.Enabled {
+		return nil, errPaymentsDisabled
+	}
+	if order.Amount == nil {
+		return nil, errOrderAmountMissing
+	}
+	balance := orderBalanceDue(order)
+	if balance <= 0 {
+		return nil, errOrderAlreadyPaid
+	}
+	if order.UserID == nil {
+		return nil, errCustomerWithoutTelegram
+	}
+
+	customer, err := s.store.GetUserByID(ctx, *order.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if customer.TID == nil {
+		return nil, errCustomerWithoutTelegram
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	now := time.Now()
+	payment := &model.Payment{
+		UUID:      uid,
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		Provider:  enums.PaymentProviderTelegram,
+		Status:    enums.PaymentStatusPending,
+		Amount:    balance,
+		Currency:  s.paymentCurrency(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if s.fakePayments() {
+		payment.Provider = enums.PaymentProviderFake
+	}
+	// The payment is saved first, so the invoice payload always matches a row at pre-checkout.
+	err = s.store.CreatePayment(ctx, payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	title := fmt.Sprintf("Заказ #%d", order.ID)
+	if s.fakePayments() {
+		_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    *customer.TID,
+			ParseMode: models.ParseModeMarkdown,
+			Text: fmt.Sprintf(
+				"🧾 *Счёт на оплату заказа \\#%s*\n\nСумма: %s\n\n_Тестовый платёж, деньги не списываются\\._",
+				bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+				bot.EscapeMarkdown(formatAmount(payment.Amount)),
+			),
+			ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{{
+				Text:         "💳 Оплатить " + formatAmount(payment.Amount),
+				CallbackData: fakePaymentCallbackPrefix + ":" + payment.UUID.String(),
+			}}}},
+		})
+	} else {
+		_, err = s.bot.SendInvoice(ctx, &bot.SendInvoiceParams{
+			ChatID:        *customer.TID,
+			Title:         title,
+			Description:   fmt.Sprintf("Оплата заказа #%d в OLA Studio", order.ID),
+			Payload:       payment.UUID.String(),
+			ProviderToken: s.cfg.Payments.Telegram.ProviderToken,
+			Currency:      payment.Currency,
+			Prices:        []models.LabeledPrice{{Label: title, Amount: minorUnits(payment.Amount, payment.Currency)}},
+		})
+	}
+	if err != nil {
+		payment.Status = enums.PaymentStatusCanceled
+		payment.UpdatedAt = time.Now()
+		updateErr := s.store.UpdatePayment(ctx, payment)
+		if updateErr != nil {
+			s.log.Error("Failed to cancel payment after failed invoice", updateErr)
+		}
+		return nil, fmt.Errorf("failed to send telegram invoice: %w", err)
+	}
+
+	return payment, nil
+}
+
+// completePayment marks a Telegram payment as succeeded. Repeated calls for
+// an already succeeded payment return it without publishing events again.
+func (s *Service) completePayment(ctx context.Context, paymentUUID uuid.UUID, externalID string, providerChargeID *string) (*model.OrderPayment, error) {
+	txCtx, err := s.store.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.store.Rollback(txCtx)
+
+	payment, err := s.store.GetPaymentByUUID(txCtx, paymentUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment.Status == enums.PaymentStatusSucceeded {
+		order, err := s.store.GetOrderByID(txCtx, payment.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order: %w", err)
+		}
+		return &model.OrderPayment{Order: order, Payment: payment}, nil
+	}
+
+	payment.ExternalID = &externalID
+	payment.ProviderChargeID = providerChargeID
+	change, err := s.applyPaymentStatus(txCtx, payment, enums.PaymentStatusSucceeded, nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, fmt.Errorf("payment %s is %s", payment.UUID, payment.Status.String())
+	}
+
+	s.store.Commit(txCtx)
+
+	s.publishPaymentChange(ctx, change, payment)
+
+	return &model.OrderPayment{Order: change.Order, Payment: payment}, nil
+}
+
+func isPreCheckoutQuery(update *models.Update) bool {
+	return update.PreCheckoutQuery != nil
+}
+
+func (s *Service) handlePreCheckoutQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.PreCheckoutQuery
+	errorMessage, err := s.checkPreCheckoutQuery(ctx, query)
+	if err != nil {
+		s.log.Error("Failed to check pre-checkout query", err)
+		errorMessage = "Не удалось проверить платёж, попробуйте позже"
+	}
+
+	_, err = b.AnswerPreCheckoutQuery(ctx, &bot.AnswerPreCheckoutQueryParams{
+		PreCheckoutQueryID: query.ID,
+		OK:                 errorMessage == "",
+		ErrorMessage:       errorMessage,
+	})
+	if err != nil {
+		s.log.Error("Failed to answer pre-checkout query", err)
+	}
+}
+
+func (s *Service) checkPreCheckoutQuery(ctx context.Context, query *models.PreCheckoutQuery) (string, error) {
+	uid, err := uuid.Parse(query.InvoicePayload)
+	if err != nil {
+		return "Счёт не найден", nil
+	}
+
+	payment, err := s.store.GetPaymentByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "Счёт не найден", nil
+		}
+		return "", fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment.Status != enums.PaymentStatusPending {
+		return "Счёт уже неактуален", nil
+	}
+	if query.Currency != payment.Currency || query.TotalAmount != minorUnits(payment.Amount, payment.Currency) {
+		return "Сумма счёта изменилась, запросите новый счёт", nil
+	}
+
+	order, err := s.store.GetOrderByID(ctx, payment.OrderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.PaymentStatus == enums.OrderPaymentStatusPaid {
+		return "Заказ уже оплачен", nil
+	}
+
+	return "", nil
+}
+
+func isSuccessfulPayment(update *models.Update) bool {
+	return update.Message != nil && update.Message.SuccessfulPayment != nil
+}
+
+func (s *Service) handleSuccessfulPayment(ctx context.Context, b *bot.Bot, update *models.Update) {
+	successfulPayment := update.Message.SuccessfulPayment
+
+	uid, err := uuid.Parse(successfulPayment.InvoicePayload)
+	if err != nil {
+		s.log.Error("Failed to parse successful payment payload", err)
+		return
+	}
+
+	result, err := s.completePayment(ctx, uid, successfulPayment.TelegramPaymentChargeID, new(successfulPayment.ProviderPaymentChargeID))
+	if err != nil {
+		s.log.Error("Failed to complete telegram payment", err)
+		return
+	}
+
+	s.sendPaymentReceived(ctx, b, update.Message.Chat.ID, result)
+}
+
+func (s *Service) handleFakePaymentCallback(ctx context.Context, b *bot.Bot, callback *models.CallbackQuery, user *model.User) (string, error) {
+	if !s.fakePayments() {
+		return "Тестовые платежи отключены", nil
+	}
+
+	uid, err := uuid.Parse(strings.TrimPrefix(callback.Data, fakePaymentCallbackPrefix+":"))
+	if err != nil {
+		return "Не удалось распарсить данные", nil
+	}
+
+	payment, err := s.store.GetPaymentByUUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return "Счёт не найден", nil
+		}
+		return "Не удалось загрузить счёт", fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment.UserID == nil || *payment.UserID != user.ID || payment.Provider != enums.PaymentProviderFake {
+		return "Это действие недоступно для вас", nil
+	}
+
+	result, err := s.completePayment(ctx, uid, fakePaymentCallbackPrefix+"_"+uid.String(), nil)
+	if err != nil {
+		return "Не удалось провести платёж", err
+	}
+
+	if message := callback.Message.Message; message != nil {
+		_, err = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+			ChatID:    message.Chat.ID,
+			MessageID: message.ID,
+		})
+		if err != nil {
+			s.log.Error("Failed to remove fake payment keyboard", err)
+		}
+		s.sendPaymentReceived(ctx, b, message.Chat.ID, result)
+	}
+
+	return "Оплата прошла", nil
+}
+
+func (s *Service) sendPaymentReceived(ctx context.Context, b *bot.Bot, chatID int64, result *model.OrderPayment) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		ParseMode: models.ParseModeMarkdown,
+		Text: fmt.Sprintf(
+			"✅ *Оплата получена*\n\nМы получили %s по заказу \\#%s\\. Спасибо\\!",
+			bot.EscapeMarkdown(formatAmount(result.Payment.Amount)),
+			bot.EscapeMarkdown(strconv.Itoa(result.Order.ID)),
+		),
+		ReplyMarkup: models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{{
+			Text: "Посмотреть заказ",
+			URL:  miniAppLink("order", result.Order.UUID),
+		}}}},
+	})
+	if err != nil {
+		s.log.Error("Failed to send payment confirmation", err)
+	}
+}
+
+func (s *Service) handleInvoiceCommand(ctx context.Context, b *bot.Bot, message *models.Message, user *model.User, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		s.replyToCommand(ctx, b, message, "Укажите номер заказа и сумму, например `/invoice 42 5000`", nil)
+		return nil
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(fields[0], "#"))
+	if err != nil {
+		s.replyToCommand(ctx, b, message, "Укажите номер заказа и сумму, например `/invoice 42 5000`", nil)
+		return nil
+	}
+
+	order, err := s.store.GetOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			s.replyToCommand(ctx, b, message, "Заказ не найден", nil)
+			return nil
+		}
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if len(fields) == 2 {
+		amount, err := strconv.Atoi(fields[1])
+		if err != nil || amount < 1 {
+			s.replyToCommand(ctx, b, message, "Сумма должна быть целым числом рублей", nil)
+			return nil
+		}
+		err = s.setOrderAmount(ctx, order, amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	payment, err := s.sendOrderInvoice(ctx, order)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPaymentsDisabled):
+			s.replyToCommand(ctx, b, message, "Оплата через Telegram отключена", nil)
+		case errors.Is(err, errOrderAlreadyPaid):
+			s.replyToCommand(ctx, b, message, "Заказ уже оплачен", nil)
+		case errors.Is(err, errOrderAmountMissing):
+			s.replyToCommand(ctx, b, message, "Сумма заказа не указана, например `/invoice 42 5000`", nil)
+		case errors.Is(err, errCustomerWithoutTelegram):
+			s.replyToCommand(ctx, b, message, "У клиента нет Telegram, выставить счёт в боте нельзя", nil)
+		default:
+			return err
+		}
+		return nil
+	}
+
+	s.replyToCommand(ctx, b, message, fmt.Sprintf(
+		"🧾 Счёт на %s по заказу \\#%s отправлен клиенту",
+		bot.EscapeMarkdown(formatAmount(payment.Amount)),
+		bot.EscapeMarkdown(strconv.Itoa(order.ID)),
+	), nil)
+	return nil
+}
+
+func (s *Service) registerPaymentListeners() {
+	s.eventBus.OrderPaid.Subscribe(func(ctx context.Context, paid *model.OrderPayment) error {
+		if s.bot == nil || paid == nil {
+			return nil
+		}
+
+		messages, err := s.store.GetOrderTelegramMessagesByOrderID(ctx, paid.Order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load order telegram messages: %w", err)
+		}
+
+		var errs []error
+		for _, message := range messages {
+			_, err = s.bot.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:          message.ChatID,
+				ParseMode:       models.ParseModeMarkdown,
+				ReplyParameters: &models.ReplyParameters{MessageID: int(message.MessageID)},
+				Text: fmt.Sprintf(
+					"💳 Оплата по заказу \\#%s: %s \\(%s\\)",
+					bot.EscapeMarkdown(strconv.Itoa(paid.Order.ID)),
+					bot.EscapeMarkdown(formatAmount(paid.Payment.Amount)),
+					bot.EscapeMarkdown(strings.ToLower(paid.Order.PaymentStatus.Label())),
+				),
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to send order paid message to chat %d: %w", message.ChatID, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
@@ -0,0 +1,26 @@
+package api
+
+import "testing"
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		want     int
+	}{
+		{1500, "RUB", 150000},
+		{1500, "rub", 150000},
+		{10, "USD", 1000},
+		{1500, "JPY", 1500},
+		{7, "KWD", 7000},
+		{0, "RUB", 0},
+		{25, "XXX", 2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := minorUnits(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("minorUnits(%d, %q) = %d, want %d", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
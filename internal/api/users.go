@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/zagvozdeen/ola/internal/api/core"
@@ -15,13 +17,79 @@ func (s *Service) getMe(r *http.Request, user *models.User) core.Response {
 	return core.JSON(http.StatusOK, user)
 }
 
+func (s *Service) getMeNotifications(r *http.Request, user *models.User) core.Response {
+	preferences, err := s.getNotificationPreferences(r.Context(), user)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+	return core.JSON(http.StatusOK, preferences)
+}
+
+type notificationPreferenceRequest struct {
+	Channel string `json:"channel" mold:"trim,lcase" validate:"required,oneof=telegram email"`
+	Event   string `json:"event" mold:"trim,lcase" validate:"required,oneof=new_order new_feedback sla_breach order_status order_comment"`
+	Enabled bool   `json:"enabled"`
+}
+
+type updateMeNotificationsRequest struct {
+	Preferences []notificationPreferenceRequest `json:"preferences" mold:"dive" validate:"required,min=1,dive"`
+}
+
+func (s *Service) updateMeNotifications(r *http.Request, user *models.User) core.Response {
+	req, res := core.Validate[updateMeNotificationsRequest](r, s.conform, s.validate)
+	if res != nil {
+		return res
+	}
+
+	defaults := defaultNotificationPreferences(user.Role)
+	preferences := make([]models.NotificationPreference, 0, len(req.Preferences))
+	for _, item := range req.Preferences {
+		channel, err := enums.NewNotificationChannel(item.Channel)
+		if err != nil {
+			return core.Err(http.StatusBadRequest, fmt.Errorf("invalid notification channel: %w", err))
+		}
+		event, err := enums.NewNotificationEvent(item.Event)
+		if err != nil {
+			return core.Err(http.StatusBadRequest, fmt.Errorf("invalid notification event: %w", err))
+		}
+		if !slices.ContainsFunc(defaults, func(p models.NotificationPreference) bool {
+			return p.Channel == channel && p.Event == event
+		}) {
+			return core.Err(http.StatusBadRequest, fmt.Errorf("notification %s is not available via %s", item.Event, item.Channel))
+		}
+		preferences = append(preferences, models.NotificationPreference{Channel: channel, Event: event, Enabled: item.Enabled})
+	}
+
+	ctx, err := s.store.Begin(r.Context())
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer s.store.Rollback(ctx)
+
+	now := time.Now()
+	for i := range preferences {
+		err = s.store.UpsertNotificationPreference(ctx, user.ID, &preferences[i], now)
+		if err != nil {
+			return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to update notification preference: %w", err))
+		}
+	}
+
+	s.store.Commit(ctx)
+
+	result, err := s.getNotificationPreferences(r.Context(), user)
+	if err != nil {
+		return core.Err(http.StatusInternalServerError, err)
+	}
+	return core.JSON(http.StatusOK, result)
+}
+
 func (s *Service) getUsers(r *http.Request, user *models.User) core.Response {
 	res := allowForAdmin(user)
 	if res != nil {
 		return res
 	}
 
-	users, err := s.store.GetAllUsers(r.Context())
+	users, err := s.store.GetAllUsers(r.Context(), normalizeTag(r.URL.Query().Get("tag")))
 	if err != nil {
 		return core.Err(http.StatusInternalServerError, fmt.Errorf("failed to get users: %w", err))
 	}
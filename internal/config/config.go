@@ -4,21 +4,31 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	App      AppConfig      `yaml:"app"`
-	DB       DBConfig       `yaml:"database"`
-	Telegram TelegramConfig `yaml:"telegram"`
-	Root     RootConfig     `yaml:"root"`
+	App       AppConfig       `yaml:"app"`
+	DB        DBConfig        `yaml:"database"`
+	Telegram  TelegramConfig  `yaml:"telegram"`
+	Mail      MailConfig      `yaml:"mail"`
+	Digest    DigestConfig    `yaml:"digest"`
+	SLA       SLAConfig       `yaml:"sla"`
+	Payments  PaymentsConfig  `yaml:"payments"`
+	Receipts  ReceiptsConfig  `yaml:"receipts"`
+	Referrals ReferralsConfig `yaml:"referrals"`
+	Campaigns CampaignsConfig `yaml:"campaigns"`
+	Ratings   RatingsConfig   `yaml:"ratings"`
+	Root      RootConfig      `yaml:"root"`
 }
 
 type AppConfig struct {
 	Host           string `yaml:"host"`
 	Port           int    `yaml:"port"`
+	URL            string `yaml:"url"`
 	Secret         string `yaml:"secret"`
 	IsProduction   bool   `yaml:"is_production"`
 	RunSeeder      bool   `yaml:"run_seeder"`
@@ -34,10 +44,148 @@ type DBConfig struct {
 }
 
 type TelegramConfig struct {
-	BotToken   string `yaml:"bot_token"`
-	BotEnabled bool   `yaml:"bot_enabled"`
-	GroupID    int    `yaml:"group_id"`
-	MiniAppURL string `yaml:"mini_app_url"`
+	BotToken   string                `yaml:"bot_token"`
+	BotEnabled bool                  `yaml:"bot_enabled"`
+	GroupID    int                   `yaml:"group_id"`
+	MiniAppURL string                `yaml:"mini_app_url"`
+	Webhook    TelegramWebhookConfig `yaml:"webhook"`
+	Routes     []TelegramRouteConfig `yaml:"routes"`
+}
+
+// TelegramRouteConfig sends matching orders or feedback to a chat or forum topic.
+// Empty filters match everything; requests matching no route go to GroupID.
+type TelegramRouteConfig struct {
+	Kind          string   `yaml:"kind"`
+	ChatID        int      `yaml:"chat_id"`
+	TopicID       int      `yaml:"topic_id"`
+	Sources       []string `yaml:"sources"`
+	FeedbackTypes []string `yaml:"feedback_types"`
+	Categories    []string `yaml:"categories"`
+	DeliveryTypes []string `yaml:"delivery_types"`
+}
+
+type TelegramWebhookConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	Secret  string `yaml:"secret"`
+}
+
+type MailConfig struct {
+	Enabled   bool       `yaml:"enabled"`
+	Transport string     `yaml:"transport"`
+	From      string     `yaml:"from"`
+	Dir       string     `yaml:"dir"`
+	SMTP      SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type DigestConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	DailyAt     string        `yaml:"daily_at"`
+	WeeklyDay   string        `yaml:"weekly_day"`
+	WeeklyAt    string        `yaml:"weekly_at"`
+	StaleAfter  time.Duration `yaml:"stale_after"`
+	TopProducts int           `yaml:"top_products"`
+}
+
+type SLAConfig struct {
+	Enabled       bool                     `yaml:"enabled"`
+	CheckInterval time.Duration            `yaml:"check_interval"`
+	AdminFactor   int                      `yaml:"admin_factor"`
+	Orders        map[string]time.Duration `yaml:"orders"`
+	Feedback      map[string]time.Duration `yaml:"feedback"`
+}
+
+type PaymentsConfig struct {
+	Telegram TelegramPaymentsConfig `yaml:"telegram"`
+	Online   OnlinePaymentsConfig   `yaml:"online"`
+}
+
+// TelegramPaymentsConfig enables Bot API invoices. With Fake set outside production
+// invoices are replaced by a button that simulates a successful payment.
+type TelegramPaymentsConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	ProviderToken string `yaml:"provider_token"`
+	Currency      string `yaml:"currency"`
+	Fake          bool   `yaml:"fake"`
+}
+
+// OnlinePaymentsConfig selects the web payment provider: "yookassa" or "fake".
+// The fake provider confirms payments instantly and is refused in production.
+type OnlinePaymentsConfig struct {
+	Enabled   bool           `yaml:"enabled"`
+	Provider  string         `yaml:"provider"`
+	Currency  string         `yaml:"currency"`
+	ReturnURL string         `yaml:"return_url"`
+	YooKassa  YooKassaConfig `yaml:"yookassa"`
+}
+
+type YooKassaConfig struct {
+	APIURL    string `yaml:"api_url"`
+	ShopID    string `yaml:"shop_id"`
+	SecretKey string `yaml:"secret_key"`
+}
+
+// ReceiptsConfig enables 54-FZ receipts for online payments. Operator is "atol" or "fake";
+// Taxation, VAT and PaymentSubject are the default tags used when composing receipts.
+type ReceiptsConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Operator       string        `yaml:"operator"`
+	INN            string        `yaml:"inn"`
+	Email          string        `yaml:"email"`
+	PaymentAddress string        `yaml:"payment_address"`
+	Taxation       string        `yaml:"taxation"`
+	VAT            string        `yaml:"vat"`
+	PaymentSubject string        `yaml:"payment_subject"`
+	RetryInterval  time.Duration `yaml:"retry_interval"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	ATOL           ATOLConfig    `yaml:"atol"`
+}
+
+type ATOLConfig struct {
+	APIURL    string `yaml:"api_url"`
+	Login     string `yaml:"login"`
+	Password  string `yaml:"password"`
+	GroupCode string `yaml:"group_code"`
+}
+
+// ReferralsConfig enables "?start=ref_<code>" links. With Reward enabled the referrer gets
+// a single-use promo code once the invited user places the first order.
+type ReferralsConfig struct {
+	Enabled bool                 `yaml:"enabled"`
+	Reward  ReferralRewardConfig `yaml:"reward"`
+}
+
+type ReferralRewardConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Kind     string        `yaml:"kind"`
+	Value    int           `yaml:"value"`
+	ValidFor time.Duration `yaml:"valid_for"`
+}
+
+// CampaignsConfig enables bot broadcasts. RatePerSecond keeps the queue below Telegram's
+// limit of about 30 messages per second, failed messages are retried up to MaxAttempts.
+type CampaignsConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	CheckInterval time.Duration `yaml:"check_interval"`
+	RatePerSecond int           `yaml:"rate_per_second"`
+	MaxAttempts   int           `yaml:"max_attempts"`
+}
+
+// RatingsConfig asks customers to rate completed orders Delay after completion. Ratings
+// at or below LowRating are reported to managers right away.
+type RatingsConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Delay         time.Duration `yaml:"delay"`
+	CheckInterval time.Duration `yaml:"check_interval"`
+	LowRating     int           `yaml:"low_rating"`
+	MaxAttempts   int           `yaml:"max_attempts"`
 }
 
 type RootConfig struct {
@@ -0,0 +1,28 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN status_changed_at TIMESTAMPTZ NULL;
+ALTER TABLE orders ADD COLUMN assignee_id INTEGER REFERENCES users (id) ON DELETE SET NULL NULL;
+UPDATE orders SET status_changed_at = updated_at;
+ALTER TABLE orders ALTER COLUMN status_changed_at SET NOT NULL;
+
+ALTER TABLE feedback ADD COLUMN status_changed_at TIMESTAMPTZ NULL;
+ALTER TABLE feedback ADD COLUMN assignee_id INTEGER REFERENCES users (id) ON DELETE SET NULL NULL;
+UPDATE feedback SET status_changed_at = updated_at;
+ALTER TABLE feedback ALTER COLUMN status_changed_at SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS sla_escalations
+(
+    kind              VARCHAR(64)    NOT NULL,
+    entity_id         INTEGER        NOT NULL,
+    status            request_status NOT NULL,
+    status_changed_at TIMESTAMPTZ    NOT NULL,
+    level             INTEGER        NOT NULL,
+    created_at        TIMESTAMPTZ    NOT NULL,
+    PRIMARY KEY (kind, entity_id, status_changed_at, level)
+);
+
+-- +goose down
+DROP TABLE IF EXISTS sla_escalations;
+ALTER TABLE feedback DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE feedback DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS status_changed_at;
@@ -0,0 +1,7 @@
+-- +goose up
+ALTER TABLE users ADD COLUMN order_notifications BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE order_comments ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose down
+ALTER TABLE order_comments DROP COLUMN IF EXISTS is_public;
+ALTER TABLE users DROP COLUMN IF EXISTS order_notifications;
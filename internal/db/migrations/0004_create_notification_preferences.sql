@@ -0,0 +1,25 @@
+-- +goose up
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    channel    VARCHAR(64) NOT NULL,
+    event      VARCHAR(64) NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, channel, event)
+);
+
+-- users.order_notifications only controlled Telegram messages about orders, keep those opt-outs.
+INSERT INTO notification_preferences (user_id, channel, event, enabled, updated_at)
+SELECT u.id, 'telegram', e.event, u.order_notifications, u.updated_at
+FROM users u
+CROSS JOIN (VALUES ('order_status'), ('order_comment')) AS e (event)
+WHERE u.order_notifications = FALSE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS order_notifications;
+
+-- +goose down
+ALTER TABLE users ADD COLUMN order_notifications BOOLEAN NOT NULL DEFAULT TRUE;
+UPDATE users SET order_notifications = FALSE
+WHERE id IN (SELECT user_id FROM notification_preferences WHERE channel = 'telegram' AND event = 'order_status' AND enabled = FALSE);
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,19 @@
+-- +goose up
+CREATE TABLE IF NOT EXISTS feedback_comments
+(
+    id          SERIAL PRIMARY KEY,
+    uuid        UUID                                                NOT NULL UNIQUE,
+    content     TEXT                                                NOT NULL,
+    feedback_id INTEGER REFERENCES feedback (id) ON DELETE RESTRICT NOT NULL,
+    user_id     INTEGER REFERENCES users (id) ON DELETE RESTRICT    NOT NULL,
+    created_at  TIMESTAMPTZ                                         NOT NULL,
+    updated_at  TIMESTAMPTZ                                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_telegram_messages_chat_message_idx ON order_telegram_messages (chat_id, message_id);
+CREATE INDEX IF NOT EXISTS feedback_telegram_messages_chat_message_idx ON feedback_telegram_messages (chat_id, message_id);
+
+-- +goose down
+DROP INDEX IF EXISTS feedback_telegram_messages_chat_message_idx;
+DROP INDEX IF EXISTS order_telegram_messages_chat_message_idx;
+DROP TABLE IF EXISTS feedback_comments;
@@ -0,0 +1,21 @@
+-- +goose up
+ALTER TABLE order_comments ADD COLUMN direction VARCHAR(32) NOT NULL DEFAULT 'internal';
+
+CREATE TABLE IF NOT EXISTS order_threads
+(
+    id                SERIAL PRIMARY KEY,
+    order_id          INTEGER REFERENCES orders (id) ON DELETE CASCADE NOT NULL,
+    chat_id           BIGINT                                           NOT NULL,
+    message_thread_id BIGINT                                           NOT NULL,
+    customer_id       INTEGER REFERENCES users (id) ON DELETE CASCADE  NOT NULL,
+    created_by        INTEGER REFERENCES users (id) ON DELETE RESTRICT NOT NULL,
+    created_at        TIMESTAMPTZ                                      NOT NULL,
+    closed_at         TIMESTAMPTZ                                      NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS order_threads_open_order_idx ON order_threads (order_id) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS order_threads_topic_idx ON order_threads (chat_id, message_thread_id);
+
+-- +goose down
+DROP TABLE IF EXISTS order_threads;
+ALTER TABLE order_comments DROP COLUMN IF EXISTS direction;
@@ -0,0 +1,5 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN delivery_type VARCHAR(32) NOT NULL DEFAULT 'pickup';
+
+-- +goose down
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_type;
@@ -0,0 +1,13 @@
+-- +goose up
+ALTER TYPE order_source ADD VALUE IF NOT EXISTS 'bot';
+
+CREATE TABLE IF NOT EXISTS bot_conversations
+(
+    user_id    INTEGER REFERENCES users (id) ON DELETE CASCADE PRIMARY KEY,
+    state      VARCHAR(64)                                     NOT NULL,
+    data       JSONB                                           NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ                                     NOT NULL
+);
+
+-- +goose down
+DROP TABLE IF EXISTS bot_conversations;
@@ -0,0 +1,28 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN amount INTEGER NULL;
+ALTER TABLE orders ADD COLUMN paid_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS payments
+(
+    id                 SERIAL PRIMARY KEY,
+    uuid               UUID UNIQUE                                      NOT NULL,
+    order_id           INTEGER REFERENCES orders (id) ON DELETE CASCADE NOT NULL,
+    user_id            INTEGER REFERENCES users (id) ON DELETE SET NULL NULL,
+    provider           VARCHAR(32)                                      NOT NULL,
+    status             VARCHAR(32)                                      NOT NULL,
+    amount             INTEGER                                          NOT NULL,
+    currency           VARCHAR(3)                                       NOT NULL,
+    external_id        VARCHAR(255)                                     NULL,
+    provider_charge_id VARCHAR(255)                                     NULL,
+    paid_at            TIMESTAMPTZ                                      NULL,
+    created_at         TIMESTAMPTZ                                      NOT NULL,
+    updated_at         TIMESTAMPTZ                                      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payments_order_id_idx ON payments (order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS payments_provider_external_id_idx ON payments (provider, external_id) WHERE external_id IS NOT NULL;
+
+-- +goose down
+DROP TABLE IF EXISTS payments;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS amount;
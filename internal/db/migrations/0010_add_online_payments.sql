@@ -0,0 +1,22 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN paid_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN payment_status VARCHAR(32) NOT NULL DEFAULT 'unpaid';
+UPDATE orders SET paid_amount = amount, payment_status = 'paid' WHERE paid_at IS NOT NULL AND amount IS NOT NULL;
+
+ALTER TABLE payments ADD COLUMN confirmation_url TEXT NULL;
+
+CREATE TABLE IF NOT EXISTS payment_webhook_events
+(
+    id          SERIAL PRIMARY KEY,
+    provider    VARCHAR(32)  NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    status      VARCHAR(32)  NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL,
+    UNIQUE (provider, external_id, status)
+);
+
+-- +goose down
+DROP TABLE IF EXISTS payment_webhook_events;
+ALTER TABLE payments DROP COLUMN IF EXISTS confirmation_url;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_status;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_amount;
@@ -0,0 +1,31 @@
+-- +goose up
+ALTER TYPE request_status ADD VALUE IF NOT EXISTS 'awaiting_payment' BEFORE 'reviewed';
+
+CREATE TABLE IF NOT EXISTS order_ledger_entries
+(
+    id          SERIAL PRIMARY KEY,
+    uuid        UUID UNIQUE                                        NOT NULL,
+    order_id    INTEGER REFERENCES orders (id) ON DELETE CASCADE   NOT NULL,
+    payment_id  INTEGER REFERENCES payments (id) ON DELETE SET NULL NULL,
+    kind        VARCHAR(32)                                        NOT NULL,
+    method      VARCHAR(32)                                        NOT NULL,
+    amount      INTEGER                                            NOT NULL CHECK (amount > 0),
+    comment     TEXT                                               NULL,
+    recorded_by INTEGER REFERENCES users (id) ON DELETE SET NULL   NULL,
+    created_at  TIMESTAMPTZ                                        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_ledger_entries_order_id_idx ON order_ledger_entries (order_id, created_at);
+
+INSERT INTO order_ledger_entries (uuid, order_id, payment_id, kind, method, amount, created_at)
+SELECT gen_random_uuid(), order_id, id, 'payment', 'online', amount, COALESCE(paid_at, updated_at)
+FROM payments
+WHERE status IN ('succeeded', 'refunded');
+
+INSERT INTO order_ledger_entries (uuid, order_id, payment_id, kind, method, amount, created_at)
+SELECT gen_random_uuid(), order_id, id, 'refund', 'online', amount, updated_at
+FROM payments
+WHERE status = 'refunded';
+
+-- +goose down
+DROP TABLE IF EXISTS order_ledger_entries;
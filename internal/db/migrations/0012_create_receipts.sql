@@ -0,0 +1,26 @@
+-- +goose up
+CREATE TABLE IF NOT EXISTS receipts
+(
+    id              SERIAL PRIMARY KEY,
+    uuid            UUID UNIQUE                                        NOT NULL,
+    order_id        INTEGER REFERENCES orders (id) ON DELETE CASCADE   NOT NULL,
+    payment_id      INTEGER REFERENCES payments (id) ON DELETE CASCADE NOT NULL,
+    operation       VARCHAR(32)                                        NOT NULL,
+    status          VARCHAR(32)                                        NOT NULL,
+    operator        VARCHAR(32)                                        NOT NULL,
+    payload         JSONB                                              NOT NULL,
+    external_id     VARCHAR(255)                                       NULL,
+    fiscal          JSONB                                              NULL,
+    attempts        INTEGER                                            NOT NULL DEFAULT 0,
+    last_error      TEXT                                               NULL,
+    next_attempt_at TIMESTAMPTZ                                        NOT NULL,
+    created_at      TIMESTAMPTZ                                        NOT NULL,
+    updated_at      TIMESTAMPTZ                                        NOT NULL,
+    UNIQUE (payment_id, operation)
+);
+
+CREATE INDEX IF NOT EXISTS receipts_due_idx ON receipts (next_attempt_at) WHERE status IN ('pending', 'sent');
+CREATE INDEX IF NOT EXISTS receipts_order_id_idx ON receipts (order_id);
+
+-- +goose down
+DROP TABLE IF EXISTS receipts;
@@ -0,0 +1,43 @@
+-- +goose up
+CREATE TABLE IF NOT EXISTS promo_codes
+(
+    id               SERIAL PRIMARY KEY,
+    uuid             UUID UNIQUE                                      NOT NULL,
+    code             VARCHAR(64) UNIQUE                               NOT NULL,
+    kind             VARCHAR(32)                                      NOT NULL,
+    value            INTEGER                                          NOT NULL CHECK (value > 0),
+    min_order_amount INTEGER                                          NULL,
+    starts_at        TIMESTAMPTZ                                      NULL,
+    ends_at          TIMESTAMPTZ                                      NULL,
+    usage_limit      INTEGER                                          NULL,
+    per_user_limit   INTEGER                                          NULL,
+    product_types    VARCHAR(32)[]                                    NOT NULL DEFAULT '{}',
+    is_active        BOOLEAN                                          NOT NULL DEFAULT TRUE,
+    created_by       INTEGER REFERENCES users (id) ON DELETE SET NULL NULL,
+    created_at       TIMESTAMPTZ                                      NOT NULL,
+    updated_at       TIMESTAMPTZ                                      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_categories
+(
+    promo_code_id INTEGER REFERENCES promo_codes (id) ON DELETE CASCADE NOT NULL,
+    category_id   INTEGER REFERENCES categories (id) ON DELETE CASCADE  NOT NULL,
+    PRIMARY KEY (promo_code_id, category_id)
+);
+
+ALTER TABLE carts ADD COLUMN promo_code_id INTEGER REFERENCES promo_codes (id) ON DELETE SET NULL NULL;
+
+ALTER TABLE orders ADD COLUMN promo_code_id INTEGER REFERENCES promo_codes (id) ON DELETE SET NULL NULL;
+ALTER TABLE orders ADD COLUMN promo_code VARCHAR(64) NULL;
+ALTER TABLE orders ADD COLUMN discount INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS orders_promo_code_id_idx ON orders (promo_code_id, user_id) WHERE promo_code_id IS NOT NULL;
+
+-- +goose down
+DROP INDEX IF EXISTS orders_promo_code_id_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code_id;
+ALTER TABLE carts DROP COLUMN IF EXISTS promo_code_id;
+DROP TABLE IF EXISTS promo_code_categories;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,20 @@
+-- +goose up
+ALTER TABLE users ADD COLUMN referral_code VARCHAR(32) UNIQUE NULL;
+
+CREATE TABLE IF NOT EXISTS referrals
+(
+    id                   SERIAL PRIMARY KEY,
+    referrer_id          INTEGER REFERENCES users (id) ON DELETE CASCADE        NOT NULL,
+    referred_id          INTEGER REFERENCES users (id) ON DELETE CASCADE UNIQUE NOT NULL,
+    order_id             INTEGER REFERENCES orders (id) ON DELETE SET NULL      NULL,
+    reward_promo_code_id INTEGER REFERENCES promo_codes (id) ON DELETE SET NULL NULL,
+    created_at           TIMESTAMPTZ                                            NOT NULL,
+    converted_at         TIMESTAMPTZ                                            NULL,
+    CHECK (referrer_id <> referred_id)
+);
+
+CREATE INDEX IF NOT EXISTS referrals_referrer_id_idx ON referrals (referrer_id);
+
+-- +goose down
+DROP TABLE IF EXISTS referrals;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
@@ -0,0 +1,12 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN attribution JSONB NULL;
+ALTER TABLE feedback ADD COLUMN attribution JSONB NULL;
+
+CREATE INDEX IF NOT EXISTS orders_source_created_at_idx ON orders (source, created_at);
+CREATE INDEX IF NOT EXISTS feedback_source_created_at_idx ON feedback (source, created_at);
+
+-- +goose down
+DROP INDEX IF EXISTS feedback_source_created_at_idx;
+DROP INDEX IF EXISTS orders_source_created_at_idx;
+ALTER TABLE feedback DROP COLUMN IF EXISTS attribution;
+ALTER TABLE orders DROP COLUMN IF EXISTS attribution;
@@ -0,0 +1,70 @@
+-- +goose up
+CREATE TABLE IF NOT EXISTS customers
+(
+    id         SERIAL PRIMARY KEY,
+    uuid       UUID UNIQUE                                      NOT NULL,
+    name       VARCHAR(255)                                     NOT NULL,
+    phone      VARCHAR(32)                                      NOT NULL,
+    user_id    INTEGER REFERENCES users (id) ON DELETE SET NULL NULL,
+    created_at TIMESTAMPTZ                                      NOT NULL,
+    updated_at TIMESTAMPTZ                                      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS customer_phones
+(
+    phone       VARCHAR(32) PRIMARY KEY                             NOT NULL,
+    customer_id INTEGER REFERENCES customers (id) ON DELETE CASCADE NOT NULL,
+    created_at  TIMESTAMPTZ                                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_phones_customer_id_idx ON customer_phones (customer_id);
+
+ALTER TABLE orders ADD COLUMN customer_id INTEGER REFERENCES customers (id) ON DELETE SET NULL NULL;
+ALTER TABLE feedback ADD COLUMN customer_id INTEGER REFERENCES customers (id) ON DELETE SET NULL NULL;
+
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS feedback_customer_id_idx ON feedback (customer_id);
+
+WITH requests AS (
+    SELECT '+7' || RIGHT(regexp_replace(phone, '\D', '', 'g'), 10) AS phone, name, user_id, created_at
+    FROM orders
+    WHERE length(regexp_replace(phone, '\D', '', 'g')) = 10
+       OR (length(regexp_replace(phone, '\D', '', 'g')) = 11 AND left(regexp_replace(phone, '\D', '', 'g'), 1) IN ('7', '8'))
+    UNION ALL
+    SELECT '+7' || RIGHT(regexp_replace(phone, '\D', '', 'g'), 10), name, user_id, created_at
+    FROM feedback
+    WHERE length(regexp_replace(phone, '\D', '', 'g')) = 10
+       OR (length(regexp_replace(phone, '\D', '', 'g')) = 11 AND left(regexp_replace(phone, '\D', '', 'g'), 1) IN ('7', '8'))
+)
+INSERT INTO customers (uuid, name, phone, user_id, created_at, updated_at)
+SELECT gen_random_uuid(),
+       (array_agg(name ORDER BY created_at DESC))[1],
+       phone,
+       (array_agg(user_id ORDER BY created_at DESC) FILTER (WHERE user_id IS NOT NULL))[1],
+       MIN(created_at),
+       MAX(created_at)
+FROM requests
+GROUP BY phone;
+
+INSERT INTO customer_phones (phone, customer_id, created_at)
+SELECT phone, id, created_at FROM customers;
+
+UPDATE orders o SET customer_id = cp.customer_id
+FROM customer_phones cp
+WHERE cp.phone = '+7' || RIGHT(regexp_replace(o.phone, '\D', '', 'g'), 10)
+  AND (length(regexp_replace(o.phone, '\D', '', 'g')) = 10
+    OR (length(regexp_replace(o.phone, '\D', '', 'g')) = 11 AND left(regexp_replace(o.phone, '\D', '', 'g'), 1) IN ('7', '8')));
+
+UPDATE feedback f SET customer_id = cp.customer_id
+FROM customer_phones cp
+WHERE cp.phone = '+7' || RIGHT(regexp_replace(f.phone, '\D', '', 'g'), 10)
+  AND (length(regexp_replace(f.phone, '\D', '', 'g')) = 10
+    OR (length(regexp_replace(f.phone, '\D', '', 'g')) = 11 AND left(regexp_replace(f.phone, '\D', '', 'g'), 1) IN ('7', '8')));
+
+-- +goose down
+DROP INDEX IF EXISTS feedback_customer_id_idx;
+DROP INDEX IF EXISTS orders_customer_id_idx;
+ALTER TABLE feedback DROP COLUMN IF EXISTS customer_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS customer_phones;
+DROP TABLE IF EXISTS customers;
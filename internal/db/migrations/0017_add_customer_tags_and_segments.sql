@@ -0,0 +1,34 @@
+-- +goose up
+ALTER TABLE customers ADD COLUMN tags VARCHAR(64)[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS customers_tags_idx ON customers USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS customer_notes
+(
+    id          SERIAL PRIMARY KEY,
+    uuid        UUID UNIQUE                                         NOT NULL,
+    customer_id INTEGER REFERENCES customers (id) ON DELETE CASCADE NOT NULL,
+    user_id     INTEGER REFERENCES users (id) ON DELETE SET NULL    NULL,
+    content     TEXT                                                NOT NULL,
+    created_at  TIMESTAMPTZ                                         NOT NULL,
+    updated_at  TIMESTAMPTZ                                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_notes_customer_id_idx ON customer_notes (customer_id);
+
+CREATE TABLE IF NOT EXISTS segments
+(
+    id         SERIAL PRIMARY KEY,
+    uuid       UUID UNIQUE                                      NOT NULL,
+    name       VARCHAR(255) UNIQUE                              NOT NULL,
+    filter     JSONB                                            NOT NULL,
+    created_by INTEGER REFERENCES users (id) ON DELETE SET NULL NULL,
+    created_at TIMESTAMPTZ                                      NOT NULL,
+    updated_at TIMESTAMPTZ                                      NOT NULL
+);
+
+-- +goose down
+DROP TABLE IF EXISTS segments;
+DROP TABLE IF EXISTS customer_notes;
+DROP INDEX IF EXISTS customers_tags_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,47 @@
+-- +goose up
+ALTER TABLE users ADD COLUMN bot_blocked_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS campaigns
+(
+    id            SERIAL PRIMARY KEY,
+    uuid          UUID UNIQUE                                         NOT NULL,
+    name          VARCHAR(255)                                        NOT NULL,
+    text          TEXT                                                NOT NULL,
+    image         VARCHAR(255)                                        NULL,
+    image_file_id VARCHAR(255)                                        NULL,
+    button_text   VARCHAR(64)                                         NULL,
+    segment_id    INTEGER REFERENCES segments (id) ON DELETE SET NULL NULL,
+    status        VARCHAR(32)                                         NOT NULL,
+    scheduled_at  TIMESTAMPTZ                                         NULL,
+    started_at    TIMESTAMPTZ                                         NULL,
+    finished_at   TIMESTAMPTZ                                         NULL,
+    created_by    INTEGER REFERENCES users (id) ON DELETE SET NULL    NULL,
+    created_at    TIMESTAMPTZ                                         NOT NULL,
+    updated_at    TIMESTAMPTZ                                         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_due_idx ON campaigns (scheduled_at) WHERE status = 'scheduled';
+
+CREATE TABLE IF NOT EXISTS campaign_recipients
+(
+    id              SERIAL PRIMARY KEY,
+    campaign_id     INTEGER REFERENCES campaigns (id) ON DELETE CASCADE NOT NULL,
+    user_id         INTEGER REFERENCES users (id) ON DELETE CASCADE     NOT NULL,
+    chat_id         BIGINT                                              NOT NULL,
+    status          VARCHAR(32)                                         NOT NULL,
+    message_id      INTEGER                                             NULL,
+    attempts        INTEGER                                             NOT NULL DEFAULT 0,
+    last_error      TEXT                                                NULL,
+    next_attempt_at TIMESTAMPTZ                                         NOT NULL,
+    sent_at         TIMESTAMPTZ                                         NULL,
+    created_at      TIMESTAMPTZ                                         NOT NULL,
+    updated_at      TIMESTAMPTZ                                         NOT NULL,
+    UNIQUE (campaign_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_recipients_due_idx ON campaign_recipients (next_attempt_at) WHERE status = 'pending';
+
+-- +goose down
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS campaigns;
+ALTER TABLE users DROP COLUMN IF EXISTS bot_blocked_at;
@@ -0,0 +1,30 @@
+-- +goose up
+ALTER TABLE products ADD COLUMN rating NUMERIC(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN reviews_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id               SERIAL PRIMARY KEY,
+    uuid             UUID UNIQUE                                         NOT NULL,
+    product_id       INTEGER REFERENCES products (id) ON DELETE CASCADE  NOT NULL,
+    order_id         INTEGER REFERENCES orders (id) ON DELETE CASCADE    NOT NULL,
+    user_id          INTEGER REFERENCES users (id) ON DELETE CASCADE     NOT NULL,
+    rating           SMALLINT                                            NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    content          TEXT                                                NOT NULL,
+    files            VARCHAR(255)[]                                      NOT NULL DEFAULT '{}',
+    status           VARCHAR(32)                                         NOT NULL,
+    moderator_id     INTEGER REFERENCES users (id) ON DELETE SET NULL    NULL,
+    moderated_at     TIMESTAMPTZ                                         NULL,
+    rejection_reason TEXT                                                NULL,
+    created_at       TIMESTAMPTZ                                         NOT NULL,
+    updated_at       TIMESTAMPTZ                                         NOT NULL,
+    UNIQUE (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_product_id_idx ON reviews (product_id) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS reviews_pending_idx ON reviews (created_at) WHERE status = 'pending';
+
+-- +goose down
+DROP TABLE IF EXISTS reviews;
+ALTER TABLE products DROP COLUMN IF EXISTS reviews_count;
+ALTER TABLE products DROP COLUMN IF EXISTS rating;
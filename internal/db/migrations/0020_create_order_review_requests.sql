@@ -0,0 +1,23 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN rating SMALLINT NULL CHECK (rating BETWEEN 1 AND 5);
+ALTER TABLE orders ADD COLUMN rated_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS order_review_requests
+(
+    id         SERIAL PRIMARY KEY,
+    order_id   INTEGER UNIQUE REFERENCES orders (id) ON DELETE CASCADE NOT NULL,
+    channel    VARCHAR(32)                                             NULL,
+    attempts   INTEGER                                                 NOT NULL DEFAULT 0,
+    last_error TEXT                                                    NULL,
+    due_at     TIMESTAMPTZ                                             NOT NULL,
+    sent_at    TIMESTAMPTZ                                             NULL,
+    created_at TIMESTAMPTZ                                             NOT NULL,
+    updated_at TIMESTAMPTZ                                             NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_review_requests_due_idx ON order_review_requests (due_at) WHERE sent_at IS NULL;
+
+-- +goose down
+DROP TABLE IF EXISTS order_review_requests;
+ALTER TABLE orders DROP COLUMN IF EXISTS rated_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS rating;
@@ -0,0 +1,7 @@
+-- +goose up
+ALTER TABLE orders ADD COLUMN feedback_id INTEGER UNIQUE REFERENCES feedback (id) ON DELETE SET NULL NULL;
+ALTER TABLE feedback ADD COLUMN order_id INTEGER REFERENCES orders (id) ON DELETE SET NULL NULL;
+
+-- +goose down
+ALTER TABLE feedback DROP COLUMN IF EXISTS order_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS feedback_id;
@@ -0,0 +1,9 @@
+-- +goose up
+ALTER TABLE promo_codes ADD COLUMN owner_id INTEGER REFERENCES users (id) ON DELETE CASCADE NULL;
+
+UPDATE promo_codes p SET owner_id = r.referrer_id
+FROM referrals r
+WHERE r.reward_promo_code_id = p.id;
+
+-- +goose down
+ALTER TABLE promo_codes DROP COLUMN IF EXISTS owner_id;
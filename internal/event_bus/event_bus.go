@@ -6,17 +6,25 @@ import (
 )
 
 type EventBus struct {
-	OrderCreated    *Event[*models.Order]
-	FeedbackCreated *Event[*models.Feedback]
-	OrderChanged    *Event[*models.Order]
-	FeedbackChanged *Event[*models.Feedback]
+	UserRegistered      *Event[*models.User]
+	OrderCreated        *Event[*models.Order]
+	FeedbackCreated     *Event[*models.Feedback]
+	OrderChanged        *Event[*models.Order]
+	FeedbackChanged     *Event[*models.Feedback]
+	OrderStatusChanged  *Event[*models.OrderStatusChange]
+	OrderCommentCreated *Event[*models.OrderComment]
+	OrderPaid           *Event[*models.OrderPayment]
 }
 
 func New(pool *worker_pool.WorkerPool) *EventBus {
 	return &EventBus{
-		OrderCreated:    NewEvent[*models.Order](pool),
-		FeedbackCreated: NewEvent[*models.Feedback](pool),
-		OrderChanged:    NewEvent[*models.Order](pool),
-		FeedbackChanged: NewEvent[*models.Feedback](pool),
+		UserRegistered:      NewEvent[*models.User](pool),
+		OrderCreated:        NewEvent[*models.Order](pool),
+		FeedbackCreated:     NewEvent[*models.Feedback](pool),
+		OrderChanged:        NewEvent[*models.Order](pool),
+		FeedbackChanged:     NewEvent[*models.Feedback](pool),
+		OrderStatusChanged:  NewEvent[*models.OrderStatusChange](pool),
+		OrderCommentCreated: NewEvent[*models.OrderComment](pool),
+		OrderPaid:           NewEvent[*models.OrderPayment](pool),
 	}
 }
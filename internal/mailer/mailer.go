@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/logger"
+)
+
+const templatesDir = "templates/emails"
+
+type Mailer struct {
+	cfg       *config.Config
+	transport Transport
+	html      map[string]*htmltemplate.Template
+	text      map[string]*texttemplate.Template
+	mu        sync.Mutex
+}
+
+func New(cfg *config.Config, log *logger.Logger) *Mailer {
+	m, err := newMailer(cfg, log)
+	if err != nil {
+		log.Error("Fatal error: failed to create mailer", err)
+		os.Exit(1)
+	}
+	return m
+}
+
+func newMailer(cfg *config.Config, log *logger.Logger) (*Mailer, error) {
+	m := &Mailer{
+		cfg:  cfg,
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+	switch cfg.Mail.Transport {
+	case "smtp":
+		m.transport = &smtpTransport{cfg: cfg.Mail.SMTP}
+	case "file", "":
+		m.transport = &fileTransport{log: log, dir: cfg.Mail.Dir}
+	default:
+		return nil, fmt.Errorf("unknown mail transport: %s", cfg.Mail.Transport)
+	}
+	return m, nil
+}
+
+func (m *Mailer) Enabled() bool {
+	return m.cfg.Mail.Enabled
+}
+
+// Send renders templates/emails/<name>.txt and <name>.html and sends them as one message.
+// The text template must define a "subject" block.
+func (m *Mailer) Send(ctx context.Context, to string, name string, data any) error {
+	if !m.cfg.Mail.Enabled {
+		return nil
+	}
+
+	textTmpl, htmlTmpl, err := m.getTemplates(name)
+	if err != nil {
+		return err
+	}
+
+	subject := &bytes.Buffer{}
+	err = textTmpl.ExecuteTemplate(subject, "subject", data)
+	if err != nil {
+		return fmt.Errorf("failed to render mail subject %s: %w", name, err)
+	}
+	text := &bytes.Buffer{}
+	err = textTmpl.ExecuteTemplate(text, name+".txt", data)
+	if err != nil {
+		return fmt.Errorf("failed to render mail text %s: %w", name, err)
+	}
+	html := &bytes.Buffer{}
+	err = htmlTmpl.ExecuteTemplate(html, name+".html", data)
+	if err != nil {
+		return fmt.Errorf("failed to render mail html %s: %w", name, err)
+	}
+
+	return m.transport.Send(ctx, &Message{
+		From:    m.cfg.Mail.From,
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    html.String(),
+	})
+}
+
+func (m *Mailer) getTemplates(name string) (*texttemplate.Template, *htmltemplate.Template, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	textTmpl, textOK := m.text[name]
+	htmlTmpl, htmlOK := m.html[name]
+	if textOK && htmlOK {
+		return textTmpl, htmlTmpl, nil
+	}
+
+	textTmpl, err := texttemplate.ParseFiles(filepath.Join(templatesDir, name+".txt"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse mail text template: %w", err)
+	}
+	htmlTmpl, err = htmltemplate.ParseFiles(filepath.Join(templatesDir, "layout.html"), filepath.Join(templatesDir, name+".html"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse mail html template: %w", err)
+	}
+
+	if m.cfg.App.IsProduction {
+		m.text[name] = textTmpl
+		m.html[name] = htmlTmpl
+	}
+	return textTmpl, htmlTmpl, nil
+}
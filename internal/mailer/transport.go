@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/logger"
+)
+
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type smtpTransport struct {
+	cfg config.SMTPConfig
+}
+
+var _ Transport = (*smtpTransport)(nil)
+
+func (t *smtpTransport) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if t.cfg.Username != "" {
+		auth = smtp.PlainAuth("", t.cfg.Username, t.cfg.Password, t.cfg.Host)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(net.JoinHostPort(t.cfg.Host, strconv.Itoa(t.cfg.Port)), auth, from.Address, []string{to.Address}, body)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to send mail via smtp: %w", err)
+		}
+		return nil
+	}
+}
+
+// fileTransport writes messages as .eml files instead of sending them, for local development.
+type fileTransport struct {
+	log *logger.Logger
+	dir string
+}
+
+var _ Transport = (*fileTransport)(nil)
+
+func (t *fileTransport) Send(ctx context.Context, msg *Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(t.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	name := filepath.Join(t.dir, time.Now().Format("20060102-150405")+"-"+uuid.NewString()+".eml")
+	err = os.WriteFile(name, body, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	t.log.Infof("Mail %q to %s saved to %s", msg.Subject, msg.To, name)
+	return nil
+}
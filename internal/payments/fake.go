@@ -0,0 +1,71 @@
+package payments
+
+import (
+	"context"
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+)
+
+// fake accepts every payment without calling a gateway. Its confirmation URL
+// points to GET /api/payments/fake/{external_id}, which completes the payment.
+type fake struct {
+	appURL string
+}
+
+var _ Provider = (*fake)(nil)
+
+func newFake(appURL string) *fake {
+	return &fake{appURL: strings.TrimSuffix(appURL, "/")}
+}
+
+type fakeNotification struct {
+	ExternalID  string    `json:"external_id"`
+	PaymentUUID uuid.UUID `json:"payment_uuid"`
+	Status      string    `json:"status"`
+	Amount      int       `json:"amount"`
+	Currency    string    `json:"currency"`
+}
+
+func (p *fake) Name() enums.PaymentProvider {
+	return enums.PaymentProviderFake
+}
+
+func (p *fake) CreatePayment(ctx context.Context, req *CreateRequest) (*Payment, error) {
+	externalID := "fake_" + req.PaymentUUID.String()
+	return &Payment{
+		ExternalID:      externalID,
+		PaymentUUID:     req.PaymentUUID,
+		Status:          enums.PaymentStatusPending,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ConfirmationURL: p.appURL + "/api/payments/fake/" + externalID,
+	}, nil
+}
+
+func (p *fake) ParseWebhook(ctx context.Context, r *http.Request) (*Payment, error) {
+	notification := &fakeNotification{}
+	err := json.UnmarshalRead(r.Body, notification)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+	status, err := enums.NewPaymentStatus(notification.Status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+	return &Payment{
+		ExternalID:  notification.ExternalID,
+		PaymentUUID: notification.PaymentUUID,
+		Status:      status,
+		Amount:      notification.Amount,
+		Currency:    notification.Currency,
+	}, nil
+}
+
+func (p *fake) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+	return &Refund{ExternalID: "fake_refund_" + req.IdempotencyKey, Status: enums.PaymentStatusRefunded}, nil
+}
@@ -0,0 +1,102 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/logger"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+)
+
+var ErrInvalidWebhook = errors.New("invalid payment webhook")
+
+// Provider is an online payment gateway. Amounts are whole rubles.
+type Provider interface {
+	Name() enums.PaymentProvider
+	CreatePayment(ctx context.Context, req *CreateRequest) (*Payment, error)
+	// ParseWebhook returns the current payment state reported by the gateway notification.
+	ParseWebhook(ctx context.Context, r *http.Request) (*Payment, error)
+	Refund(ctx context.Context, req *RefundRequest) (*Refund, error)
+}
+
+type CreateRequest struct {
+	PaymentUUID uuid.UUID
+	Amount      int
+	Currency    string
+	Description string
+	ReturnURL   string
+}
+
+// Payment is the provider's view of a payment. Fractional is set when the provider reported
+// a sum with minor units, which never matches the whole amounts the shop charges.
+type Payment struct {
+	ExternalID      string
+	PaymentUUID     uuid.UUID
+	Status          enums.PaymentStatus
+	Amount          int
+	Fractional      bool
+	Currency        string
+	ConfirmationURL string
+}
+
+type RefundRequest struct {
+	IdempotencyKey string
+	ExternalID     string
+	Amount         int
+	Currency       string
+}
+
+type Refund struct {
+	ExternalID string
+	Status     enums.PaymentStatus
+}
+
+// transitions lists allowed payment status changes. Review holds payments whose confirmed
+// amount differs from the expected one until a manager resolves them, and RefundPending
+// marks a refund requested from the gateway but not confirmed yet.
+var transitions = map[enums.PaymentStatus][]enums.PaymentStatus{
+	enums.PaymentStatusPending:       {enums.PaymentStatusSucceeded, enums.PaymentStatusCanceled, enums.PaymentStatusReview},
+	enums.PaymentStatusSucceeded:     {enums.PaymentStatusRefunded, enums.PaymentStatusRefundPending},
+	enums.PaymentStatusRefundPending: {enums.PaymentStatusRefunded},
+}
+
+// CanTransition reports whether a payment may move from one status to another.
+func CanTransition(from, to enums.PaymentStatus) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// New returns the configured online payment provider or nil when online payments are disabled.
+func New(cfg *config.Config, log *logger.Logger) Provider {
+	p, err := newProvider(cfg)
+	if err != nil {
+		log.Error("Fatal error: failed to create payment provider", err)
+		os.Exit(1)
+	}
+	return p
+}
+
+func newProvider(cfg *config.Config) (Provider, error) {
+	if !cfg.Payments.Online.Enabled {
+		return nil, nil
+	}
+	switch cfg.Payments.Online.Provider {
+	case "yookassa":
+		if cfg.Payments.Online.YooKassa.ShopID == "" || cfg.Payments.Online.YooKassa.SecretKey == "" {
+			return nil, errors.New("yookassa shop id and secret key are required")
+		}
+		return newYooKassa(cfg.Payments.Online.YooKassa), nil
+	case "fake", "":
+		if cfg.App.IsProduction {
+			return nil, errors.New("fake payment provider is not allowed in production")
+		}
+		return newFake(cfg.App.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", cfg.Payments.Online.Provider)
+	}
+}
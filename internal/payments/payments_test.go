@@ -0,0 +1,34 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to enums.PaymentStatus
+		want     bool
+	}{
+		{enums.PaymentStatusPending, enums.PaymentStatusSucceeded, true},
+		{enums.PaymentStatusPending, enums.PaymentStatusCanceled, true},
+		{enums.PaymentStatusPending, enums.PaymentStatusReview, true},
+		{enums.PaymentStatusPending, enums.PaymentStatusRefunded, false},
+		{enums.PaymentStatusSucceeded, enums.PaymentStatusRefundPending, true},
+		{enums.PaymentStatusSucceeded, enums.PaymentStatusRefunded, true},
+		{enums.PaymentStatusSucceeded, enums.PaymentStatusCanceled, false},
+		{enums.PaymentStatusRefundPending, enums.PaymentStatusRefunded, true},
+		{enums.PaymentStatusRefundPending, enums.PaymentStatusSucceeded, false},
+		{enums.PaymentStatusCanceled, enums.PaymentStatusSucceeded, false},
+		{enums.PaymentStatusRefunded, enums.PaymentStatusSucceeded, false},
+		{enums.PaymentStatusReview, enums.PaymentStatusSucceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"->"+tt.to.String(), func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from.String(), tt.to.String(), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+)
+
+const yooKassaDefaultAPIURL = "https://api.yookassa.ru/v3"
+
+type yooKassa struct {
+	cfg    config.YooKassaConfig
+	apiURL string
+	client *http.Client
+}
+
+var _ Provider = (*yooKassa)(nil)
+
+func newYooKassa(cfg config.YooKassaConfig) *yooKassa {
+	apiURL := strings.TrimSuffix(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = yooKassaDefaultAPIURL
+	}
+	return &yooKassa{
+		cfg:    cfg,
+		apiURL: apiURL,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type yooKassaAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type yooKassaPayment struct {
+	ID             string            `json:"id"`
+	Status         string            `json:"status"`
+	Amount         yooKassaAmount    `json:"amount"`
+	RefundedAmount *yooKassaAmount   `json:"refunded_amount,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Confirmation   struct {
+		ConfirmationURL string `json:"confirmation_url"`
+	} `json:"confirmation"`
+}
+
+type yooKassaRefund struct {
+	ID        string `json:"id"`
+	PaymentID string `json:"payment_id"`
+	Status    string `json:"status"`
+}
+
+type yooKassaNotification struct {
+	Type   string `json:"type"`
+	Event  string `json:"event"`
+	Object struct {
+		ID        string `json:"id"`
+		PaymentID string `json:"payment_id"`
+	} `json:"object"`
+}
+
+func (p *yooKassa) Name() enums.PaymentProvider {
+	return enums.PaymentProviderYooKassa
+}
+
+func (p *yooKassa) CreatePayment(ctx context.Context, req *CreateRequest) (*Payment, error) {
+	body := map[string]any{
+		"amount":      formatYooKassaAmount(req.Amount, req.Currency),
+		"capture":     true,
+		"description": req.Description,
+		"confirmation": map[string]string{
+			"type":       "redirect",
+			"return_url": req.ReturnURL,
+		},
+		"metadata": map[string]string{"payment_uuid": req.PaymentUUID.String()},
+	}
+	res := &yooKassaPayment{}
+	err := p.do(ctx, http.MethodPost, "/payments", req.PaymentUUID.String(), body, res)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPayment(res)
+}
+
+// ParseWebhook re-reads the payment from the API because YooKassa notifications are not signed.
+func (p *yooKassa) ParseWebhook(ctx context.Context, r *http.Request) (*Payment, error) {
+	notification := &yooKassaNotification{}
+	err := json.UnmarshalRead(r.Body, notification)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+	if notification.Type != "notification" {
+		return nil, fmt.Errorf("%w: unexpected type %q", ErrInvalidWebhook, notification.Type)
+	}
+
+	paymentID := notification.Object.ID
+	if strings.HasPrefix(notification.Event, "refund.") {
+		paymentID = notification.Object.PaymentID
+	}
+	if paymentID == "" {
+		return nil, fmt.Errorf("%w: missing payment id", ErrInvalidWebhook)
+	}
+
+	res := &yooKassaPayment{}
+	err = p.do(ctx, http.MethodGet, "/payments/"+paymentID, "", nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPayment(res)
+}
+
+func (p *yooKassa) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+	body := map[string]any{
+		"payment_id": req.ExternalID,
+		"amount":     formatYooKassaAmount(req.Amount, req.Currency),
+	}
+	res := &yooKassaRefund{}
+	err := p.do(ctx, http.MethodPost, "/refunds", req.IdempotencyKey, body, res)
+	if err != nil {
+		return nil, err
+	}
+	refund := &Refund{ExternalID: res.ID, Status: enums.PaymentStatusPending}
+	switch res.Status {
+	case "succeeded":
+		refund.Status = enums.PaymentStatusRefunded
+	case "canceled":
+		refund.Status = enums.PaymentStatusCanceled
+	}
+	return refund, nil
+}
+
+func (p *yooKassa) toPayment(res *yooKassaPayment) (*Payment, error) {
+	amount, err := parseYooKassaAmount(res.Amount.Value)
+	fractional := errors.Is(err, errFractionalAmount)
+	if err != nil && !fractional {
+		return nil, err
+	}
+	payment := &Payment{
+		ExternalID:      res.ID,
+		Status:          enums.PaymentStatusPending,
+		Amount:          amount,
+		Fractional:      fractional,
+		Currency:        res.Amount.Currency,
+		ConfirmationURL: res.Confirmation.ConfirmationURL,
+	}
+	if uid, err := uuid.Parse(res.Metadata["payment_uuid"]); err == nil {
+		payment.PaymentUUID = uid
+	}
+	switch res.Status {
+	case "succeeded":
+		payment.Status = enums.PaymentStatusSucceeded
+		if res.RefundedAmount != nil {
+			refunded, err := parseYooKassaAmount(res.RefundedAmount.Value)
+			if err != nil && !errors.Is(err, errFractionalAmount) {
+				return nil, err
+			}
+			if refunded >= amount {
+				payment.Status = enums.PaymentStatusRefunded
+			}
+		}
+	case "canceled":
+		payment.Status = enums.PaymentStatusCanceled
+	}
+	return payment, nil
+}
+
+func (p *yooKassa) do(ctx context.Context, method, path, idempotencyKey string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal yookassa request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create yookassa request: %w", err)
+	}
+	req.SetBasicAuth(p.cfg.ShopID, p.cfg.SecretKey)
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotence-Key", idempotencyKey)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send yookassa request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("yookassa %s %s returned %d: %s", method, path, res.StatusCode, b)
+	}
+	err = json.UnmarshalRead(res.Body, out)
+	if err != nil {
+		return fmt.Errorf("failed to decode yookassa response: %w", err)
+	}
+	return nil
+}
+
+func formatYooKassaAmount(amount int, currency string) yooKassaAmount {
+	return yooKassaAmount{Value: strconv.Itoa(amount) + ".00", Currency: currency}
+}
+
+var errFractionalAmount = errors.New("fractional amounts are not supported")
+
+// parseYooKassaAmount parses a whole amount. Kopecks are reported with errFractionalAmount
+// together with the whole part instead of being dropped silently.
+func parseYooKassaAmount(value string) (int, error) {
+	rubles, kopecks, _ := strings.Cut(value, ".")
+	amount, err := strconv.Atoi(rubles)
+	if err != nil {
+		return 0, fmt.Errorf("invalid yookassa amount %q: %w", value, err)
+	}
+	if strings.Trim(kopecks, "0") != "" {
+		if strings.Trim(kopecks, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid yookassa amount %q", value)
+		}
+		return amount, fmt.Errorf("yookassa amount %q: %w", value, errFractionalAmount)
+	}
+	return amount, nil
+}
@@ -0,0 +1,57 @@
+package payments
+
+import "testing"
+
+func TestParseYooKassaAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"1500.00", 1500, false},
+		{"1500", 1500, false},
+		{"0.00", 0, false},
+		{"1500.0", 1500, false},
+		{"1500.99", 1500, true},
+		{"99.50", 99, true},
+		{"10.x", 0, true},
+		{"10.-5", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseYooKassaAmount(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseYooKassaAmount(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseYooKassaAmount(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatYooKassaAmount(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		want     yooKassaAmount
+	}{
+		{1500, "RUB", yooKassaAmount{Value: "1500.00", Currency: "RUB"}},
+		{0, "RUB", yooKassaAmount{Value: "0.00", Currency: "RUB"}},
+		{42, "USD", yooKassaAmount{Value: "42.00", Currency: "USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.Value, func(t *testing.T) {
+			got := formatYooKassaAmount(tt.amount, tt.currency)
+			if got != tt.want {
+				t.Errorf("formatYooKassaAmount(%d, %q) = %+v, want %+v", tt.amount, tt.currency, got, tt.want)
+			}
+			back, err := parseYooKassaAmount(got.Value)
+			if err != nil || back != tt.amount {
+				t.Errorf("parseYooKassaAmount(%q) = %d, %v, want %d", got.Value, back, err, tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package receipts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const (
+	atolDefaultAPIURL = "https://online.atol.ru/possystem/v5"
+	atolTimeLayout    = "02.01.2006 15:04:05"
+	atolTokenTTL      = 23 * time.Hour
+)
+
+var atolPaymentObjects = map[string]int{
+	PaymentSubjectCommodity: 1,
+	PaymentSubjectService:   4,
+	PaymentSubjectPayment:   10,
+}
+
+// atol is a client for the ATOL Online v5 API. Receipts are fiscalized asynchronously,
+// so Register only returns the document uuid and Check polls the report.
+type atol struct {
+	cfg       config.ReceiptsConfig
+	apiURL    string
+	client    *http.Client
+	token     string
+	expiresAt time.Time
+	mu        sync.Mutex
+}
+
+var _ Operator = (*atol)(nil)
+
+func newATOL(cfg config.ReceiptsConfig) *atol {
+	apiURL := strings.TrimSuffix(cfg.ATOL.APIURL, "/")
+	if apiURL == "" {
+		apiURL = atolDefaultAPIURL
+	}
+	return &atol{
+		cfg:    cfg,
+		apiURL: apiURL,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type atolError struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type atolResponse struct {
+	UUID    string      `json:"uuid"`
+	Status  string      `json:"status"`
+	Error   *atolError  `json:"error"`
+	Token   string      `json:"token"`
+	Payload atolPayload `json:"payload"`
+}
+
+type atolPayload struct {
+	FiscalReceiptNumber     int64  `json:"fiscal_receipt_number"`
+	ShiftNumber             int64  `json:"shift_number"`
+	ReceiptDatetime         string `json:"receipt_datetime"`
+	FNNumber                string `json:"fn_number"`
+	ECRRegistrationNumber   string `json:"ecr_registration_number"`
+	FiscalDocumentNumber    int64  `json:"fiscal_document_number"`
+	FiscalDocumentAttribute int64  `json:"fiscal_document_attribute"`
+}
+
+type atolItem struct {
+	Name          string         `json:"name"`
+	Price         int            `json:"price"`
+	Quantity      int            `json:"quantity"`
+	Measure       int            `json:"measure"`
+	Sum           int            `json:"sum"`
+	PaymentMethod string         `json:"payment_method"`
+	PaymentObject int            `json:"payment_object"`
+	VAT           map[string]any `json:"vat"`
+}
+
+func (o *atol) Name() string {
+	return "atol"
+}
+
+func (o *atol) Register(ctx context.Context, receipt *models.Receipt) (*Result, error) {
+	payload := receipt.Payload
+	items := make([]atolItem, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		items = append(items, atolItem{
+			Name:          item.Name,
+			Price:         item.Price,
+			Quantity:      item.Quantity,
+			Sum:           item.Sum,
+			PaymentMethod: item.PaymentMethod,
+			PaymentObject: atolPaymentObjects[item.PaymentSubject],
+			VAT:           map[string]any{"type": item.VAT},
+		})
+	}
+	paymentType := 1
+	if payload.Advance {
+		paymentType = 2
+	}
+	client := map[string]string{}
+	if payload.Email != "" {
+		client["email"] = payload.Email
+	} else {
+		client["phone"] = formatATOLPhone(payload.Phone)
+	}
+	body := map[string]any{
+		"external_id": receipt.UUID.String(),
+		"timestamp":   payload.CreatedAt.Format(atolTimeLayout),
+		"receipt": map[string]any{
+			"client": client,
+			"company": map[string]string{
+				"email":           o.cfg.Email,
+				"sno":             payload.Taxation,
+				"inn":             o.cfg.INN,
+				"payment_address": o.cfg.PaymentAddress,
+			},
+			"items":    items,
+			"payments": []map[string]int{{"type": paymentType, "sum": payload.Total}},
+			"total":    payload.Total,
+		},
+	}
+
+	operation := receipt.Operation
+	if operation == enums.ReceiptOperationSellSettlement {
+		operation = enums.ReceiptOperationSell
+	}
+	res, err := o.do(ctx, http.MethodPost, "/"+o.cfg.ATOL.GroupCode+"/"+operation.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if res.UUID == "" {
+		return nil, atolFailure(res.Error)
+	}
+	return &Result{ExternalID: res.UUID}, nil
+}
+
+func (o *atol) Check(ctx context.Context, externalID string) (*Result, error) {
+	res, err := o.do(ctx, http.MethodGet, "/"+o.cfg.ATOL.GroupCode+"/report/"+externalID, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch res.Status {
+	case "done":
+		receiptAt, err := time.ParseInLocation(atolTimeLayout, res.Payload.ReceiptDatetime, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid atol receipt datetime %q: %w", res.Payload.ReceiptDatetime, err)
+		}
+		return &Result{
+			ExternalID: externalID,
+			Fiscal: &models.ReceiptFiscal{
+				FiscalDocumentNumber:    res.Payload.FiscalDocumentNumber,
+				FiscalDocumentAttribute: res.Payload.FiscalDocumentAttribute,
+				FiscalReceiptNumber:     res.Payload.FiscalReceiptNumber,
+				ShiftNumber:             res.Payload.ShiftNumber,
+				FNNumber:                res.Payload.FNNumber,
+				RegistrationNumber:      res.Payload.ECRRegistrationNumber,
+				ReceiptAt:               receiptAt,
+			},
+		}, nil
+	case "fail":
+		return nil, atolFailure(res.Error)
+	default:
+		return &Result{ExternalID: externalID}, nil
+	}
+}
+
+func (o *atol) getToken(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != "" && time.Now().Before(o.expiresAt) {
+		return o.token, nil
+	}
+
+	res := &atolResponse{}
+	err := o.send(ctx, http.MethodPost, "/getToken", "", map[string]string{
+		"login": o.cfg.ATOL.Login,
+		"pass":  o.cfg.ATOL.Password,
+	}, res)
+	if err != nil {
+		return "", err
+	}
+	if res.Token == "" {
+		return "", fmt.Errorf("failed to get atol token: %+v", res.Error)
+	}
+	o.token = res.Token
+	o.expiresAt = time.Now().Add(atolTokenTTL)
+	return o.token, nil
+}
+
+func (o *atol) do(ctx context.Context, method, path string, body any) (*atolResponse, error) {
+	token, err := o.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &atolResponse{}
+	err = o.send(ctx, method, path, token, body, res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil && res.Error.Code == 11 {
+		// The token expired earlier than expected, the next attempt will request a new one.
+		o.mu.Lock()
+		o.token = ""
+		o.mu.Unlock()
+		return nil, fmt.Errorf("atol token expired: %s", res.Error.Text)
+	}
+	return res, nil
+}
+
+func (o *atol) send(ctx context.Context, method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal atol request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.apiURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create atol request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Token", token)
+	}
+
+	res, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send atol request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("atol %s %s returned %d: %s", method, path, res.StatusCode, b)
+	}
+	// Validation errors come with 4xx codes and a JSON error body.
+	err = json.UnmarshalRead(res.Body, out)
+	if err != nil {
+		return fmt.Errorf("failed to decode atol response: %w", err)
+	}
+	return nil
+}
+
+// atolFailure treats system errors as temporary and everything else as a rejected receipt.
+func atolFailure(e *atolError) error {
+	if e == nil {
+		return fmt.Errorf("%w: unknown atol error", ErrRejected)
+	}
+	if e.Type == "system" {
+		return fmt.Errorf("atol error %d: %s", e.Code, e.Text)
+	}
+	return fmt.Errorf("%w: atol error %d: %s", ErrRejected, e.Code, e.Text)
+}
+
+func formatATOLPhone(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	if len(digits) == 11 && digits[0] == '8' {
+		digits[0] = '7'
+	}
+	if len(digits) == 10 {
+		digits = append([]byte{'7'}, digits...)
+	}
+	return "+" + string(digits)
+}
@@ -0,0 +1,96 @@
+package receipts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// Compose builds the receipt for a payment. Order items are listed one by one when their
+// catalog prices add up to the payment amount, otherwise the payment is a single line.
+// Partial payments before the order is completed are registered as prepayment.
+func Compose(cfg config.ReceiptsConfig, order *models.Order, payment *models.Payment, email string, now time.Time) models.ReceiptPayload {
+	payload := models.ReceiptPayload{
+		Email:     email,
+		Taxation:  cfg.Taxation,
+		Total:     payment.Amount,
+		CreatedAt: now,
+	}
+	if email == "" {
+		payload.Phone = order.Phone
+	}
+
+	method := PaymentMethodFullPrepayment
+	switch {
+	case order.Status == enums.RequestStatusReviewed || order.Status == enums.RequestStatusAwaitingPayment:
+		method = PaymentMethodFullPayment
+	case order.Amount != nil && payment.Amount < *order.Amount:
+		method = PaymentMethodPrepayment
+	}
+
+	if method == PaymentMethodPrepayment {
+		payload.Items = []models.ReceiptItem{{
+			Name:           fmt.Sprintf("Предоплата по заказу №%d", order.ID),
+			Price:          payment.Amount,
+			Quantity:       1,
+			Sum:            payment.Amount,
+			VAT:            cfg.VAT,
+			PaymentSubject: PaymentSubjectPayment,
+			PaymentMethod:  method,
+		}}
+		return payload
+	}
+
+	payload.Items = orderLines(cfg, order, payment, method)
+	return payload
+}
+
+// ComposeSettlement builds the full_payment receipt issued when a prepaid order is
+// completed. It is paid off by the earlier advance instead of new money.
+func ComposeSettlement(cfg config.ReceiptsConfig, order *models.Order, payment *models.Payment, email string, now time.Time) models.ReceiptPayload {
+	payload := models.ReceiptPayload{
+		Email:     email,
+		Taxation:  cfg.Taxation,
+		Items:     orderLines(cfg, order, payment, PaymentMethodFullPayment),
+		Total:     payment.Amount,
+		Advance:   true,
+		CreatedAt: now,
+	}
+	if email == "" {
+		payload.Phone = order.Phone
+	}
+	return payload
+}
+
+func orderLines(cfg config.ReceiptsConfig, order *models.Order, payment *models.Payment, method string) []models.ReceiptItem {
+	sum := 0
+	items := make([]models.ReceiptItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, models.ReceiptItem{
+			Name:           item.ProductName,
+			Price:          item.PriceFrom,
+			Quantity:       item.Qty,
+			Sum:            item.PriceFrom * item.Qty,
+			VAT:            cfg.VAT,
+			PaymentSubject: cfg.PaymentSubject,
+			PaymentMethod:  method,
+		})
+		sum += item.PriceFrom * item.Qty
+	}
+	if len(items) > 0 && sum == payment.Amount {
+		return items
+	}
+
+	return []models.ReceiptItem{{
+		Name:           fmt.Sprintf("Оплата заказа №%d", order.ID),
+		Price:          payment.Amount,
+		Quantity:       1,
+		Sum:            payment.Amount,
+		VAT:            cfg.VAT,
+		PaymentSubject: cfg.PaymentSubject,
+		PaymentMethod:  method,
+	}}
+}
@@ -0,0 +1,167 @@
+package receipts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+var testConfig = config.ReceiptsConfig{
+	Taxation:       TaxationUSNIncome,
+	VAT:            VATNone,
+	PaymentSubject: PaymentSubjectService,
+}
+
+func TestCompose(t *testing.T) {
+	items := []models.OrderItem{
+		{ProductName: "Фотосессия", PriceFrom: 3000, Qty: 1},
+		{ProductName: "Печать", PriceFrom: 500, Qty: 2},
+	}
+	itemLines := func(method string) []models.ReceiptItem {
+		return []models.ReceiptItem{
+			{Name: "Фотосессия", Price: 3000, Quantity: 1, Sum: 3000, VAT: VATNone, PaymentSubject: PaymentSubjectService, PaymentMethod: method},
+			{Name: "Печать", Price: 500, Quantity: 2, Sum: 1000, VAT: VATNone, PaymentSubject: PaymentSubjectService, PaymentMethod: method},
+		}
+	}
+	singleLine := func(name string, amount int, subject, method string) []models.ReceiptItem {
+		return []models.ReceiptItem{
+			{Name: name, Price: amount, Quantity: 1, Sum: amount, VAT: VATNone, PaymentSubject: subject, PaymentMethod: method},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status enums.RequestStatus
+		amount *int
+		items  []models.OrderItem
+		paid   int
+		want   []models.ReceiptItem
+	}{
+		{
+			name:   "full prepayment with items",
+			status: enums.RequestStatusInProgress,
+			amount: new(4000),
+			items:  items,
+			paid:   4000,
+			want:   itemLines(PaymentMethodFullPrepayment),
+		},
+		{
+			name:   "partial prepayment",
+			status: enums.RequestStatusInProgress,
+			amount: new(4000),
+			items:  items,
+			paid:   1000,
+			want:   singleLine("Предоплата по заказу №7", 1000, PaymentSubjectPayment, PaymentMethodPrepayment),
+		},
+		{
+			name:   "payment of a completed order",
+			status: enums.RequestStatusReviewed,
+			amount: new(4000),
+			items:  items,
+			paid:   4000,
+			want:   itemLines(PaymentMethodFullPayment),
+		},
+		{
+			name:   "balance of an order awaiting payment",
+			status: enums.RequestStatusAwaitingPayment,
+			amount: new(4000),
+			items:  items,
+			paid:   1500,
+			want:   singleLine("Оплата заказа №7", 1500, PaymentSubjectService, PaymentMethodFullPayment),
+		},
+		{
+			name:   "agreed amount differs from catalog prices",
+			status: enums.RequestStatusInProgress,
+			amount: new(4500),
+			items:  items,
+			paid:   4500,
+			want:   singleLine("Оплата заказа №7", 4500, PaymentSubjectService, PaymentMethodFullPrepayment),
+		},
+		{
+			name:   "order without amount or items",
+			status: enums.RequestStatusCreated,
+			paid:   2000,
+			want:   singleLine("Оплата заказа №7", 2000, PaymentSubjectService, PaymentMethodFullPrepayment),
+		},
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: 7, Status: tt.status, Amount: tt.amount, Phone: "+7 (900) 000-00-00", Items: tt.items}
+			payment := &models.Payment{Amount: tt.paid}
+			got := Compose(testConfig, order, payment, "client@example.com", now)
+			if !reflect.DeepEqual(got.Items, tt.want) {
+				t.Errorf("Compose() items = %+v, want %+v", got.Items, tt.want)
+			}
+			if got.Total != tt.paid || got.Advance || got.Taxation != TaxationUSNIncome || !got.CreatedAt.Equal(now) {
+				t.Errorf("Compose() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestComposeContact(t *testing.T) {
+	order := &models.Order{ID: 7, Status: enums.RequestStatusInProgress, Phone: "+7 (900) 000-00-00"}
+	payment := &models.Payment{Amount: 1000}
+	tests := []struct {
+		name      string
+		email     string
+		wantEmail string
+		wantPhone string
+	}{
+		{"email", "client@example.com", "client@example.com", ""},
+		{"phone fallback", "", "", "+7 (900) 000-00-00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, got := range []models.ReceiptPayload{
+				Compose(testConfig, order, payment, tt.email, time.Now()),
+				ComposeSettlement(testConfig, order, payment, tt.email, time.Now()),
+			} {
+				if got.Email != tt.wantEmail || got.Phone != tt.wantPhone {
+					t.Errorf("email, phone = %q, %q, want %q, %q", got.Email, got.Phone, tt.wantEmail, tt.wantPhone)
+				}
+			}
+		})
+	}
+}
+
+func TestComposeSettlement(t *testing.T) {
+	items := []models.OrderItem{{ProductName: "Фотосессия", PriceFrom: 3000, Qty: 1}}
+	tests := []struct {
+		name string
+		paid int
+		want []models.ReceiptItem
+	}{
+		{
+			name: "advance equal to catalog prices",
+			paid: 3000,
+			want: []models.ReceiptItem{
+				{Name: "Фотосессия", Price: 3000, Quantity: 1, Sum: 3000, VAT: VATNone, PaymentSubject: PaymentSubjectService, PaymentMethod: PaymentMethodFullPayment},
+			},
+		},
+		{
+			name: "partial advance",
+			paid: 1000,
+			want: []models.ReceiptItem{
+				{Name: "Оплата заказа №7", Price: 1000, Quantity: 1, Sum: 1000, VAT: VATNone, PaymentSubject: PaymentSubjectService, PaymentMethod: PaymentMethodFullPayment},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: 7, Status: enums.RequestStatusReviewed, Amount: new(3000), Items: items}
+			got := ComposeSettlement(testConfig, order, &models.Payment{Amount: tt.paid}, "client@example.com", time.Now())
+			if !reflect.DeepEqual(got.Items, tt.want) {
+				t.Errorf("ComposeSettlement() items = %+v, want %+v", got.Items, tt.want)
+			}
+			if !got.Advance || got.Total != tt.paid {
+				t.Errorf("ComposeSettlement() advance, total = %v, %d, want true, %d", got.Advance, got.Total, tt.paid)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package receipts
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// fake fiscalizes every receipt instantly with made up requisites.
+type fake struct {
+	documents atomic.Int64
+}
+
+var _ Operator = (*fake)(nil)
+
+func newFake() *fake {
+	return &fake{}
+}
+
+func (o *fake) Name() string {
+	return "fake"
+}
+
+func (o *fake) Register(ctx context.Context, receipt *models.Receipt) (*Result, error) {
+	return o.result("fake_" + receipt.UUID.String()), nil
+}
+
+func (o *fake) Check(ctx context.Context, externalID string) (*Result, error) {
+	return o.result(externalID), nil
+}
+
+func (o *fake) result(externalID string) *Result {
+	n := o.documents.Add(1)
+	return &Result{
+		ExternalID: externalID,
+		Fiscal: &models.ReceiptFiscal{
+			FiscalDocumentNumber:    n,
+			FiscalDocumentAttribute: 1000000000 + n,
+			FiscalReceiptNumber:     n,
+			ShiftNumber:             1,
+			FNNumber:                "9999078900000000",
+			RegistrationNumber:      "0000000000000000",
+			ReceiptAt:               time.Now(),
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package receipts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/zagvozdeen/ola/internal/config"
+	"github.com/zagvozdeen/ola/internal/logger"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// ErrRejected marks receipts the operator will never accept, so they must not be retried.
+var ErrRejected = errors.New("receipt rejected by fiscal operator")
+
+// Taxation systems (tag 1055).
+const (
+	TaxationOSN              = "osn"
+	TaxationUSNIncome        = "usn_income"
+	TaxationUSNIncomeOutcome = "usn_income_outcome"
+	TaxationESN              = "esn"
+	TaxationPatent           = "patent"
+)
+
+// VAT rates (tag 1199).
+const (
+	VATNone = "none"
+	VAT0    = "vat0"
+	VAT5    = "vat5"
+	VAT7    = "vat7"
+	VAT10   = "vat10"
+	VAT20   = "vat20"
+	VAT22   = "vat22"
+)
+
+// Payment subjects (tag 1212).
+const (
+	PaymentSubjectCommodity = "commodity"
+	PaymentSubjectService   = "service"
+	PaymentSubjectPayment   = "payment"
+)
+
+// Payment methods (tag 1214).
+const (
+	PaymentMethodFullPrepayment = "full_prepayment"
+	PaymentMethodPrepayment     = "prepayment"
+	PaymentMethodFullPayment    = "full_payment"
+)
+
+var (
+	taxations       = []string{TaxationOSN, TaxationUSNIncome, TaxationUSNIncomeOutcome, TaxationESN, TaxationPatent}
+	vats            = []string{VATNone, VAT0, VAT5, VAT7, VAT10, VAT20, VAT22}
+	paymentSubjects = []string{PaymentSubjectCommodity, PaymentSubjectService, PaymentSubjectPayment}
+)
+
+// Operator is a fiscal data operator that registers receipts on a cloud cash register.
+type Operator interface {
+	Name() string
+	// Register sends the receipt. Operators that fiscalize asynchronously return a result without Fiscal.
+	Register(ctx context.Context, receipt *models.Receipt) (*Result, error)
+	// Check returns the state of a receipt accepted by Register.
+	Check(ctx context.Context, externalID string) (*Result, error)
+}
+
+type Result struct {
+	ExternalID string
+	Fiscal     *models.ReceiptFiscal
+}
+
+// New returns the configured fiscal operator or nil when receipts are disabled.
+func New(cfg *config.Config, log *logger.Logger) Operator {
+	o, err := newOperator(cfg)
+	if err != nil {
+		log.Error("Fatal error: failed to create fiscal operator", err)
+		os.Exit(1)
+	}
+	return o
+}
+
+func newOperator(cfg *config.Config) (Operator, error) {
+	if !cfg.Receipts.Enabled {
+		return nil, nil
+	}
+	if !slices.Contains(taxations, cfg.Receipts.Taxation) {
+		return nil, fmt.Errorf("unknown receipt taxation: %s", cfg.Receipts.Taxation)
+	}
+	if !slices.Contains(vats, cfg.Receipts.VAT) {
+		return nil, fmt.Errorf("unknown receipt vat: %s", cfg.Receipts.VAT)
+	}
+	if !slices.Contains(paymentSubjects, cfg.Receipts.PaymentSubject) {
+		return nil, fmt.Errorf("unknown receipt payment subject: %s", cfg.Receipts.PaymentSubject)
+	}
+	switch cfg.Receipts.Operator {
+	case "atol":
+		if cfg.Receipts.ATOL.Login == "" || cfg.Receipts.ATOL.Password == "" || cfg.Receipts.ATOL.GroupCode == "" {
+			return nil, errors.New("atol login, password and group code are required")
+		}
+		if cfg.Receipts.INN == "" || cfg.Receipts.PaymentAddress == "" {
+			return nil, errors.New("receipt inn and payment address are required")
+		}
+		return newATOL(cfg.Receipts), nil
+	case "fake", "":
+		if cfg.App.IsProduction {
+			return nil, errors.New("fake fiscal operator is not allowed in production")
+		}
+		return newFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown fiscal operator: %s", cfg.Receipts.Operator)
+	}
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/logger"
+)
+
+type Job func(ctx context.Context) error
+
+type job struct {
+	name string
+	next func(now time.Time) time.Time
+	fn   Job
+}
+
+type Scheduler struct {
+	log  *logger.Logger
+	jobs []job
+	mu   sync.Mutex
+}
+
+func New(log *logger.Logger) *Scheduler {
+	return &Scheduler{log: log}
+}
+
+// Every runs fn with a fixed interval between runs.
+func (s *Scheduler) Every(name string, interval time.Duration, fn Job) {
+	s.add(name, func(now time.Time) time.Time {
+		return now.Add(interval)
+	}, fn)
+}
+
+// Daily runs fn every day at the given time of day, e.g. 9h30m for 09:30.
+func (s *Scheduler) Daily(name string, at time.Duration, fn Job) {
+	s.add(name, daily(at), fn)
+}
+
+// Weekly runs fn every week on the given weekday at the given time of day.
+func (s *Scheduler) Weekly(name string, day time.Weekday, at time.Duration, fn Job) {
+	s.add(name, weekly(day, at), fn)
+}
+
+func daily(at time.Duration) func(time.Time) time.Time {
+	return func(now time.Time) time.Time {
+		next := startOfDay(now).Add(at)
+		if !next.After(now) {
+			next = startOfDay(now.AddDate(0, 0, 1)).Add(at)
+		}
+		return next
+	}
+}
+
+func weekly(day time.Weekday, at time.Duration) func(time.Time) time.Time {
+	return func(now time.Time) time.Time {
+		days := (int(day) - int(now.Weekday()) + 7) % 7
+		next := startOfDay(now.AddDate(0, 0, days)).Add(at)
+		if !next.After(now) {
+			next = startOfDay(now.AddDate(0, 0, days+7)).Add(at)
+		}
+		return next
+	}
+}
+
+func (s *Scheduler) add(name string, next func(time.Time) time.Time, fn Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job{name: name, next: next, fn: fn})
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := make([]job, len(s.jobs))
+	copy(jobs, s.jobs)
+	s.mu.Unlock()
+
+	wg := &sync.WaitGroup{}
+	for _, j := range jobs {
+		wg.Go(func() {
+			s.loop(ctx, j)
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	for {
+		timer := time.NewTimer(time.Until(j.next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runJob(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("Scheduled job panicked", fmt.Errorf("%v", r), slog.String("job", j.name))
+		}
+	}()
+	if err := j.fn(ctx); err != nil {
+		s.log.Error("Scheduled job error", err, slog.String("job", j.name))
+	}
+}
+
+// ParseTimeOfDay parses "15:04" into an offset from midnight.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses an english weekday name, e.g. "monday".
+func ParseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), s) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaily(t *testing.T) {
+	at := 9*time.Hour + 30*time.Minute
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before today's run", date(2026, 3, 10, 8, 0), date(2026, 3, 10, 9, 30)},
+		{"at today's run", date(2026, 3, 10, 9, 30), date(2026, 3, 11, 9, 30)},
+		{"after today's run", date(2026, 3, 10, 23, 59), date(2026, 3, 11, 9, 30)},
+		{"end of month", date(2026, 3, 31, 12, 0), date(2026, 4, 1, 9, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := daily(at)(tt.now)
+			if !got.Equal(tt.want) {
+				t.Errorf("daily(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	at := 10 * time.Hour
+	// 2026-03-09 is a Monday.
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"earlier the same day", date(2026, 3, 9, 9, 0), date(2026, 3, 9, 10, 0)},
+		{"later the same day", date(2026, 3, 9, 10, 0), date(2026, 3, 16, 10, 0)},
+		{"later in the week", date(2026, 3, 11, 8, 0), date(2026, 3, 16, 10, 0)},
+		{"day before", date(2026, 3, 8, 23, 0), date(2026, 3, 9, 10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := weekly(time.Monday, at)(tt.now)
+			if !got.Equal(tt.want) {
+				t.Errorf("weekly(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"00:00", 0, false},
+		{"09:30", 9*time.Hour + 30*time.Minute, false},
+		{"23:59", 23*time.Hour + 59*time.Minute, false},
+		{"24:00", 0, true},
+		{"9am", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimeOfDay(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeOfDay(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimeOfDay(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
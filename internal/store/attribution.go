@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// GetAttributionReport aggregates landing orders and feedback created in [from, to) by
+// UTM source, medium and campaign. Requests without attribution are grouped under empty values.
+func (s *Store) GetAttributionReport(ctx context.Context, from, to time.Time) ([]models.AttributionReportRow, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`WITH leads AS (
+			SELECT attribution, 1 AS orders, 0 AS feedback, COALESCE(amount, 0) AS revenue, paid_amount AS paid
+			FROM orders
+			WHERE source = $1 AND created_at >= $2 AND created_at < $3
+			UNION ALL
+			SELECT attribution, 0, 1, 0, 0
+			FROM feedback
+			WHERE source = $1 AND created_at >= $2 AND created_at < $3
+		)
+		SELECT COALESCE(attribution->>'utm_source', ''),
+			COALESCE(attribution->>'utm_medium', ''),
+			COALESCE(attribution->>'utm_campaign', ''),
+			SUM(orders), SUM(feedback), SUM(revenue), SUM(paid)
+		FROM leads
+		GROUP BY 1, 2, 3
+		ORDER BY 4 DESC, 5 DESC, 1, 2, 3`,
+		enums.OrderSourceLanding, from, to,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	report := make([]models.AttributionReportRow, 0)
+	for rows.Next() {
+		row := models.AttributionReportRow{}
+		err = rows.Scan(
+			&row.Source,
+			&row.Medium,
+			&row.Campaign,
+			&row.Orders,
+			&row.Feedback,
+			&row.Revenue,
+			&row.Paid,
+		)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		report = append(report, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return report, nil
+}
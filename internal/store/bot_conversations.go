@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func (s *Store) GetBotConversation(ctx context.Context, userID int) (*models.BotConversation, error) {
+	conversation := &models.BotConversation{}
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT user_id, state, data, updated_at FROM bot_conversations WHERE user_id = $1",
+		userID,
+	).Scan(&conversation.UserID, &conversation.State, &conversation.Data, &conversation.UpdatedAt)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return conversation, nil
+}
+
+func (s *Store) SaveBotConversation(ctx context.Context, conversation *models.BotConversation) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`INSERT INTO bot_conversations (user_id, state, data, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET state = EXCLUDED.state, data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`,
+		conversation.UserID, conversation.State, conversation.Data, conversation.UpdatedAt,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) DeleteBotConversation(ctx context.Context, userID int) error {
+	_, err := s.querier(ctx).Exec(ctx, "DELETE FROM bot_conversations WHERE user_id = $1", userID)
+	return wrapDBError(err)
+}
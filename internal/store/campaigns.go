@@ -0,0 +1,283 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const campaignColumns = "id, uuid, name, text, image, image_file_id, button_text, segment_id, status, scheduled_at, started_at, finished_at, created_by, created_at, updated_at"
+
+func scanCampaign(row pgx.Row, campaign *models.Campaign) error {
+	return row.Scan(
+		&campaign.ID,
+		&campaign.UUID,
+		&campaign.Name,
+		&campaign.Text,
+		&campaign.Image,
+		&campaign.ImageFileID,
+		&campaign.ButtonText,
+		&campaign.SegmentID,
+		&campaign.Status,
+		&campaign.ScheduledAt,
+		&campaign.StartedAt,
+		&campaign.FinishedAt,
+		&campaign.CreatedBy,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt,
+	)
+}
+
+const campaignRecipientColumns = "id, campaign_id, user_id, chat_id, status, message_id, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at"
+
+func scanCampaignRecipient(row pgx.Row, recipient *models.CampaignRecipient) error {
+	return row.Scan(
+		&recipient.ID,
+		&recipient.CampaignID,
+		&recipient.UserID,
+		&recipient.ChatID,
+		&recipient.Status,
+		&recipient.MessageID,
+		&recipient.Attempts,
+		&recipient.LastError,
+		&recipient.NextAttemptAt,
+		&recipient.SentAt,
+		&recipient.CreatedAt,
+		&recipient.UpdatedAt,
+	)
+}
+
+func (s *Store) GetAllCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	return s.queryCampaigns(ctx, "SELECT "+campaignColumns+" FROM campaigns ORDER BY created_at DESC")
+}
+
+func (s *Store) GetCampaignByUUID(ctx context.Context, campaignUUID uuid.UUID) (*models.Campaign, error) {
+	campaign := &models.Campaign{}
+	err := scanCampaign(s.querier(ctx).QueryRow(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE uuid = $1", campaignUUID), campaign)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return campaign, nil
+}
+
+func (s *Store) GetCampaignByID(ctx context.Context, id int) (*models.Campaign, error) {
+	campaign := &models.Campaign{}
+	err := scanCampaign(s.querier(ctx).QueryRow(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = $1", id), campaign)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return campaign, nil
+}
+
+// GetDueCampaigns locks scheduled campaigns whose time has come, so only one worker starts them.
+func (s *Store) GetDueCampaigns(ctx context.Context, now time.Time) ([]models.Campaign, error) {
+	return s.queryCampaigns(
+		ctx,
+		"SELECT "+campaignColumns+" FROM campaigns WHERE status = $1 AND scheduled_at <= $2 ORDER BY scheduled_at FOR UPDATE SKIP LOCKED",
+		enums.CampaignStatusScheduled, now,
+	)
+}
+
+func (s *Store) queryCampaigns(ctx context.Context, query string, args ...any) ([]models.Campaign, error) {
+	rows, err := s.querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	campaigns := make([]models.Campaign, 0)
+	for rows.Next() {
+		campaign := models.Campaign{}
+		err = scanCampaign(rows, &campaign)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return campaigns, nil
+}
+
+func (s *Store) CreateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		`INSERT INTO campaigns (uuid, name, text, image, button_text, segment_id, status, scheduled_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		campaign.UUID, campaign.Name, campaign.Text, campaign.Image, campaign.ButtonText, campaign.SegmentID, campaign.Status, campaign.ScheduledAt, campaign.CreatedBy, campaign.CreatedAt, campaign.UpdatedAt,
+	).Scan(&campaign.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE campaigns SET name = $1, text = $2, image = $3, image_file_id = $4, button_text = $5, segment_id = $6, status = $7,
+		scheduled_at = $8, started_at = $9, finished_at = $10, updated_at = $11 WHERE id = $12`,
+		campaign.Name, campaign.Text, campaign.Image, campaign.ImageFileID, campaign.ButtonText, campaign.SegmentID, campaign.Status,
+		campaign.ScheduledAt, campaign.StartedAt, campaign.FinishedAt, campaign.UpdatedAt, campaign.ID,
+	)
+	return wrapDBError(err)
+}
+
+// UpdateCampaignContent changes the message and segment of a draft or scheduled campaign. It
+// reports false when the campaign has started meanwhile, leaving the status columns untouched.
+func (s *Store) UpdateCampaignContent(ctx context.Context, campaign *models.Campaign) (bool, error) {
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE campaigns SET name = $1, text = $2, image = $3, image_file_id = $4, button_text = $5, segment_id = $6, updated_at = $7
+		WHERE id = $8 AND status IN ($9, $10)`,
+		campaign.Name, campaign.Text, campaign.Image, campaign.ImageFileID, campaign.ButtonText, campaign.SegmentID, campaign.UpdatedAt, campaign.ID,
+		enums.CampaignStatusDraft, enums.CampaignStatusScheduled,
+	)
+	if err != nil {
+		return false, wrapDBError(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CancelCampaign cancels a scheduled or sending campaign. It reports false when the campaign
+// is in another status, e.g. it has just been finished.
+func (s *Store) CancelCampaign(ctx context.Context, campaign *models.Campaign) (bool, error) {
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE campaigns SET status = $1, finished_at = $2, updated_at = $3 WHERE id = $4 AND status IN ($5, $6)",
+		enums.CampaignStatusCancelled, campaign.FinishedAt, campaign.UpdatedAt, campaign.ID,
+		enums.CampaignStatusScheduled, enums.CampaignStatusSending,
+	)
+	if err != nil {
+		return false, wrapDBError(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpdateCampaignImageFileID caches the Telegram file id of the uploaded image, so the
+// image is uploaded once per campaign instead of once per recipient.
+func (s *Store) UpdateCampaignImageFileID(ctx context.Context, campaign *models.Campaign) error {
+	_, err := s.querier(ctx).Exec(ctx, "UPDATE campaigns SET image_file_id = $1 WHERE id = $2", campaign.ImageFileID, campaign.ID)
+	return wrapDBError(err)
+}
+
+// FinishCampaigns marks sending campaigns without pending recipients as done.
+func (s *Store) FinishCampaigns(ctx context.Context, now time.Time) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE campaigns SET status = $1, finished_at = $2, updated_at = $2
+		WHERE status = $3 AND NOT EXISTS (
+			SELECT 1 FROM campaign_recipients r WHERE r.campaign_id = campaigns.id AND r.status = $4
+		)`,
+		enums.CampaignStatusDone, now, enums.CampaignStatusSending, enums.CampaignRecipientStatusPending,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) GetCampaignStatsByCampaignIDs(ctx context.Context, campaignIDs []int) (map[int]models.CampaignStats, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		"SELECT campaign_id, status, COUNT(*) FROM campaign_recipients WHERE campaign_id = ANY($1) GROUP BY campaign_id, status",
+		campaignIDs,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	statsByCampaignID := make(map[int]models.CampaignStats, len(campaignIDs))
+	for rows.Next() {
+		var (
+			campaignID int
+			status     enums.CampaignRecipientStatus
+			count      int
+		)
+		err = rows.Scan(&campaignID, &status, &count)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		stats := statsByCampaignID[campaignID]
+		stats.Total += count
+		switch status {
+		case enums.CampaignRecipientStatusPending:
+			stats.Pending += count
+		case enums.CampaignRecipientStatusSent:
+			stats.Sent += count
+		case enums.CampaignRecipientStatusBlocked:
+			stats.Blocked += count
+		case enums.CampaignRecipientStatusFailed:
+			stats.Failed += count
+		}
+		statsByCampaignID[campaignID] = stats
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return statsByCampaignID, nil
+}
+
+// CreateCampaignRecipients queues the users that have a Telegram chat and did not block
+// the bot. It returns the number of queued recipients.
+func (s *Store) CreateCampaignRecipients(ctx context.Context, campaignID int, userIDs []int, now time.Time) (int, error) {
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		`INSERT INTO campaign_recipients (campaign_id, user_id, chat_id, status, next_attempt_at, created_at, updated_at)
+		SELECT $1, id, tid, $2, $3, $3, $3 FROM users
+		WHERE id = ANY($4) AND tid IS NOT NULL AND bot_blocked_at IS NULL
+		ON CONFLICT (campaign_id, user_id) DO NOTHING`,
+		campaignID, enums.CampaignRecipientStatusPending, now, userIDs,
+	)
+	if err != nil {
+		return 0, wrapDBError(err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimDueCampaignRecipients returns pending recipients of sending campaigns and postpones
+// them until leaseUntil, so concurrent workers do not message the same user twice.
+func (s *Store) ClaimDueCampaignRecipients(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.CampaignRecipient, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`UPDATE campaign_recipients SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT r.id FROM campaign_recipients r
+			JOIN campaigns c ON c.id = r.campaign_id
+			WHERE r.status = $3 AND r.next_attempt_at <= $1 AND c.status = $4
+			ORDER BY r.next_attempt_at, r.id
+			LIMIT $5
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		RETURNING `+campaignRecipientColumns,
+		now, leaseUntil, enums.CampaignRecipientStatusPending, enums.CampaignStatusSending, limit,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	recipients := make([]models.CampaignRecipient, 0)
+	for rows.Next() {
+		recipient := models.CampaignRecipient{}
+		err = scanCampaignRecipient(rows, &recipient)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return recipients, nil
+}
+
+func (s *Store) UpdateCampaignRecipient(ctx context.Context, recipient *models.CampaignRecipient) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE campaign_recipients SET status = $1, message_id = $2, attempts = $3, last_error = $4, next_attempt_at = $5, sent_at = $6, updated_at = $7 WHERE id = $8",
+		recipient.Status, recipient.MessageID, recipient.Attempts, recipient.LastError, recipient.NextAttemptAt, recipient.SentAt, recipient.UpdatedAt, recipient.ID,
+	)
+	return wrapDBError(err)
+}
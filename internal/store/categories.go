@@ -171,3 +171,34 @@ func (s *Store) ReplaceProductCategories(ctx context.Context, productID int, cat
 
 	return nil
 }
+
+func (s *Store) GetCategorySlugsByOrderID(ctx context.Context, orderID int) ([]string, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT DISTINCT c.slug
+		FROM order_items oi
+		JOIN category_product cp ON cp.product_id = oi.product_id
+		JOIN categories c ON c.id = cp.category_id
+		WHERE oi.order_id = $1`,
+		orderID,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	slugs := make([]string, 0)
+	for rows.Next() {
+		var slug string
+		err = rows.Scan(&slug)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return slugs, nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const customerNoteColumns = "id, uuid, customer_id, user_id, content, created_at, updated_at"
+
+func scanCustomerNote(row pgx.Row, note *models.CustomerNote) error {
+	return row.Scan(
+		&note.ID,
+		&note.UUID,
+		&note.CustomerID,
+		&note.UserID,
+		&note.Content,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+	)
+}
+
+func (s *Store) GetCustomerNotesByCustomerID(ctx context.Context, customerID int) ([]models.CustomerNote, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+customerNoteColumns+" FROM customer_notes WHERE customer_id = $1 ORDER BY created_at DESC, id DESC", customerID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	notes := make([]models.CustomerNote, 0)
+	for rows.Next() {
+		note := models.CustomerNote{}
+		err = scanCustomerNote(rows, &note)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		notes = append(notes, note)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return notes, nil
+}
+
+func (s *Store) GetCustomerNoteByUUID(ctx context.Context, noteUUID uuid.UUID) (*models.CustomerNote, error) {
+	note := &models.CustomerNote{}
+	err := scanCustomerNote(s.querier(ctx).QueryRow(ctx, "SELECT "+customerNoteColumns+" FROM customer_notes WHERE uuid = $1", noteUUID), note)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return note, nil
+}
+
+func (s *Store) CreateCustomerNote(ctx context.Context, note *models.CustomerNote) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO customer_notes (uuid, customer_id, user_id, content, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		note.UUID, note.CustomerID, note.UserID, note.Content, note.CreatedAt, note.UpdatedAt,
+	).Scan(&note.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) DeleteCustomerNote(ctx context.Context, note *models.CustomerNote) error {
+	_, err := s.querier(ctx).Exec(ctx, "DELETE FROM customer_notes WHERE id = $1", note.ID)
+	return wrapDBError(err)
+}
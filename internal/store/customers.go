@@ -0,0 +1,329 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const customerColumns = `c.id, c.uuid, c.name, c.phone, c.tags, c.user_id, c.created_at, c.updated_at,
+	ARRAY(SELECT cp.phone FROM customer_phones cp WHERE cp.customer_id = c.id ORDER BY cp.created_at, cp.phone),
+	(SELECT COUNT(*) FROM orders o WHERE o.customer_id = c.id),
+	(SELECT COALESCE(SUM(o.amount), 0) FROM orders o WHERE o.customer_id = c.id),
+	(SELECT COALESCE(SUM(o.paid_amount), 0) FROM orders o WHERE o.customer_id = c.id),
+	(SELECT COUNT(*) FROM feedback f WHERE f.customer_id = c.id),
+	(SELECT MAX(o.created_at) FROM orders o WHERE o.customer_id = c.id)`
+
+func scanCustomer(row pgx.Row, customer *models.Customer) error {
+	return row.Scan(
+		&customer.ID,
+		&customer.UUID,
+		&customer.Name,
+		&customer.Phone,
+		&customer.Tags,
+		&customer.UserID,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+		&customer.Phones,
+		&customer.OrdersCount,
+		&customer.OrdersAmount,
+		&customer.PaidAmount,
+		&customer.FeedbackCount,
+		&customer.LastOrderAt,
+	)
+}
+
+// GetCustomers returns customers by last activity. A non-empty phone or tag limits the result
+// to the customer owning that normalized phone or to customers with the tag.
+func (s *Store) GetCustomers(ctx context.Context, phone, tag string) ([]models.Customer, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if phone != "" {
+		args = append(args, phone)
+		conditions = append(conditions, fmt.Sprintf("c.id IN (SELECT customer_id FROM customer_phones WHERE phone = $%d)", len(args)))
+	}
+	if tag != "" {
+		args = append(args, tag)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(c.tags)", len(args)))
+	}
+	query := "SELECT " + customerColumns + " FROM customers c"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY c.updated_at DESC, c.id DESC"
+
+	return s.queryCustomers(ctx, query, args...)
+}
+
+// GetSegmentCustomers returns customers matching the segment filter by last activity.
+func (s *Store) GetSegmentCustomers(ctx context.Context, filter models.SegmentFilter, now time.Time) ([]models.Customer, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if len(filter.Tags) > 0 {
+		args = append(args, filter.Tags)
+		conditions = append(conditions, fmt.Sprintf("c.tags && $%d", len(args)))
+	}
+	if len(filter.ExcludeTags) > 0 {
+		args = append(args, filter.ExcludeTags)
+		conditions = append(conditions, fmt.Sprintf("NOT c.tags && $%d", len(args)))
+	}
+	if len(filter.Sources) > 0 || filter.OrderedWithinDays != nil {
+		orderConditions := []string{"o.customer_id = c.id"}
+		if len(filter.Sources) > 0 {
+			args = append(args, filter.Sources)
+			orderConditions = append(orderConditions, fmt.Sprintf("o.source::text = ANY($%d)", len(args)))
+		}
+		if filter.OrderedWithinDays != nil {
+			args = append(args, now.AddDate(0, 0, -*filter.OrderedWithinDays))
+			orderConditions = append(orderConditions, fmt.Sprintf("o.created_at >= $%d", len(args)))
+		}
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM orders o WHERE "+strings.Join(orderConditions, " AND ")+")")
+	}
+	if filter.MinOrders != nil {
+		args = append(args, *filter.MinOrders)
+		conditions = append(conditions, fmt.Sprintf("(SELECT COUNT(*) FROM orders o WHERE o.customer_id = c.id) >= $%d", len(args)))
+	}
+	if filter.MinOrdersAmount != nil {
+		args = append(args, *filter.MinOrdersAmount)
+		conditions = append(conditions, fmt.Sprintf("(SELECT COALESCE(SUM(o.amount), 0) FROM orders o WHERE o.customer_id = c.id) >= $%d", len(args)))
+	}
+	if filter.HasTelegram {
+		conditions = append(conditions, "c.user_id IN (SELECT id FROM users WHERE tid IS NOT NULL)")
+	}
+	query := "SELECT " + customerColumns + " FROM customers c"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY c.updated_at DESC, c.id DESC"
+
+	return s.queryCustomers(ctx, query, args...)
+}
+
+func (s *Store) queryCustomers(ctx context.Context, query string, args ...any) ([]models.Customer, error) {
+	rows, err := s.querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		customer := models.Customer{}
+		err = scanCustomer(rows, &customer)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		customers = append(customers, customer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return customers, nil
+}
+
+func (s *Store) GetCustomerByUUID(ctx context.Context, customerUUID uuid.UUID) (*models.Customer, error) {
+	customer := &models.Customer{}
+	err := scanCustomer(s.querier(ctx).QueryRow(ctx, "SELECT "+customerColumns+" FROM customers c WHERE c.uuid = $1", customerUUID), customer)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return customer, nil
+}
+
+func (s *Store) getCustomerByPhone(ctx context.Context, phone string) (*models.Customer, error) {
+	customer := &models.Customer{}
+	err := scanCustomer(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+customerColumns+" FROM customers c JOIN customer_phones p ON p.customer_id = c.id WHERE p.phone = $1",
+		phone,
+	), customer)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return customer, nil
+}
+
+// GetOrCreateCustomerByPhone returns the customer owning the normalized phone, creating one
+// when the phone is new. The user is linked to the customer unless it already has one,
+// and updated_at is bumped so customers are listed by last activity.
+func (s *Store) GetOrCreateCustomerByPhone(ctx context.Context, phone, name string, userID *int) (*models.Customer, error) {
+	now := time.Now()
+	customer, err := s.getCustomerByPhone(ctx, phone)
+	if err == nil {
+		if customer.UserID == nil {
+			customer.UserID = userID
+		}
+		customer.UpdatedAt = now
+		_, err = s.querier(ctx).Exec(ctx, "UPDATE customers SET user_id = $1, updated_at = $2 WHERE id = $3", customer.UserID, customer.UpdatedAt, customer.ID)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		return customer, nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	uid, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	customer = &models.Customer{
+		UUID:      uid,
+		Name:      name,
+		Phone:     phone,
+		Phones:    []string{phone},
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO customers (uuid, name, phone, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		customer.UUID, customer.Name, customer.Phone, customer.UserID, customer.CreatedAt, customer.UpdatedAt,
+	).Scan(&customer.ID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		"INSERT INTO customer_phones (phone, customer_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		phone, customer.ID, now,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		// The phone was taken by a concurrent request, use its customer instead.
+		_, err = s.querier(ctx).Exec(ctx, "DELETE FROM customers WHERE id = $1", customer.ID)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		return s.getCustomerByPhone(ctx, phone)
+	}
+	return customer, nil
+}
+
+func (s *Store) UpdateCustomerTags(ctx context.Context, customer *models.Customer) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE customers SET tags = $1, updated_at = $2 WHERE id = $3",
+		customer.Tags, customer.UpdatedAt, customer.ID,
+	)
+	return wrapDBError(err)
+}
+
+// GetCustomerTags returns every tag in use with the number of customers having it.
+func (s *Store) GetCustomerTags(ctx context.Context) ([]models.CustomerTag, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT tag, COUNT(*) FROM customers, unnest(tags) AS tag GROUP BY tag ORDER BY 2 DESC, tag")
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.CustomerTag, 0)
+	for rows.Next() {
+		tag := models.CustomerTag{}
+		err = rows.Scan(&tag.Tag, &tag.Customers)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		tags = append(tags, tag)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return tags, nil
+}
+
+func (s *Store) UpdateOrderCustomer(ctx context.Context, order *models.Order) error {
+	_, err := s.querier(ctx).Exec(ctx, "UPDATE orders SET customer_id = $1 WHERE id = $2", order.CustomerID, order.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) GetOrdersByCustomerID(ctx context.Context, customerID int) ([]models.Order, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+orderColumns+" FROM orders WHERE customer_id = $1 ORDER BY created_at DESC", customerID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0)
+	for rows.Next() {
+		order := models.Order{}
+		err = scanOrder(rows, &order)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return orders, nil
+}
+
+func (s *Store) GetFeedbackByCustomerID(ctx context.Context, customerID int) ([]models.Feedback, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+feedbackColumns+" FROM feedback WHERE customer_id = $1 ORDER BY created_at DESC", customerID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	feedbacks := make([]models.Feedback, 0)
+	for rows.Next() {
+		var feedback models.Feedback
+		err = scanFeedback(rows, &feedback)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return feedbacks, nil
+}
+
+// MergeCustomers moves orders, feedback, phones and notes of the source customers to the
+// target and deletes the sources.
+func (s *Store) MergeCustomers(ctx context.Context, target *models.Customer, sourceIDs []int) error {
+	placeholders := make([]string, 0, len(sourceIDs))
+	args := []any{target.ID}
+	for _, id := range sourceIDs {
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	in := strings.Join(placeholders, ", ")
+
+	for _, query := range []string{
+		"UPDATE orders SET customer_id = $1 WHERE customer_id IN (" + in + ")",
+		"UPDATE feedback SET customer_id = $1 WHERE customer_id IN (" + in + ")",
+		"UPDATE customer_phones SET customer_id = $1 WHERE customer_id IN (" + in + ")",
+		"UPDATE customer_notes SET customer_id = $1 WHERE customer_id IN (" + in + ")",
+		"DELETE FROM customers WHERE id <> $1 AND id IN (" + in + ")",
+	} {
+		_, err := s.querier(ctx).Exec(ctx, query, args...)
+		if err != nil {
+			return wrapDBError(err)
+		}
+	}
+
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE customers SET name = $1, user_id = $2, tags = $3, updated_at = $4 WHERE id = $5",
+		target.Name, target.UserID, target.Tags, target.UpdatedAt, target.ID,
+	)
+	return wrapDBError(err)
+}
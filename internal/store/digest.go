@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func (s *Store) GetDigest(ctx context.Context, from, to, staleBefore time.Time, topProducts, staleLimit int) (*models.Digest, error) {
+	digest := &models.Digest{
+		From:           from,
+		To:             to,
+		TopProducts:    make([]models.DigestProduct, 0),
+		OrdersBySource: make([]models.DigestSource, 0),
+		StaleRequests:  make([]models.DigestStaleItem, 0),
+	}
+
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE created_at >= $1 AND created_at < $2),
+			COUNT(*) FILTER (WHERE status = $3),
+			COUNT(*) FILTER (WHERE status <> $4 AND updated_at < $5)
+		FROM orders`,
+		from, to, enums.RequestStatusInProgress, enums.RequestStatusReviewed, staleBefore,
+	).Scan(&digest.NewOrders, &digest.InProgressOrders, &digest.StaleOrders)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	err = s.querier(ctx).QueryRow(
+		ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE created_at >= $1 AND created_at < $2),
+			COUNT(*) FILTER (WHERE status = $3),
+			COUNT(*) FILTER (WHERE status <> $4 AND updated_at < $5)
+		FROM feedback`,
+		from, to, enums.RequestStatusInProgress, enums.RequestStatusReviewed, staleBefore,
+	).Scan(&digest.NewFeedback, &digest.InProgressFeedback, &digest.StaleFeedback)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT oi.product_name, SUM(oi.qty), COUNT(DISTINCT oi.order_id)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.created_at >= $1 AND o.created_at < $2
+		GROUP BY oi.product_name
+		ORDER BY SUM(oi.qty) DESC, oi.product_name
+		LIMIT $3`,
+		from, to, topProducts,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	for rows.Next() {
+		product := models.DigestProduct{}
+		err = rows.Scan(&product.ProductName, &product.Qty, &product.Orders)
+		if err != nil {
+			rows.Close()
+			return nil, wrapDBError(err)
+		}
+		digest.TopProducts = append(digest.TopProducts, product)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	rows, err = s.querier(ctx).Query(
+		ctx,
+		"SELECT source, COUNT(*) FROM orders WHERE created_at >= $1 AND created_at < $2 GROUP BY source ORDER BY COUNT(*) DESC",
+		from, to,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	for rows.Next() {
+		source := models.DigestSource{}
+		err = rows.Scan(&source.Source, &source.Count)
+		if err != nil {
+			rows.Close()
+			return nil, wrapDBError(err)
+		}
+		digest.OrdersBySource = append(digest.OrdersBySource, source)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	rows, err = s.querier(ctx).Query(
+		ctx,
+		`SELECT kind, id, uuid, status, name, updated_at FROM (
+			SELECT 'order' AS kind, id, uuid, status, name, updated_at FROM orders WHERE status <> $1 AND updated_at < $2
+			UNION ALL
+			SELECT 'feedback' AS kind, id, uuid, status, name, updated_at FROM feedback WHERE status <> $1 AND updated_at < $2
+		) stale
+		ORDER BY updated_at
+		LIMIT $3`,
+		enums.RequestStatusReviewed, staleBefore, staleLimit,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item := models.DigestStaleItem{}
+		err = rows.Scan(&item.Kind, &item.ID, &item.UUID, &item.Status, &item.Name, &item.UpdatedAt)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		digest.StaleRequests = append(digest.StaleRequests, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return digest, nil
+}
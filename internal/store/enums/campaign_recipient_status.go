@@ -0,0 +1,74 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type CampaignRecipientStatus struct {
+	slug string
+}
+
+func NewCampaignRecipientStatus(s string) (CampaignRecipientStatus, error) {
+	switch s {
+	case CampaignRecipientStatusPending.slug:
+		return CampaignRecipientStatusPending, nil
+	case CampaignRecipientStatusSent.slug:
+		return CampaignRecipientStatusSent, nil
+	case CampaignRecipientStatusBlocked.slug:
+		return CampaignRecipientStatusBlocked, nil
+	case CampaignRecipientStatusFailed.slug:
+		return CampaignRecipientStatusFailed, nil
+	default:
+		return CampaignRecipientStatus{}, fmt.Errorf("unknown campaign recipient status: %s", s)
+	}
+}
+
+var (
+	CampaignRecipientStatusPending = CampaignRecipientStatus{slug: "pending"}
+	CampaignRecipientStatusSent    = CampaignRecipientStatus{slug: "sent"}
+	CampaignRecipientStatusBlocked = CampaignRecipientStatus{slug: "blocked"}
+	CampaignRecipientStatusFailed  = CampaignRecipientStatus{slug: "failed"}
+)
+
+func (c *CampaignRecipientStatus) String() string {
+	return c.slug
+}
+
+func (c *CampaignRecipientStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert campaign recipient status to string")
+	}
+	e, err := NewCampaignRecipientStatus(s)
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
+
+func (c CampaignRecipientStatus) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+func (c CampaignRecipientStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(c.slug))
+}
+
+func (c *CampaignRecipientStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("campaign recipient status must be a JSON string")
+	}
+	e, err := NewCampaignRecipientStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
@@ -0,0 +1,77 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type CampaignStatus struct {
+	slug string
+}
+
+func NewCampaignStatus(s string) (CampaignStatus, error) {
+	switch s {
+	case CampaignStatusDraft.slug:
+		return CampaignStatusDraft, nil
+	case CampaignStatusScheduled.slug:
+		return CampaignStatusScheduled, nil
+	case CampaignStatusSending.slug:
+		return CampaignStatusSending, nil
+	case CampaignStatusDone.slug:
+		return CampaignStatusDone, nil
+	case CampaignStatusCancelled.slug:
+		return CampaignStatusCancelled, nil
+	default:
+		return CampaignStatus{}, fmt.Errorf("unknown campaign status: %s", s)
+	}
+}
+
+var (
+	CampaignStatusDraft     = CampaignStatus{slug: "draft"}
+	CampaignStatusScheduled = CampaignStatus{slug: "scheduled"}
+	CampaignStatusSending   = CampaignStatus{slug: "sending"}
+	CampaignStatusDone      = CampaignStatus{slug: "done"}
+	CampaignStatusCancelled = CampaignStatus{slug: "cancelled"}
+)
+
+func (c *CampaignStatus) String() string {
+	return c.slug
+}
+
+func (c *CampaignStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert campaign status to string")
+	}
+	e, err := NewCampaignStatus(s)
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
+
+func (c CampaignStatus) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+func (c CampaignStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(c.slug))
+}
+
+func (c *CampaignStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("campaign status must be a JSON string")
+	}
+	e, err := NewCampaignStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
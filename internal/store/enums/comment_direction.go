@@ -0,0 +1,71 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type CommentDirection struct {
+	slug string
+}
+
+func NewCommentDirection(s string) (CommentDirection, error) {
+	switch s {
+	case CommentDirectionInternal.slug:
+		return CommentDirectionInternal, nil
+	case CommentDirectionToCustomer.slug:
+		return CommentDirectionToCustomer, nil
+	case CommentDirectionFromCustomer.slug:
+		return CommentDirectionFromCustomer, nil
+	default:
+		return CommentDirection{}, fmt.Errorf("unknown comment direction: %s", s)
+	}
+}
+
+var (
+	CommentDirectionInternal     = CommentDirection{slug: "internal"}
+	CommentDirectionToCustomer   = CommentDirection{slug: "to_customer"}
+	CommentDirectionFromCustomer = CommentDirection{slug: "from_customer"}
+)
+
+func (d *CommentDirection) String() string {
+	return d.slug
+}
+
+func (d *CommentDirection) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert comment direction to string")
+	}
+	e, err := NewCommentDirection(s)
+	if err != nil {
+		return err
+	}
+	*d = e
+	return nil
+}
+
+func (d CommentDirection) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d CommentDirection) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(d.slug))
+}
+
+func (d *CommentDirection) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("comment direction must be a JSON string")
+	}
+	e, err := NewCommentDirection(tok.String())
+	if err != nil {
+		return err
+	}
+	*d = e
+	return nil
+}
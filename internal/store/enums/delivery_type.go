@@ -0,0 +1,73 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type DeliveryType struct {
+	slug  string
+	label string
+}
+
+func NewDeliveryType(s string) (DeliveryType, error) {
+	switch s {
+	case DeliveryTypePickup.slug:
+		return DeliveryTypePickup, nil
+	case DeliveryTypeDelivery.slug:
+		return DeliveryTypeDelivery, nil
+	default:
+		return DeliveryType{}, fmt.Errorf("unknown delivery type: %s", s)
+	}
+}
+
+var (
+	DeliveryTypePickup   = DeliveryType{slug: "pickup", label: "Самовывоз"}
+	DeliveryTypeDelivery = DeliveryType{slug: "delivery", label: "Доставка"}
+)
+
+func (d *DeliveryType) String() string {
+	return d.slug
+}
+
+func (d DeliveryType) Label() string {
+	return d.label
+}
+
+func (d *DeliveryType) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert delivery type to string")
+	}
+	e, err := NewDeliveryType(s)
+	if err != nil {
+		return err
+	}
+	*d = e
+	return nil
+}
+
+func (d DeliveryType) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d DeliveryType) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(d.slug))
+}
+
+func (d *DeliveryType) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("delivery type must be a JSON string")
+	}
+	e, err := NewDeliveryType(tok.String())
+	if err != nil {
+		return err
+	}
+	*d = e
+	return nil
+}
@@ -0,0 +1,73 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type LedgerEntryKind struct {
+	slug  string
+	label string
+}
+
+func NewLedgerEntryKind(s string) (LedgerEntryKind, error) {
+	switch s {
+	case LedgerEntryKindPayment.slug:
+		return LedgerEntryKindPayment, nil
+	case LedgerEntryKindRefund.slug:
+		return LedgerEntryKindRefund, nil
+	default:
+		return LedgerEntryKind{}, fmt.Errorf("unknown ledger entry kind: %s", s)
+	}
+}
+
+var (
+	LedgerEntryKindPayment = LedgerEntryKind{slug: "payment", label: "Оплата"}
+	LedgerEntryKindRefund  = LedgerEntryKind{slug: "refund", label: "Возврат"}
+)
+
+func (k *LedgerEntryKind) String() string {
+	return k.slug
+}
+
+func (k LedgerEntryKind) Label() string {
+	return k.label
+}
+
+func (k *LedgerEntryKind) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert ledger entry kind to string")
+	}
+	e, err := NewLedgerEntryKind(s)
+	if err != nil {
+		return err
+	}
+	*k = e
+	return nil
+}
+
+func (k LedgerEntryKind) Value() (driver.Value, error) {
+	return k.String(), nil
+}
+
+func (k LedgerEntryKind) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(k.slug))
+}
+
+func (k *LedgerEntryKind) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("ledger entry kind must be a JSON string")
+	}
+	e, err := NewLedgerEntryKind(tok.String())
+	if err != nil {
+		return err
+	}
+	*k = e
+	return nil
+}
@@ -0,0 +1,68 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type NotificationChannel struct {
+	slug string
+}
+
+func NewNotificationChannel(s string) (NotificationChannel, error) {
+	switch s {
+	case NotificationChannelTelegram.slug:
+		return NotificationChannelTelegram, nil
+	case NotificationChannelEmail.slug:
+		return NotificationChannelEmail, nil
+	default:
+		return NotificationChannel{}, fmt.Errorf("unknown notification channel: %s", s)
+	}
+}
+
+var (
+	NotificationChannelTelegram = NotificationChannel{slug: "telegram"}
+	NotificationChannelEmail    = NotificationChannel{slug: "email"}
+)
+
+func (c *NotificationChannel) String() string {
+	return c.slug
+}
+
+func (c *NotificationChannel) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert notification channel to string")
+	}
+	e, err := NewNotificationChannel(s)
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
+
+func (c NotificationChannel) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+func (c NotificationChannel) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(c.slug))
+}
+
+func (c *NotificationChannel) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("notification channel must be a JSON string")
+	}
+	e, err := NewNotificationChannel(tok.String())
+	if err != nil {
+		return err
+	}
+	*c = e
+	return nil
+}
@@ -0,0 +1,80 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type NotificationEvent struct {
+	slug string
+}
+
+func NewNotificationEvent(s string) (NotificationEvent, error) {
+	switch s {
+	case NotificationEventNewOrder.slug:
+		return NotificationEventNewOrder, nil
+	case NotificationEventNewFeedback.slug:
+		return NotificationEventNewFeedback, nil
+	case NotificationEventSLABreach.slug:
+		return NotificationEventSLABreach, nil
+	case NotificationEventOrderStatus.slug:
+		return NotificationEventOrderStatus, nil
+	case NotificationEventOrderComment.slug:
+		return NotificationEventOrderComment, nil
+	case NotificationEventRegistration.slug:
+		return NotificationEventRegistration, nil
+	default:
+		return NotificationEvent{}, fmt.Errorf("unknown notification event: %s", s)
+	}
+}
+
+var (
+	NotificationEventNewOrder     = NotificationEvent{slug: "new_order"}
+	NotificationEventNewFeedback  = NotificationEvent{slug: "new_feedback"}
+	NotificationEventSLABreach    = NotificationEvent{slug: "sla_breach"}
+	NotificationEventOrderStatus  = NotificationEvent{slug: "order_status"}
+	NotificationEventOrderComment = NotificationEvent{slug: "order_comment"}
+	NotificationEventRegistration = NotificationEvent{slug: "registration"}
+)
+
+func (e *NotificationEvent) String() string {
+	return e.slug
+}
+
+func (e *NotificationEvent) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert notification event to string")
+	}
+	v, err := NewNotificationEvent(s)
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+func (e NotificationEvent) Value() (driver.Value, error) {
+	return e.String(), nil
+}
+
+func (e NotificationEvent) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(e.slug))
+}
+
+func (e *NotificationEvent) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("notification event must be a JSON string")
+	}
+	v, err := NewNotificationEvent(tok.String())
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
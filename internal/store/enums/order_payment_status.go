@@ -0,0 +1,76 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type OrderPaymentStatus struct {
+	slug  string
+	label string
+}
+
+func NewOrderPaymentStatus(s string) (OrderPaymentStatus, error) {
+	switch s {
+	case OrderPaymentStatusUnpaid.slug:
+		return OrderPaymentStatusUnpaid, nil
+	case OrderPaymentStatusPartiallyPaid.slug:
+		return OrderPaymentStatusPartiallyPaid, nil
+	case OrderPaymentStatusPaid.slug:
+		return OrderPaymentStatusPaid, nil
+	default:
+		return OrderPaymentStatus{}, fmt.Errorf("unknown order payment status: %s", s)
+	}
+}
+
+var (
+	OrderPaymentStatusUnpaid        = OrderPaymentStatus{slug: "unpaid", label: "Не оплачен"}
+	OrderPaymentStatusPartiallyPaid = OrderPaymentStatus{slug: "partially_paid", label: "Частично оплачен"}
+	OrderPaymentStatusPaid          = OrderPaymentStatus{slug: "paid", label: "Оплачен"}
+)
+
+func (p *OrderPaymentStatus) String() string {
+	return p.slug
+}
+
+func (p OrderPaymentStatus) Label() string {
+	return p.label
+}
+
+func (p *OrderPaymentStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert order payment status to string")
+	}
+	e, err := NewOrderPaymentStatus(s)
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
+
+func (p OrderPaymentStatus) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p OrderPaymentStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(p.slug))
+}
+
+func (p *OrderPaymentStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("order payment status must be a JSON string")
+	}
+	e, err := NewOrderPaymentStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
@@ -7,7 +7,8 @@ import (
 )
 
 type OrderSource struct {
-	slug string
+	slug  string
+	label string
 }
 
 func NewOrderSource(s string) (OrderSource, error) {
@@ -18,21 +19,28 @@ func NewOrderSource(s string) (OrderSource, error) {
 		return OrderSourceSPA, nil
 	case OrderSourceTMA.slug:
 		return OrderSourceTMA, nil
+	case OrderSourceBot.slug:
+		return OrderSourceBot, nil
 	default:
 		return OrderSource{}, fmt.Errorf("unknown order source: %s", s)
 	}
 }
 
 var (
-	OrderSourceLanding = OrderSource{slug: "landing"}
-	OrderSourceSPA     = OrderSource{slug: "spa"}
-	OrderSourceTMA     = OrderSource{slug: "tma"}
+	OrderSourceLanding = OrderSource{slug: "landing", label: "Сайт"}
+	OrderSourceSPA     = OrderSource{slug: "spa", label: "Веб-приложение"}
+	OrderSourceTMA     = OrderSource{slug: "tma", label: "Mini App"}
+	OrderSourceBot     = OrderSource{slug: "bot", label: "Чат-бот"}
 )
 
 func (u *OrderSource) String() string {
 	return u.slug
 }
 
+func (u OrderSource) Label() string {
+	return u.label
+}
+
 func (u *OrderSource) Scan(src any) error {
 	s, ok := src.(string)
 	if !ok {
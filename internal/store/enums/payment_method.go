@@ -0,0 +1,79 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type PaymentMethod struct {
+	slug  string
+	label string
+}
+
+func NewPaymentMethod(s string) (PaymentMethod, error) {
+	switch s {
+	case PaymentMethodCash.slug:
+		return PaymentMethodCash, nil
+	case PaymentMethodCard.slug:
+		return PaymentMethodCard, nil
+	case PaymentMethodTransfer.slug:
+		return PaymentMethodTransfer, nil
+	case PaymentMethodOnline.slug:
+		return PaymentMethodOnline, nil
+	default:
+		return PaymentMethod{}, fmt.Errorf("unknown payment method: %s", s)
+	}
+}
+
+var (
+	PaymentMethodCash     = PaymentMethod{slug: "cash", label: "Наличные"}
+	PaymentMethodCard     = PaymentMethod{slug: "card", label: "Карта"}
+	PaymentMethodTransfer = PaymentMethod{slug: "transfer", label: "Перевод"}
+	PaymentMethodOnline   = PaymentMethod{slug: "online", label: "Онлайн"}
+)
+
+func (p *PaymentMethod) String() string {
+	return p.slug
+}
+
+func (p PaymentMethod) Label() string {
+	return p.label
+}
+
+func (p *PaymentMethod) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert payment method to string")
+	}
+	e, err := NewPaymentMethod(s)
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
+
+func (p PaymentMethod) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p PaymentMethod) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(p.slug))
+}
+
+func (p *PaymentMethod) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("payment method must be a JSON string")
+	}
+	e, err := NewPaymentMethod(tok.String())
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
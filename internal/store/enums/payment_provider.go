@@ -0,0 +1,71 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type PaymentProvider struct {
+	slug string
+}
+
+func NewPaymentProvider(s string) (PaymentProvider, error) {
+	switch s {
+	case PaymentProviderTelegram.slug:
+		return PaymentProviderTelegram, nil
+	case PaymentProviderYooKassa.slug:
+		return PaymentProviderYooKassa, nil
+	case PaymentProviderFake.slug:
+		return PaymentProviderFake, nil
+	default:
+		return PaymentProvider{}, fmt.Errorf("unknown payment provider: %s", s)
+	}
+}
+
+var (
+	PaymentProviderTelegram = PaymentProvider{slug: "telegram"}
+	PaymentProviderYooKassa = PaymentProvider{slug: "yookassa"}
+	PaymentProviderFake     = PaymentProvider{slug: "fake"}
+)
+
+func (p *PaymentProvider) String() string {
+	return p.slug
+}
+
+func (p *PaymentProvider) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert payment provider to string")
+	}
+	e, err := NewPaymentProvider(s)
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
+
+func (p PaymentProvider) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p PaymentProvider) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(p.slug))
+}
+
+func (p *PaymentProvider) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("payment provider must be a JSON string")
+	}
+	e, err := NewPaymentProvider(tok.String())
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
@@ -0,0 +1,80 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type PaymentStatus struct {
+	slug string
+}
+
+func NewPaymentStatus(s string) (PaymentStatus, error) {
+	switch s {
+	case PaymentStatusPending.slug:
+		return PaymentStatusPending, nil
+	case PaymentStatusSucceeded.slug:
+		return PaymentStatusSucceeded, nil
+	case PaymentStatusCanceled.slug:
+		return PaymentStatusCanceled, nil
+	case PaymentStatusRefunded.slug:
+		return PaymentStatusRefunded, nil
+	case PaymentStatusRefundPending.slug:
+		return PaymentStatusRefundPending, nil
+	case PaymentStatusReview.slug:
+		return PaymentStatusReview, nil
+	default:
+		return PaymentStatus{}, fmt.Errorf("unknown payment status: %s", s)
+	}
+}
+
+var (
+	PaymentStatusPending       = PaymentStatus{slug: "pending"}
+	PaymentStatusSucceeded     = PaymentStatus{slug: "succeeded"}
+	PaymentStatusCanceled      = PaymentStatus{slug: "canceled"}
+	PaymentStatusRefunded      = PaymentStatus{slug: "refunded"}
+	PaymentStatusRefundPending = PaymentStatus{slug: "refund_pending"}
+	PaymentStatusReview        = PaymentStatus{slug: "review"}
+)
+
+func (p *PaymentStatus) String() string {
+	return p.slug
+}
+
+func (p *PaymentStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert payment status to string")
+	}
+	e, err := NewPaymentStatus(s)
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
+
+func (p PaymentStatus) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p PaymentStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(p.slug))
+}
+
+func (p *PaymentStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("payment status must be a JSON string")
+	}
+	e, err := NewPaymentStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*p = e
+	return nil
+}
@@ -0,0 +1,68 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type PromoCodeKind struct {
+	slug string
+}
+
+func NewPromoCodeKind(s string) (PromoCodeKind, error) {
+	switch s {
+	case PromoCodeKindPercent.slug:
+		return PromoCodeKindPercent, nil
+	case PromoCodeKindFixed.slug:
+		return PromoCodeKindFixed, nil
+	default:
+		return PromoCodeKind{}, fmt.Errorf("unknown promo code kind: %s", s)
+	}
+}
+
+var (
+	PromoCodeKindPercent = PromoCodeKind{slug: "percent"}
+	PromoCodeKindFixed   = PromoCodeKind{slug: "fixed"}
+)
+
+func (k *PromoCodeKind) String() string {
+	return k.slug
+}
+
+func (k *PromoCodeKind) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert promo code kind to string")
+	}
+	e, err := NewPromoCodeKind(s)
+	if err != nil {
+		return err
+	}
+	*k = e
+	return nil
+}
+
+func (k PromoCodeKind) Value() (driver.Value, error) {
+	return k.String(), nil
+}
+
+func (k PromoCodeKind) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(k.slug))
+}
+
+func (k *PromoCodeKind) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("promo code kind must be a JSON string")
+	}
+	e, err := NewPromoCodeKind(tok.String())
+	if err != nil {
+		return err
+	}
+	*k = e
+	return nil
+}
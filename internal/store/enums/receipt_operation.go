@@ -0,0 +1,71 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type ReceiptOperation struct {
+	slug string
+}
+
+func NewReceiptOperation(s string) (ReceiptOperation, error) {
+	switch s {
+	case ReceiptOperationSell.slug:
+		return ReceiptOperationSell, nil
+	case ReceiptOperationSellRefund.slug:
+		return ReceiptOperationSellRefund, nil
+	case ReceiptOperationSellSettlement.slug:
+		return ReceiptOperationSellSettlement, nil
+	default:
+		return ReceiptOperation{}, fmt.Errorf("unknown receipt operation: %s", s)
+	}
+}
+
+var (
+	ReceiptOperationSell           = ReceiptOperation{slug: "sell"}
+	ReceiptOperationSellRefund     = ReceiptOperation{slug: "sell_refund"}
+	ReceiptOperationSellSettlement = ReceiptOperation{slug: "sell_settlement"}
+)
+
+func (o *ReceiptOperation) String() string {
+	return o.slug
+}
+
+func (o *ReceiptOperation) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert receipt operation to string")
+	}
+	e, err := NewReceiptOperation(s)
+	if err != nil {
+		return err
+	}
+	*o = e
+	return nil
+}
+
+func (o ReceiptOperation) Value() (driver.Value, error) {
+	return o.String(), nil
+}
+
+func (o ReceiptOperation) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(o.slug))
+}
+
+func (o *ReceiptOperation) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("receipt operation must be a JSON string")
+	}
+	e, err := NewReceiptOperation(tok.String())
+	if err != nil {
+		return err
+	}
+	*o = e
+	return nil
+}
@@ -0,0 +1,74 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type ReceiptStatus struct {
+	slug string
+}
+
+func NewReceiptStatus(s string) (ReceiptStatus, error) {
+	switch s {
+	case ReceiptStatusPending.slug:
+		return ReceiptStatusPending, nil
+	case ReceiptStatusSent.slug:
+		return ReceiptStatusSent, nil
+	case ReceiptStatusDone.slug:
+		return ReceiptStatusDone, nil
+	case ReceiptStatusFailed.slug:
+		return ReceiptStatusFailed, nil
+	default:
+		return ReceiptStatus{}, fmt.Errorf("unknown receipt status: %s", s)
+	}
+}
+
+var (
+	ReceiptStatusPending = ReceiptStatus{slug: "pending"}
+	ReceiptStatusSent    = ReceiptStatus{slug: "sent"}
+	ReceiptStatusDone    = ReceiptStatus{slug: "done"}
+	ReceiptStatusFailed  = ReceiptStatus{slug: "failed"}
+)
+
+func (r *ReceiptStatus) String() string {
+	return r.slug
+}
+
+func (r *ReceiptStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert receipt status to string")
+	}
+	e, err := NewReceiptStatus(s)
+	if err != nil {
+		return err
+	}
+	*r = e
+	return nil
+}
+
+func (r ReceiptStatus) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r ReceiptStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(r.slug))
+}
+
+func (r *ReceiptStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("receipt status must be a JSON string")
+	}
+	e, err := NewReceiptStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*r = e
+	return nil
+}
@@ -18,6 +18,8 @@ func NewRequestStatus(s string) (RequestStatus, error) {
 		return RequestStatusCreated, nil
 	case RequestStatusInProgress.slug:
 		return RequestStatusInProgress, nil
+	case RequestStatusAwaitingPayment.slug:
+		return RequestStatusAwaitingPayment, nil
 	case RequestStatusReviewed.slug:
 		return RequestStatusReviewed, nil
 	default:
@@ -26,9 +28,10 @@ func NewRequestStatus(s string) (RequestStatus, error) {
 }
 
 var (
-	RequestStatusCreated    = RequestStatus{slug: "created", emoji: "🆕", label: "Новый"}
-	RequestStatusInProgress = RequestStatus{slug: "in_progress", emoji: "💼", label: "В работе"}
-	RequestStatusReviewed   = RequestStatus{slug: "reviewed", emoji: "✅", label: "Завершён"}
+	RequestStatusCreated         = RequestStatus{slug: "created", emoji: "🆕", label: "Новый"}
+	RequestStatusInProgress      = RequestStatus{slug: "in_progress", emoji: "💼", label: "В работе"}
+	RequestStatusAwaitingPayment = RequestStatus{slug: "awaiting_payment", emoji: "💳", label: "Ожидает оплаты"}
+	RequestStatusReviewed        = RequestStatus{slug: "reviewed", emoji: "✅", label: "Завершён"}
 )
 
 func (s RequestStatus) String() string {
@@ -0,0 +1,71 @@
+package enums
+
+import (
+	"database/sql/driver"
+	"encoding/json/jsontext"
+	"fmt"
+)
+
+type ReviewStatus struct {
+	slug string
+}
+
+func NewReviewStatus(s string) (ReviewStatus, error) {
+	switch s {
+	case ReviewStatusPending.slug:
+		return ReviewStatusPending, nil
+	case ReviewStatusApproved.slug:
+		return ReviewStatusApproved, nil
+	case ReviewStatusRejected.slug:
+		return ReviewStatusRejected, nil
+	default:
+		return ReviewStatus{}, fmt.Errorf("unknown review status: %s", s)
+	}
+}
+
+var (
+	ReviewStatusPending  = ReviewStatus{slug: "pending"}
+	ReviewStatusApproved = ReviewStatus{slug: "approved"}
+	ReviewStatusRejected = ReviewStatus{slug: "rejected"}
+)
+
+func (r *ReviewStatus) String() string {
+	return r.slug
+}
+
+func (r *ReviewStatus) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not assert review status to string")
+	}
+	e, err := NewReviewStatus(s)
+	if err != nil {
+		return err
+	}
+	*r = e
+	return nil
+}
+
+func (r ReviewStatus) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r ReviewStatus) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String(r.slug))
+}
+
+func (r *ReviewStatus) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("review status must be a JSON string")
+	}
+	e, err := NewReviewStatus(tok.String())
+	if err != nil {
+		return err
+	}
+	*r = e
+	return nil
+}
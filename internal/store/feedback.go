@@ -4,11 +4,59 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
 	"github.com/zagvozdeen/ola/internal/store/models"
 )
 
+const feedbackColumns = "id, uuid, status, source, type, name, phone, content, user_id, assignee_id, customer_id, order_id, attribution, status_changed_at, created_at, updated_at"
+
+func scanFeedback(row pgx.Row, feedback *models.Feedback) error {
+	return row.Scan(
+		&feedback.ID,
+		&feedback.UUID,
+		&feedback.Status,
+		&feedback.Source,
+		&feedback.Type,
+		&feedback.Name,
+		&feedback.Phone,
+		&feedback.Content,
+		&feedback.UserID,
+		&feedback.AssigneeID,
+		&feedback.CustomerID,
+		&feedback.OrderID,
+		&feedback.Attribution,
+		&feedback.StatusChangedAt,
+		&feedback.CreatedAt,
+		&feedback.UpdatedAt,
+	)
+}
+
 func (s *Store) GetAllFeedback(ctx context.Context) ([]models.Feedback, error) {
-	rows, err := s.querier(ctx).Query(ctx, "SELECT id, uuid, status, source, type, name, phone, content, user_id, created_at, updated_at FROM feedback ORDER BY created_at DESC")
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+feedbackColumns+" FROM feedback ORDER BY created_at DESC")
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	feedbacks := make([]models.Feedback, 0)
+	for rows.Next() {
+		var feedback models.Feedback
+		err = scanFeedback(rows, &feedback)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return feedbacks, nil
+}
+
+func (s *Store) GetUnfinishedFeedback(ctx context.Context) ([]models.Feedback, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+feedbackColumns+" FROM feedback WHERE status <> $1 ORDER BY status_changed_at", enums.RequestStatusReviewed)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -17,7 +65,7 @@ func (s *Store) GetAllFeedback(ctx context.Context) ([]models.Feedback, error) {
 	feedbacks := make([]models.Feedback, 0)
 	for rows.Next() {
 		var feedback models.Feedback
-		err = rows.Scan(&feedback.ID, &feedback.UUID, &feedback.Status, &feedback.Source, &feedback.Type, &feedback.Name, &feedback.Phone, &feedback.Content, &feedback.UserID, &feedback.CreatedAt, &feedback.UpdatedAt)
+		err = scanFeedback(rows, &feedback)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -32,25 +80,21 @@ func (s *Store) GetAllFeedback(ctx context.Context) ([]models.Feedback, error) {
 
 func (s *Store) GetFeedbackByID(ctx context.Context, id int) (*models.Feedback, error) {
 	feedback := &models.Feedback{}
-	err := s.querier(ctx).QueryRow(
+	err := scanFeedback(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, status, source, type, name, phone, content, user_id, created_at, updated_at FROM feedback WHERE id = $1",
+		"SELECT "+feedbackColumns+" FROM feedback WHERE id = $1",
 		id,
-	).Scan(
-		&feedback.ID, &feedback.UUID, &feedback.Status, &feedback.Source, &feedback.Type, &feedback.Name, &feedback.Phone, &feedback.Content, &feedback.UserID, &feedback.CreatedAt, &feedback.UpdatedAt,
-	)
+	), feedback)
 	return feedback, wrapDBError(err)
 }
 
 func (s *Store) GetFeedbackByUUID(ctx context.Context, feedbackUUID uuid.UUID) (*models.Feedback, error) {
 	feedback := &models.Feedback{}
-	err := s.querier(ctx).QueryRow(
+	err := scanFeedback(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, status, source, type, name, phone, content, user_id, created_at, updated_at FROM feedback WHERE uuid = $1",
+		"SELECT "+feedbackColumns+" FROM feedback WHERE uuid = $1",
 		feedbackUUID,
-	).Scan(
-		&feedback.ID, &feedback.UUID, &feedback.Status, &feedback.Source, &feedback.Type, &feedback.Name, &feedback.Phone, &feedback.Content, &feedback.UserID, &feedback.CreatedAt, &feedback.UpdatedAt,
-	)
+	), feedback)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -61,8 +105,8 @@ func (s *Store) GetFeedbackByUUID(ctx context.Context, feedbackUUID uuid.UUID) (
 func (s *Store) CreateFeedback(ctx context.Context, feedback *models.Feedback) error {
 	err := s.querier(ctx).QueryRow(
 		ctx,
-		"INSERT INTO feedback (uuid, status, source, type, name, phone, content, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
-		feedback.UUID, feedback.Status, feedback.Source, feedback.Type, feedback.Name, feedback.Phone, feedback.Content, feedback.UserID, feedback.CreatedAt, feedback.UpdatedAt,
+		"INSERT INTO feedback (uuid, status, source, type, name, phone, content, user_id, customer_id, attribution, status_changed_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id",
+		feedback.UUID, feedback.Status, feedback.Source, feedback.Type, feedback.Name, feedback.Phone, feedback.Content, feedback.UserID, feedback.CustomerID, feedback.Attribution, feedback.StatusChangedAt, feedback.CreatedAt, feedback.UpdatedAt,
 	).Scan(&feedback.ID)
 	return wrapDBError(err)
 }
@@ -70,8 +114,17 @@ func (s *Store) CreateFeedback(ctx context.Context, feedback *models.Feedback) e
 func (s *Store) UpdateFeedbackStatus(ctx context.Context, feedback *models.Feedback) error {
 	_, err := s.querier(ctx).Exec(
 		ctx,
-		"UPDATE feedback SET status = $2, updated_at = $3 WHERE id = $1",
-		feedback.ID, feedback.Status, feedback.UpdatedAt,
+		"UPDATE feedback SET status = $2, status_changed_at = $3, assignee_id = $4, updated_at = $5 WHERE id = $1",
+		feedback.ID, feedback.Status, feedback.StatusChangedAt, feedback.AssigneeID, feedback.UpdatedAt,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateFeedbackOrder(ctx context.Context, feedback *models.Feedback) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE feedback SET order_id = $2, updated_at = $3 WHERE id = $1",
+		feedback.ID, feedback.OrderID, feedback.UpdatedAt,
 	)
 	return wrapDBError(err)
 }
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func (s *Store) GetFeedbackCommentsByFeedbackIDs(ctx context.Context, feedbackIDs []int) (map[int][]models.FeedbackComment, error) {
+	commentsByFeedbackID := make(map[int][]models.FeedbackComment, len(feedbackIDs))
+	if len(feedbackIDs) == 0 {
+		return commentsByFeedbackID, nil
+	}
+
+	placeholders := make([]string, 0, len(feedbackIDs))
+	args := make([]any, 0, len(feedbackIDs))
+	for _, feedbackID := range feedbackIDs {
+		args = append(args, feedbackID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT
+			fc.id,
+			fc.uuid,
+			fc.content,
+			fc.feedback_id,
+			fc.user_id,
+			fc.created_at,
+			fc.updated_at,
+			u.id,
+			u.uuid,
+			u.first_name,
+			u.last_name,
+			u.username
+		FROM feedback_comments fc
+		JOIN users u ON u.id = fc.user_id
+		WHERE fc.feedback_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY fc.feedback_id, fc.created_at, fc.id`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment := models.FeedbackComment{
+			Author: &models.OrderCommentAuthor{},
+		}
+		err = rows.Scan(
+			&comment.ID,
+			&comment.UUID,
+			&comment.Content,
+			&comment.FeedbackID,
+			&comment.UserID,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+			&comment.Author.ID,
+			&comment.Author.UUID,
+			&comment.Author.FirstName,
+			&comment.Author.LastName,
+			&comment.Author.Username,
+		)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+
+		commentsByFeedbackID[comment.FeedbackID] = append(commentsByFeedbackID[comment.FeedbackID], comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return commentsByFeedbackID, nil
+}
+
+func (s *Store) CreateFeedbackComment(ctx context.Context, comment *models.FeedbackComment) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO feedback_comments (uuid, content, feedback_id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		comment.UUID,
+		comment.Content,
+		comment.FeedbackID,
+		comment.UserID,
+		comment.CreatedAt,
+		comment.UpdatedAt,
+	).Scan(&comment.ID)
+	return wrapDBError(err)
+}
@@ -19,6 +19,47 @@ func (s *Store) GetFeedbackTelegramMessageByFeedbackID(ctx context.Context, id i
 	return message, nil
 }
 
+func (s *Store) GetFeedbackTelegramMessagesByFeedbackID(ctx context.Context, id int) ([]models.FeedbackTelegramMessage, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		"SELECT feedback_id, chat_id, message_id FROM feedback_telegram_messages WHERE feedback_id = $1 ORDER BY message_id",
+		id,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.FeedbackTelegramMessage, 0)
+	for rows.Next() {
+		message := models.FeedbackTelegramMessage{}
+		err = rows.Scan(&message.FeedbackID, &message.ChatID, &message.MessageID)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return messages, nil
+}
+
+func (s *Store) GetFeedbackTelegramMessageByMessageID(ctx context.Context, chatID, messageID int64) (*models.FeedbackTelegramMessage, error) {
+	message := &models.FeedbackTelegramMessage{}
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT feedback_id, chat_id, message_id FROM feedback_telegram_messages WHERE chat_id = $1 AND message_id = $2",
+		chatID,
+		messageID,
+	).Scan(&message.FeedbackID, &message.ChatID, &message.MessageID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return message, nil
+}
+
 func (s *Store) CreateFeedbackTelegramMessage(ctx context.Context, message *models.FeedbackTelegramMessage) error {
 	_, err := s.querier(ctx).Exec(
 		ctx,
@@ -8,18 +8,26 @@ import (
 )
 
 type User struct {
-	ID        int            `json:"id"`
-	TID       *int64         `json:"tid"`
-	UUID      uuid.UUID      `json:"uuid"`
-	FirstName string         `json:"first_name"`
-	LastName  *string        `json:"last_name"`
-	Username  *string        `json:"username"`
-	Email     *string        `json:"email"`
-	Phone     *string        `json:"phone"`
-	Password  *string        `json:"-"`
-	Role      enums.UserRole `json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID           int            `json:"id"`
+	TID          *int64         `json:"tid"`
+	UUID         uuid.UUID      `json:"uuid"`
+	FirstName    string         `json:"first_name"`
+	LastName     *string        `json:"last_name"`
+	Username     *string        `json:"username"`
+	Email        *string        `json:"email"`
+	Phone        *string        `json:"phone"`
+	Password     *string        `json:"-"`
+	Role         enums.UserRole `json:"role"`
+	ReferralCode *string        `json:"referral_code"`
+	BotBlockedAt *time.Time     `json:"bot_blocked_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+type NotificationPreference struct {
+	Channel enums.NotificationChannel `json:"channel"`
+	Event   enums.NotificationEvent   `json:"event"`
+	Enabled bool                      `json:"enabled"`
 }
 
 type File struct {
@@ -34,19 +42,21 @@ type File struct {
 }
 
 type Product struct {
-	ID          int               `json:"id"`
-	UUID        uuid.UUID         `json:"uuid"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	PriceFrom   int               `json:"price_from"`
-	PriceTo     *int              `json:"price_to"`
-	Type        enums.ProductType `json:"type"`
-	IsMain      bool              `json:"is_main"`
-	FileContent string            `json:"file_content"`
-	Categories  []Category        `json:"categories"`
-	UserID      int               `json:"user_id"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID           int               `json:"id"`
+	UUID         uuid.UUID         `json:"uuid"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	PriceFrom    int               `json:"price_from"`
+	PriceTo      *int              `json:"price_to"`
+	Type         enums.ProductType `json:"type"`
+	IsMain       bool              `json:"is_main"`
+	FileContent  string            `json:"file_content"`
+	Rating       float64           `json:"rating"`
+	ReviewsCount int               `json:"reviews_count"`
+	Categories   []Category        `json:"categories"`
+	UserID       int               `json:"user_id"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 type Cart struct {
@@ -58,6 +68,65 @@ type Cart struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type Referral struct {
+	ID                int        `json:"id"`
+	ReferrerID        int        `json:"referrer_id"`
+	ReferredID        int        `json:"referred_id"`
+	OrderID           *int       `json:"order_id"`
+	RewardPromoCodeID *int       `json:"reward_promo_code_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ConvertedAt       *time.Time `json:"converted_at"`
+}
+
+type ReferralLink struct {
+	Code      string `json:"code"`
+	URL       string `json:"url"`
+	Invited   int    `json:"invited"`
+	Converted int    `json:"converted"`
+}
+
+type ReferralReportRow struct {
+	ReferrerID   int       `json:"referrer_id"`
+	ReferrerUUID uuid.UUID `json:"referrer_uuid"`
+	FirstName    string    `json:"first_name"`
+	Username     *string   `json:"username"`
+	ReferralCode *string   `json:"referral_code"`
+	Invited      int       `json:"invited"`
+	Converted    int       `json:"converted"`
+	Orders       int       `json:"orders"`
+	Revenue      int       `json:"revenue"`
+	Rewards      int       `json:"rewards"`
+}
+
+type PromoCode struct {
+	ID             int                 `json:"id"`
+	UUID           uuid.UUID           `json:"uuid"`
+	Code           string              `json:"code"`
+	Kind           enums.PromoCodeKind `json:"kind"`
+	Value          int                 `json:"value"`
+	MinOrderAmount *int                `json:"min_order_amount"`
+	StartsAt       *time.Time          `json:"starts_at"`
+	EndsAt         *time.Time          `json:"ends_at"`
+	UsageLimit     *int                `json:"usage_limit"`
+	PerUserLimit   *int                `json:"per_user_limit"`
+	ProductTypes   []enums.ProductType `json:"product_types"`
+	CategoryIDs    []int               `json:"category_ids"`
+	IsActive       bool                `json:"is_active"`
+	UsedCount      int                 `json:"used_count"`
+	OwnerID        *int                `json:"owner_id"`
+	CreatedBy      *int                `json:"created_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// CartPromo is a promo code applied to the cart with the discount for its current items.
+type CartPromo struct {
+	Code     string `json:"code"`
+	Subtotal int    `json:"subtotal"`
+	Discount int    `json:"discount"`
+	Total    int    `json:"total"`
+}
+
 type CartItem struct {
 	ProductID   int               `json:"product_id"`
 	ProductUUID uuid.UUID         `json:"product_uuid"`
@@ -70,18 +139,37 @@ type CartItem struct {
 }
 
 type Order struct {
-	ID        int                 `json:"id"`
-	UUID      uuid.UUID           `json:"uuid"`
-	Status    enums.RequestStatus `json:"status"`
-	Source    enums.OrderSource   `json:"source"`
-	Name      string              `json:"name"`
-	Phone     string              `json:"phone"`
-	Content   string              `json:"content"`
-	Items     []OrderItem         `json:"items"`
-	Comments  []OrderComment      `json:"comments"`
-	UserID    *int                `json:"user_id"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
+	ID              int                      `json:"id"`
+	UUID            uuid.UUID                `json:"uuid"`
+	Status          enums.RequestStatus      `json:"status"`
+	Source          enums.OrderSource        `json:"source"`
+	DeliveryType    enums.DeliveryType       `json:"delivery_type"`
+	Name            string                   `json:"name"`
+	Phone           string                   `json:"phone"`
+	Content         string                   `json:"content"`
+	Items           []OrderItem              `json:"items"`
+	Comments        []OrderComment           `json:"comments"`
+	Ledger          []LedgerEntry            `json:"ledger"`
+	UserID          *int                     `json:"user_id"`
+	AssigneeID      *int                     `json:"assignee_id"`
+	CustomerID      *int                     `json:"customer_id"`
+	FeedbackID      *int                     `json:"feedback_id"`
+	PromoCodeID     *int                     `json:"promo_code_id"`
+	PromoCode       *string                  `json:"promo_code"`
+	Discount        int                      `json:"discount"`
+	Amount          *int                     `json:"amount"`
+	PaidAmount      int                      `json:"paid_amount"`
+	BalanceDue      *int                     `json:"balance_due"`
+	PaymentStatus   enums.OrderPaymentStatus `json:"payment_status"`
+	PaidAt          *time.Time               `json:"paid_at"`
+	Attribution     *Attribution             `json:"attribution"`
+	Rating          *int                     `json:"rating"`
+	RatedAt         *time.Time               `json:"rated_at"`
+	StatusChangedAt time.Time                `json:"status_changed_at"`
+	TimeInStatus    int64                    `json:"time_in_status"`
+	SLABreached     bool                     `json:"sla_breached"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
 }
 
 type OrderItem struct {
@@ -95,17 +183,114 @@ type OrderItem struct {
 }
 
 type Feedback struct {
-	ID        int                 `json:"id"`
-	UUID      uuid.UUID           `json:"uuid"`
-	Status    enums.RequestStatus `json:"status"`
-	Source    enums.OrderSource   `json:"source"`
-	Type      enums.FeedbackType  `json:"type"`
-	Name      string              `json:"name"`
-	Phone     string              `json:"phone"`
-	Content   string              `json:"content"`
-	UserID    int                 `json:"user_id"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
+	ID              int                 `json:"id"`
+	UUID            uuid.UUID           `json:"uuid"`
+	Status          enums.RequestStatus `json:"status"`
+	Source          enums.OrderSource   `json:"source"`
+	Type            enums.FeedbackType  `json:"type"`
+	Name            string              `json:"name"`
+	Phone           string              `json:"phone"`
+	Content         string              `json:"content"`
+	UserID          int                 `json:"user_id"`
+	AssigneeID      *int                `json:"assignee_id"`
+	CustomerID      *int                `json:"customer_id"`
+	OrderID         *int                `json:"order_id"`
+	Attribution     *Attribution        `json:"attribution"`
+	StatusChangedAt time.Time           `json:"status_changed_at"`
+	TimeInStatus    int64               `json:"time_in_status"`
+	SLABreached     bool                `json:"sla_breached"`
+	Comments        []FeedbackComment   `json:"comments"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// Customer is a client identified by phone across guest and registered requests.
+// Phones lists every number of the customer, including those of merged duplicates.
+type Customer struct {
+	ID            int        `json:"id"`
+	UUID          uuid.UUID  `json:"uuid"`
+	Name          string     `json:"name"`
+	Phone         string     `json:"phone"`
+	Phones        []string   `json:"phones"`
+	Tags          []string   `json:"tags"`
+	UserID        *int       `json:"user_id"`
+	OrdersCount   int        `json:"orders_count"`
+	OrdersAmount  int        `json:"orders_amount"`
+	PaidAmount    int        `json:"paid_amount"`
+	FeedbackCount int        `json:"feedback_count"`
+	LastOrderAt   *time.Time `json:"last_order_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type CustomerCard struct {
+	Customer
+	User     *User          `json:"user"`
+	Notes    []CustomerNote `json:"notes"`
+	Orders   []Order        `json:"orders"`
+	Feedback []Feedback     `json:"feedback"`
+}
+
+// CustomerNote is an internal manager note, it is never shown to the customer.
+type CustomerNote struct {
+	ID         int       `json:"id"`
+	UUID       uuid.UUID `json:"uuid"`
+	CustomerID int       `json:"customer_id"`
+	UserID     *int      `json:"user_id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CustomerTag struct {
+	Tag       string `json:"tag"`
+	Customers int    `json:"customers"`
+}
+
+// Segment is a saved customer filter, e.g. "ordered in the last 90 days from the Mini App".
+type Segment struct {
+	ID        int           `json:"id"`
+	UUID      uuid.UUID     `json:"uuid"`
+	Name      string        `json:"name"`
+	Filter    SegmentFilter `json:"filter"`
+	CreatedBy *int          `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// SegmentFilter conditions are combined with AND, empty conditions match every customer.
+// Tags match customers having any of them, Sources and OrderedWithinDays require an order
+// matching both.
+type SegmentFilter struct {
+	Tags              []string `json:"tags"`
+	ExcludeTags       []string `json:"exclude_tags"`
+	Sources           []string `json:"sources"`
+	OrderedWithinDays *int     `json:"ordered_within_days"`
+	MinOrders         *int     `json:"min_orders"`
+	MinOrdersAmount   *int     `json:"min_orders_amount"`
+	HasTelegram       bool     `json:"has_telegram"`
+}
+
+// Attribution is the marketing source of a landing visit that ended with an order or feedback.
+type Attribution struct {
+	Source      string    `json:"utm_source,omitempty"`
+	Medium      string    `json:"utm_medium,omitempty"`
+	Campaign    string    `json:"utm_campaign,omitempty"`
+	Term        string    `json:"utm_term,omitempty"`
+	Content     string    `json:"utm_content,omitempty"`
+	Referrer    string    `json:"referrer,omitempty"`
+	LandingPage string    `json:"landing_page,omitempty"`
+	CapturedAt  time.Time `json:"captured_at"`
+}
+
+type AttributionReportRow struct {
+	Source   string `json:"utm_source"`
+	Medium   string `json:"utm_medium"`
+	Campaign string `json:"utm_campaign"`
+	Orders   int    `json:"orders"`
+	Feedback int    `json:"feedback"`
+	Revenue  int    `json:"revenue"`
+	Paid     int    `json:"paid"`
 }
 
 type Category struct {
@@ -134,14 +319,128 @@ type FeedbackTelegramMessage struct {
 }
 
 type OrderComment struct {
-	ID        int                 `json:"id"`
-	UUID      uuid.UUID           `json:"uuid"`
-	Content   string              `json:"content"`
-	OrderID   int                 `json:"order_id"`
-	UserID    int                 `json:"user_id"`
-	Author    *OrderCommentAuthor `json:"author,omitempty"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
+	ID        int                    `json:"id"`
+	UUID      uuid.UUID              `json:"uuid"`
+	Content   string                 `json:"content"`
+	IsPublic  bool                   `json:"is_public"`
+	Direction enums.CommentDirection `json:"direction"`
+	OrderID   int                    `json:"order_id"`
+	UserID    int                    `json:"user_id"`
+	Author    *OrderCommentAuthor    `json:"author,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type OrderThread struct {
+	ID              int        `json:"id"`
+	OrderID         int        `json:"order_id"`
+	ChatID          int64      `json:"chat_id"`
+	MessageThreadID int64      `json:"message_thread_id"`
+	CustomerID      int        `json:"customer_id"`
+	CreatedBy       int        `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ClosedAt        *time.Time `json:"closed_at"`
+}
+
+type Payment struct {
+	ID               int                   `json:"id"`
+	UUID             uuid.UUID             `json:"uuid"`
+	OrderID          int                   `json:"order_id"`
+	UserID           *int                  `json:"user_id"`
+	Provider         enums.PaymentProvider `json:"provider"`
+	Status           enums.PaymentStatus   `json:"status"`
+	Amount           int                   `json:"amount"`
+	Currency         string                `json:"currency"`
+	ExternalID       *string               `json:"external_id"`
+	ProviderChargeID *string               `json:"provider_charge_id"`
+	ConfirmationURL  *string               `json:"confirmation_url"`
+	PaidAt           *time.Time            `json:"paid_at"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+}
+
+type LedgerEntry struct {
+	ID         int                   `json:"id"`
+	UUID       uuid.UUID             `json:"uuid"`
+	OrderID    int                   `json:"order_id"`
+	PaymentID  *int                  `json:"payment_id"`
+	Kind       enums.LedgerEntryKind `json:"kind"`
+	Method     enums.PaymentMethod   `json:"method"`
+	Amount     int                   `json:"amount"`
+	Comment    *string               `json:"comment"`
+	RecordedBy *int                  `json:"recorded_by"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+type Receipt struct {
+	ID            int                    `json:"id"`
+	UUID          uuid.UUID              `json:"uuid"`
+	OrderID       int                    `json:"order_id"`
+	PaymentID     int                    `json:"payment_id"`
+	Operation     enums.ReceiptOperation `json:"operation"`
+	Status        enums.ReceiptStatus    `json:"status"`
+	Operator      string                 `json:"operator"`
+	Payload       ReceiptPayload         `json:"payload"`
+	ExternalID    *string                `json:"external_id"`
+	Fiscal        *ReceiptFiscal         `json:"fiscal"`
+	Attempts      int                    `json:"attempts"`
+	LastError     *string                `json:"last_error"`
+	NextAttemptAt time.Time              `json:"next_attempt_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// ReceiptPayload is the fiscal document sent to the operator. Amounts are whole rubles.
+// Advance marks a settlement receipt paid off by an earlier prepayment.
+type ReceiptPayload struct {
+	Email     string        `json:"email,omitempty"`
+	Phone     string        `json:"phone,omitempty"`
+	Taxation  string        `json:"taxation"`
+	Items     []ReceiptItem `json:"items"`
+	Total     int           `json:"total"`
+	Advance   bool          `json:"advance,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type ReceiptItem struct {
+	Name           string `json:"name"`
+	Price          int    `json:"price"`
+	Quantity       int    `json:"quantity"`
+	Sum            int    `json:"sum"`
+	VAT            string `json:"vat"`
+	PaymentSubject string `json:"payment_subject"`
+	PaymentMethod  string `json:"payment_method"`
+}
+
+type ReceiptFiscal struct {
+	FiscalDocumentNumber    int64     `json:"fiscal_document_number"`
+	FiscalDocumentAttribute int64     `json:"fiscal_document_attribute"`
+	FiscalReceiptNumber     int64     `json:"fiscal_receipt_number"`
+	ShiftNumber             int64     `json:"shift_number"`
+	FNNumber                string    `json:"fn_number"`
+	RegistrationNumber      string    `json:"registration_number"`
+	ReceiptAt               time.Time `json:"receipt_at"`
+}
+
+type OrderPayment struct {
+	Order   *Order   `json:"order"`
+	Payment *Payment `json:"payment"`
+}
+
+type OrderStatusChange struct {
+	Order     *Order              `json:"order"`
+	OldStatus enums.RequestStatus `json:"old_status"`
+}
+
+type FeedbackComment struct {
+	ID         int                 `json:"id"`
+	UUID       uuid.UUID           `json:"uuid"`
+	Content    string              `json:"content"`
+	FeedbackID int                 `json:"feedback_id"`
+	UserID     int                 `json:"user_id"`
+	Author     *OrderCommentAuthor `json:"author,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
 }
 
 type OrderCommentAuthor struct {
@@ -152,8 +451,142 @@ type OrderCommentAuthor struct {
 	Username  *string   `json:"username"`
 }
 
+type SLAEscalation struct {
+	Kind            string              `json:"kind"`
+	EntityID        int                 `json:"entity_id"`
+	Status          enums.RequestStatus `json:"status"`
+	StatusChangedAt time.Time           `json:"status_changed_at"`
+	Level           int                 `json:"level"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+type Digest struct {
+	From               time.Time         `json:"from"`
+	To                 time.Time         `json:"to"`
+	NewOrders          int               `json:"new_orders"`
+	InProgressOrders   int               `json:"in_progress_orders"`
+	StaleOrders        int               `json:"stale_orders"`
+	NewFeedback        int               `json:"new_feedback"`
+	InProgressFeedback int               `json:"in_progress_feedback"`
+	StaleFeedback      int               `json:"stale_feedback"`
+	TopProducts        []DigestProduct   `json:"top_products"`
+	OrdersBySource     []DigestSource    `json:"orders_by_source"`
+	StaleRequests      []DigestStaleItem `json:"stale_requests"`
+}
+
+type DigestProduct struct {
+	ProductName string `json:"product_name"`
+	Qty         int    `json:"qty"`
+	Orders      int    `json:"orders"`
+}
+
+type DigestSource struct {
+	Source enums.OrderSource `json:"source"`
+	Count  int               `json:"count"`
+}
+
+type DigestStaleItem struct {
+	Kind      string              `json:"kind"`
+	ID        int                 `json:"id"`
+	UUID      uuid.UUID           `json:"uuid"`
+	Status    enums.RequestStatus `json:"status"`
+	Name      string              `json:"name"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
 type Action struct {
 	ID        int
 	Content   string
 	CreatedAt time.Time
 }
+
+type BotConversation struct {
+	UserID    int                 `json:"user_id"`
+	State     string              `json:"state"`
+	Data      BotConversationData `json:"data"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type BotConversationData struct {
+	Category     string `json:"category,omitempty"`
+	ProductID    int    `json:"product_id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	DeliveryType string `json:"delivery_type,omitempty"`
+}
+
+type Campaign struct {
+	ID          int                  `json:"id"`
+	UUID        uuid.UUID            `json:"uuid"`
+	Name        string               `json:"name"`
+	Text        string               `json:"text"`
+	Image       *string              `json:"image"`
+	ImageFileID *string              `json:"-"`
+	ButtonText  *string              `json:"button_text"`
+	SegmentID   *int                 `json:"segment_id"`
+	Status      enums.CampaignStatus `json:"status"`
+	ScheduledAt *time.Time           `json:"scheduled_at"`
+	StartedAt   *time.Time           `json:"started_at"`
+	FinishedAt  *time.Time           `json:"finished_at"`
+	CreatedBy   *int                 `json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Stats       CampaignStats        `json:"stats"`
+}
+
+// CampaignStats counts campaign recipients by delivery status.
+type CampaignStats struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Sent    int `json:"sent"`
+	Blocked int `json:"blocked"`
+	Failed  int `json:"failed"`
+}
+
+type CampaignRecipient struct {
+	ID            int                           `json:"id"`
+	CampaignID    int                           `json:"campaign_id"`
+	UserID        int                           `json:"user_id"`
+	ChatID        int64                         `json:"chat_id"`
+	Status        enums.CampaignRecipientStatus `json:"status"`
+	MessageID     *int                          `json:"message_id"`
+	Attempts      int                           `json:"attempts"`
+	LastError     *string                       `json:"last_error"`
+	NextAttemptAt time.Time                     `json:"next_attempt_at"`
+	SentAt        *time.Time                    `json:"sent_at"`
+	CreatedAt     time.Time                     `json:"created_at"`
+	UpdatedAt     time.Time                     `json:"updated_at"`
+}
+
+type Review struct {
+	ID              int                `json:"id"`
+	UUID            uuid.UUID          `json:"uuid"`
+	ProductID       int                `json:"product_id"`
+	ProductName     string             `json:"product_name"`
+	OrderID         int                `json:"order_id"`
+	UserID          int                `json:"user_id"`
+	AuthorName      string             `json:"author_name"`
+	Rating          int                `json:"rating"`
+	Content         string             `json:"content"`
+	Files           []string           `json:"files"`
+	Status          enums.ReviewStatus `json:"status"`
+	ModeratorID     *int               `json:"moderator_id"`
+	ModeratedAt     *time.Time         `json:"moderated_at"`
+	RejectionReason *string            `json:"rejection_reason"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// OrderReviewRequest asks the customer to rate a completed order once DueAt passes.
+// Channel is nil when the request was skipped because the customer can not be reached.
+type OrderReviewRequest struct {
+	ID        int                        `json:"id"`
+	OrderID   int                        `json:"order_id"`
+	Channel   *enums.NotificationChannel `json:"channel"`
+	Attempts  int                        `json:"attempts"`
+	LastError *string                    `json:"last_error"`
+	DueAt     time.Time                  `json:"due_at"`
+	SentAt    *time.Time                 `json:"sent_at"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+func (s *Store) GetNotificationPreferencesByUserID(ctx context.Context, userID int) ([]models.NotificationPreference, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT channel, event, enabled FROM notification_preferences WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	preferences := make([]models.NotificationPreference, 0)
+	for rows.Next() {
+		preference := models.NotificationPreference{}
+		err = rows.Scan(&preference.Channel, &preference.Event, &preference.Enabled)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		preferences = append(preferences, preference)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return preferences, nil
+}
+
+func (s *Store) UpsertNotificationPreference(ctx context.Context, userID int, preference *models.NotificationPreference, updatedAt time.Time) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`INSERT INTO notification_preferences (user_id, channel, event, enabled, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, channel, event) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`,
+		userID, preference.Channel, preference.Event, preference.Enabled, updatedAt,
+	)
+	return wrapDBError(err)
+}
+
+// GetNotificationRecipients returns users with one of the roles who have the event enabled for the channel,
+// falling back to enabledByDefault when the user has not set the preference.
+func (s *Store) GetNotificationRecipients(ctx context.Context, roles []enums.UserRole, channel enums.NotificationChannel, event enums.NotificationEvent, enabledByDefault bool) ([]models.User, error) {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.String())
+	}
+
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT `+userColumns+` FROM users
+		WHERE role::text = ANY($1) AND COALESCE((
+			SELECT np.enabled FROM notification_preferences np
+			WHERE np.user_id = users.id AND np.channel = $2 AND np.event = $3
+		), $4)
+		ORDER BY id`,
+		names, channel, event, enabledByDefault,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		user := models.User{}
+		err = scanUser(rows, &user)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return users, nil
+}
@@ -27,6 +27,8 @@ func (s *Store) GetOrderCommentsByOrderIDs(ctx context.Context, orderIDs []int)
 			oc.id,
 			oc.uuid,
 			oc.content,
+			oc.is_public,
+			oc.direction,
 			oc.order_id,
 			oc.user_id,
 			oc.created_at,
@@ -55,6 +57,8 @@ func (s *Store) GetOrderCommentsByOrderIDs(ctx context.Context, orderIDs []int)
 			&comment.ID,
 			&comment.UUID,
 			&comment.Content,
+			&comment.IsPublic,
+			&comment.Direction,
 			&comment.OrderID,
 			&comment.UserID,
 			&comment.CreatedAt,
@@ -81,9 +85,11 @@ func (s *Store) GetOrderCommentsByOrderIDs(ctx context.Context, orderIDs []int)
 func (s *Store) CreateOrderComment(ctx context.Context, comment *models.OrderComment) error {
 	err := s.querier(ctx).QueryRow(
 		ctx,
-		"INSERT INTO order_comments (uuid, content, order_id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		"INSERT INTO order_comments (uuid, content, is_public, direction, order_id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
 		comment.UUID,
 		comment.Content,
+		comment.IsPublic,
+		comment.Direction,
 		comment.OrderID,
 		comment.UserID,
 		comment.CreatedAt,
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const ledgerEntryColumns = "id, uuid, order_id, payment_id, kind, method, amount, comment, recorded_by, created_at"
+
+func scanLedgerEntry(row pgx.Row, entry *models.LedgerEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.UUID,
+		&entry.OrderID,
+		&entry.PaymentID,
+		&entry.Kind,
+		&entry.Method,
+		&entry.Amount,
+		&entry.Comment,
+		&entry.RecordedBy,
+		&entry.CreatedAt,
+	)
+}
+
+func (s *Store) GetLedgerEntriesByOrderIDs(ctx context.Context, orderIDs []int) (map[int][]models.LedgerEntry, error) {
+	entriesByOrderID := make(map[int][]models.LedgerEntry, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return entriesByOrderID, nil
+	}
+
+	placeholders := make([]string, 0, len(orderIDs))
+	args := make([]any, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		args = append(args, orderID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		"SELECT "+ledgerEntryColumns+" FROM order_ledger_entries WHERE order_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY order_id, created_at, id",
+		args...,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := models.LedgerEntry{}
+		err = scanLedgerEntry(rows, &entry)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		entriesByOrderID[entry.OrderID] = append(entriesByOrderID[entry.OrderID], entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return entriesByOrderID, nil
+}
+
+func (s *Store) CreateLedgerEntry(ctx context.Context, entry *models.LedgerEntry) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO order_ledger_entries (uuid, order_id, payment_id, kind, method, amount, comment, recorded_by, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		entry.UUID, entry.OrderID, entry.PaymentID, entry.Kind, entry.Method, entry.Amount, entry.Comment, entry.RecordedBy, entry.CreatedAt,
+	).Scan(&entry.ID)
+	return wrapDBError(err)
+}
+
+// GetOrderPaidAmount returns payments minus refunds recorded in the order ledger.
+func (s *Store) GetOrderPaidAmount(ctx context.Context, orderID int) (int, error) {
+	var amount int
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT COALESCE(SUM(CASE WHEN kind = $2 THEN -amount ELSE amount END), 0) FROM order_ledger_entries WHERE order_id = $1",
+		orderID, enums.LedgerEntryKindRefund,
+	).Scan(&amount)
+	return amount, wrapDBError(err)
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const orderReviewRequestColumns = "id, order_id, channel, attempts, last_error, due_at, sent_at, created_at, updated_at"
+
+func scanOrderReviewRequest(row pgx.Row, request *models.OrderReviewRequest) error {
+	return row.Scan(
+		&request.ID,
+		&request.OrderID,
+		&request.Channel,
+		&request.Attempts,
+		&request.LastError,
+		&request.DueAt,
+		&request.SentAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+}
+
+// CreateOrderReviewRequest schedules the request unless the order already has one.
+func (s *Store) CreateOrderReviewRequest(ctx context.Context, request *models.OrderReviewRequest) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`INSERT INTO order_review_requests (order_id, due_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id) DO NOTHING`,
+		request.OrderID, request.DueAt, request.CreatedAt, request.UpdatedAt,
+	)
+	return wrapDBError(err)
+}
+
+// ClaimDueOrderReviewRequests returns unsent requests whose time has come and postpones
+// them until leaseUntil, so concurrent workers do not ask the same customer twice.
+func (s *Store) ClaimDueOrderReviewRequests(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.OrderReviewRequest, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`UPDATE order_review_requests SET due_at = $2
+		WHERE id IN (
+			SELECT id FROM order_review_requests
+			WHERE sent_at IS NULL AND due_at <= $1
+			ORDER BY due_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+orderReviewRequestColumns,
+		now, leaseUntil, limit,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	requests := make([]models.OrderReviewRequest, 0)
+	for rows.Next() {
+		request := models.OrderReviewRequest{}
+		err = scanOrderReviewRequest(rows, &request)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		requests = append(requests, request)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return requests, nil
+}
+
+func (s *Store) UpdateOrderReviewRequest(ctx context.Context, request *models.OrderReviewRequest) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE order_review_requests SET channel = $1, attempts = $2, last_error = $3, due_at = $4, sent_at = $5, updated_at = $6 WHERE id = $7",
+		request.Channel, request.Attempts, request.LastError, request.DueAt, request.SentAt, request.UpdatedAt, request.ID,
+	)
+	return wrapDBError(err)
+}
@@ -19,6 +19,47 @@ func (s *Store) GetOrderTelegramMessageByOrderID(ctx context.Context, orderID in
 	return message, nil
 }
 
+func (s *Store) GetOrderTelegramMessagesByOrderID(ctx context.Context, orderID int) ([]models.OrderTelegramMessage, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		"SELECT order_id, chat_id, message_id FROM order_telegram_messages WHERE order_id = $1 ORDER BY message_id",
+		orderID,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.OrderTelegramMessage, 0)
+	for rows.Next() {
+		message := models.OrderTelegramMessage{}
+		err = rows.Scan(&message.OrderID, &message.ChatID, &message.MessageID)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return messages, nil
+}
+
+func (s *Store) GetOrderTelegramMessageByMessageID(ctx context.Context, chatID, messageID int64) (*models.OrderTelegramMessage, error) {
+	message := &models.OrderTelegramMessage{}
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT order_id, chat_id, message_id FROM order_telegram_messages WHERE chat_id = $1 AND message_id = $2",
+		chatID,
+		messageID,
+	).Scan(&message.OrderID, &message.ChatID, &message.MessageID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return message, nil
+}
+
 func (s *Store) CreateOrderTelegramMessage(ctx context.Context, message *models.OrderTelegramMessage) error {
 	//_, err := s.querier(ctx).Exec(
 	//	ctx,
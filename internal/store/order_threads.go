@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const orderThreadColumns = "id, order_id, chat_id, message_thread_id, customer_id, created_by, created_at, closed_at"
+
+func scanOrderThread(row pgx.Row, thread *models.OrderThread) error {
+	return row.Scan(
+		&thread.ID,
+		&thread.OrderID,
+		&thread.ChatID,
+		&thread.MessageThreadID,
+		&thread.CustomerID,
+		&thread.CreatedBy,
+		&thread.CreatedAt,
+		&thread.ClosedAt,
+	)
+}
+
+func (s *Store) GetOpenOrderThreadByOrderID(ctx context.Context, orderID int) (*models.OrderThread, error) {
+	thread := &models.OrderThread{}
+	err := scanOrderThread(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+orderThreadColumns+" FROM order_threads WHERE order_id = $1 AND closed_at IS NULL",
+		orderID,
+	), thread)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return thread, nil
+}
+
+func (s *Store) GetOpenOrderThreadByTopic(ctx context.Context, chatID, messageThreadID int64) (*models.OrderThread, error) {
+	thread := &models.OrderThread{}
+	err := scanOrderThread(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+orderThreadColumns+" FROM order_threads WHERE chat_id = $1 AND message_thread_id = $2 AND closed_at IS NULL",
+		chatID, messageThreadID,
+	), thread)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return thread, nil
+}
+
+func (s *Store) GetLatestOpenOrderThreadByCustomerID(ctx context.Context, customerID int) (*models.OrderThread, error) {
+	thread := &models.OrderThread{}
+	err := scanOrderThread(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+orderThreadColumns+" FROM order_threads WHERE customer_id = $1 AND closed_at IS NULL ORDER BY created_at DESC LIMIT 1",
+		customerID,
+	), thread)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return thread, nil
+}
+
+func (s *Store) CreateOrderThread(ctx context.Context, thread *models.OrderThread) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO order_threads (order_id, chat_id, message_thread_id, customer_id, created_by, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		thread.OrderID,
+		thread.ChatID,
+		thread.MessageThreadID,
+		thread.CustomerID,
+		thread.CreatedBy,
+		thread.CreatedAt,
+	).Scan(&thread.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) CloseOrderThread(ctx context.Context, id int, closedAt time.Time) error {
+	_, err := s.querier(ctx).Exec(ctx, "UPDATE order_threads SET closed_at = $1 WHERE id = $2", closedAt, id)
+	return wrapDBError(err)
+}
@@ -7,12 +7,75 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/zagvozdeen/ola/internal/store/enums"
 	"github.com/zagvozdeen/ola/internal/store/models"
 )
 
-func (s *Store) GetAllOrders(ctx context.Context) ([]models.Order, error) {
-	rows, err := s.querier(ctx).Query(ctx, "SELECT id, uuid, status, source, name, phone, content, user_id, created_at, updated_at FROM orders ORDER BY updated_at DESC, created_at DESC")
+const orderColumns = "id, uuid, status, source, delivery_type, name, phone, content, user_id, assignee_id, customer_id, feedback_id, promo_code_id, promo_code, discount, amount, paid_amount, payment_status, paid_at, attribution, rating, rated_at, status_changed_at, created_at, updated_at"
+
+func scanOrder(row pgx.Row, order *models.Order) error {
+	return row.Scan(
+		&order.ID,
+		&order.UUID,
+		&order.Status,
+		&order.Source,
+		&order.DeliveryType,
+		&order.Name,
+		&order.Phone,
+		&order.Content,
+		&order.UserID,
+		&order.AssigneeID,
+		&order.CustomerID,
+		&order.FeedbackID,
+		&order.PromoCodeID,
+		&order.PromoCode,
+		&order.Discount,
+		&order.Amount,
+		&order.PaidAmount,
+		&order.PaymentStatus,
+		&order.PaidAt,
+		&order.Attribution,
+		&order.Rating,
+		&order.RatedAt,
+		&order.StatusChangedAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+}
+
+// GetAllOrders returns every order, or only orders of customers with the tag when it is set.
+func (s *Store) GetAllOrders(ctx context.Context, tag string) ([]models.Order, error) {
+	query := "SELECT " + orderColumns + " FROM orders"
+	var args []any
+	if tag != "" {
+		query += " WHERE customer_id IN (SELECT id FROM customers WHERE $1 = ANY(tags))"
+		args = append(args, tag)
+	}
+	rows, err := s.querier(ctx).Query(ctx, query+" ORDER BY updated_at DESC, created_at DESC", args...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0)
+	for rows.Next() {
+		order := models.Order{}
+		err = scanOrder(rows, &order)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return orders, nil
+}
+
+func (s *Store) GetUnfinishedOrders(ctx context.Context) ([]models.Order, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+orderColumns+" FROM orders WHERE status <> $1 ORDER BY status_changed_at", enums.RequestStatusReviewed)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -21,7 +84,7 @@ func (s *Store) GetAllOrders(ctx context.Context) ([]models.Order, error) {
 	orders := make([]models.Order, 0)
 	for rows.Next() {
 		order := models.Order{}
-		err = rows.Scan(&order.ID, &order.UUID, &order.Status, &order.Source, &order.Name, &order.Phone, &order.Content, &order.UserID, &order.CreatedAt, &order.UpdatedAt)
+		err = scanOrder(rows, &order)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -36,13 +99,41 @@ func (s *Store) GetAllOrders(ctx context.Context) ([]models.Order, error) {
 
 func (s *Store) GetOrderByUUID(ctx context.Context, orderUUID uuid.UUID) (*models.Order, error) {
 	order := &models.Order{}
-	err := s.querier(ctx).QueryRow(
+	err := scanOrder(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, status, source, name, phone, content, user_id, created_at, updated_at FROM orders WHERE uuid = $1",
+		"SELECT "+orderColumns+" FROM orders WHERE uuid = $1",
 		orderUUID,
-	).Scan(
-		&order.ID, &order.UUID, &order.Status, &order.Source, &order.Name, &order.Phone, &order.Content, &order.UserID, &order.CreatedAt, &order.UpdatedAt,
-	)
+	), order)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return order, nil
+}
+
+// GetOrderByIDForUpdate locks the order row until the transaction ends, like GetOrderByUUIDForUpdate.
+func (s *Store) GetOrderByIDForUpdate(ctx context.Context, id int) (*models.Order, error) {
+	order := &models.Order{}
+	err := scanOrder(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+orderColumns+" FROM orders WHERE id = $1 FOR UPDATE",
+		id,
+	), order)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return order, nil
+}
+
+// GetOrderByUUIDForUpdate locks the order row until the transaction ends, so payment totals are checked one change at a time.
+func (s *Store) GetOrderByUUIDForUpdate(ctx context.Context, orderUUID uuid.UUID) (*models.Order, error) {
+	order := &models.Order{}
+	err := scanOrder(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+orderColumns+" FROM orders WHERE uuid = $1 FOR UPDATE",
+		orderUUID,
+	), order)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -52,13 +143,11 @@ func (s *Store) GetOrderByUUID(ctx context.Context, orderUUID uuid.UUID) (*model
 
 func (s *Store) GetOrderByID(ctx context.Context, orderID int) (*models.Order, error) {
 	order := &models.Order{}
-	err := s.querier(ctx).QueryRow(
+	err := scanOrder(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, status, source, name, phone, content, user_id, created_at, updated_at FROM orders WHERE id = $1",
+		"SELECT "+orderColumns+" FROM orders WHERE id = $1",
 		orderID,
-	).Scan(
-		&order.ID, &order.UUID, &order.Status, &order.Source, &order.Name, &order.Phone, &order.Content, &order.UserID, &order.CreatedAt, &order.UpdatedAt,
-	)
+	), order)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -69,8 +158,8 @@ func (s *Store) GetOrderByID(ctx context.Context, orderID int) (*models.Order, e
 func (s *Store) CreateOrder(ctx context.Context, order *models.Order) error {
 	err := s.querier(ctx).QueryRow(
 		ctx,
-		"INSERT INTO orders (uuid, status, source, name, phone, content, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
-		order.UUID, order.Status, order.Source, order.Name, order.Phone, order.Content, order.UserID, order.CreatedAt, order.UpdatedAt,
+		"INSERT INTO orders (uuid, status, source, delivery_type, name, phone, content, user_id, customer_id, feedback_id, attribution, status_changed_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id",
+		order.UUID, order.Status, order.Source, order.DeliveryType, order.Name, order.Phone, order.Content, order.UserID, order.CustomerID, order.FeedbackID, order.Attribution, order.StatusChangedAt, order.CreatedAt, order.UpdatedAt,
 	).Scan(&order.ID)
 	return wrapDBError(err)
 }
@@ -78,8 +167,44 @@ func (s *Store) CreateOrder(ctx context.Context, order *models.Order) error {
 func (s *Store) UpdateOrderStatus(ctx context.Context, order *models.Order) error {
 	_, err := s.querier(ctx).Exec(
 		ctx,
-		"UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3",
-		order.Status, order.UpdatedAt, order.ID,
+		"UPDATE orders SET status = $1, status_changed_at = $2, assignee_id = $3, updated_at = $4 WHERE id = $5",
+		order.Status, order.StatusChangedAt, order.AssigneeID, order.UpdatedAt, order.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateOrderRating(ctx context.Context, order *models.Order) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE orders SET rating = $1, rated_at = $2, updated_at = $3 WHERE id = $4",
+		order.Rating, order.RatedAt, order.UpdatedAt, order.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateOrderAmount(ctx context.Context, order *models.Order) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE orders SET amount = $1, updated_at = $2 WHERE id = $3",
+		order.Amount, order.UpdatedAt, order.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateOrderPayment(ctx context.Context, order *models.Order) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE orders SET paid_amount = $1, payment_status = $2, paid_at = $3, updated_at = $4 WHERE id = $5",
+		order.PaidAmount, order.PaymentStatus, order.PaidAt, order.UpdatedAt, order.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateOrderPromoCode(ctx context.Context, order *models.Order) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE orders SET promo_code_id = $1, promo_code = $2, discount = $3, updated_at = $4 WHERE id = $5",
+		order.PromoCodeID, order.PromoCode, order.Discount, order.UpdatedAt, order.ID,
 	)
 	return wrapDBError(err)
 }
@@ -135,7 +260,7 @@ func (s *Store) GetOrderItemsByOrderIDs(ctx context.Context, orderIDs []int) (ma
 	return itemsByOrderID, nil
 }
 
-func (s *Store) CreateOrderFromUserCart(ctx context.Context, userID int, source enums.OrderSource, name, phone, content string) (*models.Order, error) {
+func (s *Store) CreateOrderFromUserCart(ctx context.Context, userID int, source enums.OrderSource, deliveryType enums.DeliveryType, name, phone, content string) (*models.Order, error) {
 	cart, err := s.getOrCreateUserCartByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -148,21 +273,24 @@ func (s *Store) CreateOrderFromUserCart(ctx context.Context, userID int, source
 
 	now := time.Now()
 	order := &models.Order{
-		UUID:      uid,
-		Status:    enums.RequestStatusCreated,
-		Source:    source,
-		Name:      name,
-		Phone:     phone,
-		Content:   content,
-		UserID:    &userID,
-		CreatedAt: now,
-		UpdatedAt: now,
+		UUID:            uid,
+		Status:          enums.RequestStatusCreated,
+		PaymentStatus:   enums.OrderPaymentStatusUnpaid,
+		Source:          source,
+		DeliveryType:    deliveryType,
+		Name:            name,
+		Phone:           phone,
+		Content:         content,
+		UserID:          &userID,
+		StatusChangedAt: now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
 	err = s.querier(ctx).QueryRow(
 		ctx,
-		"INSERT INTO orders (uuid, status, source, name, phone, content, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
-		order.UUID, order.Status, order.Source, order.Name, order.Phone, order.Content, order.UserID, order.CreatedAt, order.UpdatedAt,
+		"INSERT INTO orders (uuid, status, source, delivery_type, name, phone, content, user_id, status_changed_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id",
+		order.UUID, order.Status, order.Source, order.DeliveryType, order.Name, order.Phone, order.Content, order.UserID, order.StatusChangedAt, order.CreatedAt, order.UpdatedAt,
 	).Scan(&order.ID)
 	if err != nil {
 		return nil, wrapDBError(err)
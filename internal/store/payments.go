@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const paymentColumns = "id, uuid, order_id, user_id, provider, status, amount, currency, external_id, provider_charge_id, confirmation_url, paid_at, created_at, updated_at"
+
+func scanPayment(row pgx.Row, payment *models.Payment) error {
+	return row.Scan(
+		&payment.ID,
+		&payment.UUID,
+		&payment.OrderID,
+		&payment.UserID,
+		&payment.Provider,
+		&payment.Status,
+		&payment.Amount,
+		&payment.Currency,
+		&payment.ExternalID,
+		&payment.ProviderChargeID,
+		&payment.ConfirmationURL,
+		&payment.PaidAt,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+}
+
+func (s *Store) GetPaymentByUUID(ctx context.Context, paymentUUID uuid.UUID) (*models.Payment, error) {
+	payment := &models.Payment{}
+	err := scanPayment(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE uuid = $1 FOR UPDATE",
+		paymentUUID,
+	), payment)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return payment, nil
+}
+
+func (s *Store) GetPaymentByExternalID(ctx context.Context, provider enums.PaymentProvider, externalID string) (*models.Payment, error) {
+	payment := &models.Payment{}
+	err := scanPayment(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE provider = $1 AND external_id = $2 FOR UPDATE",
+		provider, externalID,
+	), payment)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return payment, nil
+}
+
+func (s *Store) GetPaymentsByOrderID(ctx context.Context, orderID int) ([]models.Payment, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+paymentColumns+" FROM payments WHERE order_id = $1 ORDER BY created_at", orderID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	payments := make([]models.Payment, 0)
+	for rows.Next() {
+		payment := models.Payment{}
+		err = scanPayment(rows, &payment)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		payments = append(payments, payment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return payments, nil
+}
+
+func (s *Store) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO payments (uuid, order_id, user_id, provider, status, amount, currency, external_id, provider_charge_id, confirmation_url, paid_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id",
+		payment.UUID, payment.OrderID, payment.UserID, payment.Provider, payment.Status, payment.Amount, payment.Currency, payment.ExternalID, payment.ProviderChargeID, payment.ConfirmationURL, payment.PaidAt, payment.CreatedAt, payment.UpdatedAt,
+	).Scan(&payment.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE payments SET status = $1, external_id = $2, provider_charge_id = $3, paid_at = $4, updated_at = $5 WHERE id = $6",
+		payment.Status, payment.ExternalID, payment.ProviderChargeID, payment.PaidAt, payment.UpdatedAt, payment.ID,
+	)
+	return wrapDBError(err)
+}
+
+// CreatePaymentWebhookEvent records a provider notification and reports false when it was already processed.
+func (s *Store) CreatePaymentWebhookEvent(ctx context.Context, provider enums.PaymentProvider, externalID string, status enums.PaymentStatus, createdAt time.Time) (bool, error) {
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		"INSERT INTO payment_webhook_events (provider, external_id, status, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		provider, externalID, status, createdAt,
+	)
+	if err != nil {
+		return false, wrapDBError(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
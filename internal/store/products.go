@@ -12,7 +12,7 @@ import (
 
 // GetAllProducts
 func (s *Store) GetAllProducts(ctx context.Context) ([]models.Product, error) {
-	rows, err := s.querier(ctx).Query(ctx, "SELECT id, uuid, name, description, price_from, price_to, type, file_content, user_id, created_at, updated_at FROM products ORDER BY created_at DESC")
+	rows, err := s.querier(ctx).Query(ctx, "SELECT id, uuid, name, description, price_from, price_to, type, file_content, rating, reviews_count, user_id, created_at, updated_at FROM products ORDER BY created_at DESC")
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -20,7 +20,7 @@ func (s *Store) GetAllProducts(ctx context.Context) ([]models.Product, error) {
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		err = rows.Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
+		err = rows.Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.Rating, &product.ReviewsCount, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -37,7 +37,7 @@ func (s *Store) GetAllProducts(ctx context.Context) ([]models.Product, error) {
 func (s *Store) GetMainProducts(ctx context.Context) ([]models.Product, error) {
 	rows, err := s.querier(ctx).Query(
 		ctx,
-		"SELECT name, description, price_from, price_to, file_content FROM products WHERE is_main = TRUE AND type = $1 ORDER BY created_at DESC LIMIT 4",
+		"SELECT name, description, price_from, price_to, file_content, rating, reviews_count FROM products WHERE is_main = TRUE AND type = $1 ORDER BY created_at DESC LIMIT 4",
 		enums.ProductTypeProduct,
 	)
 	if err != nil {
@@ -47,7 +47,7 @@ func (s *Store) GetMainProducts(ctx context.Context) ([]models.Product, error) {
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		err = rows.Scan(&product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.FileContent)
+		err = rows.Scan(&product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.FileContent, &product.Rating, &product.ReviewsCount)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -61,7 +61,7 @@ func (s *Store) GetMainProducts(ctx context.Context) ([]models.Product, error) {
 func (s *Store) GetMainServices(ctx context.Context) ([]models.Product, error) {
 	rows, err := s.querier(ctx).Query(
 		ctx,
-		"SELECT name, description, price_from, price_to, file_content FROM products WHERE is_main = TRUE AND type = $1 ORDER BY created_at DESC LIMIT 4",
+		"SELECT name, description, price_from, price_to, file_content, rating, reviews_count FROM products WHERE is_main = TRUE AND type = $1 ORDER BY created_at DESC LIMIT 4",
 		enums.ProductTypeService,
 	)
 	if err != nil {
@@ -71,7 +71,7 @@ func (s *Store) GetMainServices(ctx context.Context) ([]models.Product, error) {
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		err = rows.Scan(&product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.FileContent)
+		err = rows.Scan(&product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.FileContent, &product.Rating, &product.ReviewsCount)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -84,7 +84,7 @@ func (s *Store) GetMainServices(ctx context.Context) ([]models.Product, error) {
 // GetCatalogProducts
 func (s *Store) GetCatalogProducts(ctx context.Context, categorySlugs []string, productType *enums.ProductType) ([]models.Product, error) {
 	query := strings.Builder{}
-	query.WriteString("SELECT p.id, p.uuid, p.name, p.description, p.price_from, p.price_to, p.type, p.file_content, p.user_id, p.created_at, p.updated_at FROM products p")
+	query.WriteString("SELECT p.id, p.uuid, p.name, p.description, p.price_from, p.price_to, p.type, p.file_content, p.rating, p.reviews_count, p.user_id, p.created_at, p.updated_at FROM products p")
 
 	args := make([]any, 0, len(categorySlugs)+1)
 	conditions := make([]string, 0, 2)
@@ -123,7 +123,7 @@ func (s *Store) GetCatalogProducts(ctx context.Context, categorySlugs []string,
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		err = rows.Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
+		err = rows.Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.Rating, &product.ReviewsCount, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -142,9 +142,9 @@ func (s *Store) GetProductByID(ctx context.Context, id int) (*models.Product, er
 	product := &models.Product{}
 	err := s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, name, description, price_from, price_to, type, file_content, user_id, created_at, updated_at FROM products WHERE id = $1",
+		"SELECT id, uuid, name, description, price_from, price_to, type, file_content, rating, reviews_count, user_id, created_at, updated_at FROM products WHERE id = $1",
 		id,
-	).Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
+	).Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.FileContent, &product.Rating, &product.ReviewsCount, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -155,15 +155,28 @@ func (s *Store) GetProductByUUID(ctx context.Context, uuid uuid.UUID) (*models.P
 	product := &models.Product{}
 	err := s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, uuid, name, description, price_from, price_to, type, is_main, file_content, user_id, created_at, updated_at FROM products WHERE uuid = $1",
+		"SELECT id, uuid, name, description, price_from, price_to, type, is_main, file_content, rating, reviews_count, user_id, created_at, updated_at FROM products WHERE uuid = $1",
 		uuid,
-	).Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.IsMain, &product.FileContent, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
+	).Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.IsMain, &product.FileContent, &product.Rating, &product.ReviewsCount, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
 	return product, nil
 }
 
+// RefreshProductRating recalculates the product rating from its approved reviews.
+func (s *Store) RefreshProductRating(ctx context.Context, productID int) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE products SET
+			rating = COALESCE((SELECT ROUND(AVG(rating), 2) FROM reviews WHERE product_id = $1 AND status = $2), 0),
+			reviews_count = (SELECT COUNT(*) FROM reviews WHERE product_id = $1 AND status = $2)
+		WHERE id = $1`,
+		productID, enums.ReviewStatusApproved,
+	)
+	return wrapDBError(err)
+}
+
 func (s *Store) CreateProduct(ctx context.Context, product *models.Product) error {
 	err := s.querier(ctx).QueryRow(
 		ctx,
@@ -201,3 +214,78 @@ func (s *Store) DeleteProductByUUID(ctx context.Context, uuid uuid.UUID) error {
 	}
 	return nil
 }
+
+func (s *Store) SearchProducts(ctx context.Context, query string, limit, offset int) ([]models.Product, error) {
+	pattern := "%" + strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(query, `\`, `\\`), "%", `\%`), "_", `\_`) + "%"
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT p.id, p.uuid, p.name, p.description, p.price_from, p.price_to, p.type, p.is_main, p.file_content, p.rating, p.reviews_count, p.user_id, p.created_at, p.updated_at
+		FROM products p
+		WHERE p.name ILIKE $1
+			OR p.description ILIKE $1
+			OR EXISTS (
+				SELECT 1 FROM category_product cp
+				JOIN categories c ON c.id = cp.category_id
+				WHERE cp.product_id = p.id AND (c.name ILIKE $1 OR c.slug ILIKE $1)
+			)
+		ORDER BY p.is_main DESC, p.name, p.id
+		LIMIT $2 OFFSET $3`,
+		pattern, limit, offset,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var product models.Product
+		err = rows.Scan(&product.ID, &product.UUID, &product.Name, &product.Description, &product.PriceFrom, &product.PriceTo, &product.Type, &product.IsMain, &product.FileContent, &product.Rating, &product.ReviewsCount, &product.UserID, &product.CreatedAt, &product.UpdatedAt)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return products, nil
+}
+
+func (s *Store) GetCategoryIDsByProductIDs(ctx context.Context, productIDs []int) (map[int][]int, error) {
+	categoryIDsByProductID := make(map[int][]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return categoryIDsByProductID, nil
+	}
+
+	placeholders := make([]string, 0, len(productIDs))
+	args := make([]any, 0, len(productIDs))
+	for _, productID := range productIDs {
+		args = append(args, productID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		"SELECT product_id, category_id FROM category_product WHERE product_id IN ("+strings.Join(placeholders, ", ")+")",
+		args...,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, categoryID int
+		err = rows.Scan(&productID, &categoryID)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		categoryIDsByProductID[productID] = append(categoryIDsByProductID[productID], categoryID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+
+	return categoryIDsByProductID, nil
+}
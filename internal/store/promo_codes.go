@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const promoCodeColumns = `p.id, p.uuid, p.code, p.kind, p.value, p.min_order_amount, p.starts_at, p.ends_at, p.usage_limit, p.per_user_limit, p.product_types, p.is_active, p.owner_id, p.created_by, p.created_at, p.updated_at,
+	ARRAY(SELECT pc.category_id FROM promo_code_categories pc WHERE pc.promo_code_id = p.id ORDER BY pc.category_id),
+	(SELECT COUNT(*) FROM orders o WHERE o.promo_code_id = p.id)`
+
+func scanPromoCode(row pgx.Row, promo *models.PromoCode) error {
+	var productTypes []string
+	err := row.Scan(
+		&promo.ID,
+		&promo.UUID,
+		&promo.Code,
+		&promo.Kind,
+		&promo.Value,
+		&promo.MinOrderAmount,
+		&promo.StartsAt,
+		&promo.EndsAt,
+		&promo.UsageLimit,
+		&promo.PerUserLimit,
+		&productTypes,
+		&promo.IsActive,
+		&promo.OwnerID,
+		&promo.CreatedBy,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+		&promo.CategoryIDs,
+		&promo.UsedCount,
+	)
+	if err != nil {
+		return err
+	}
+	promo.ProductTypes = make([]enums.ProductType, 0, len(productTypes))
+	for _, productType := range productTypes {
+		t, err := enums.NewProductType(productType)
+		if err != nil {
+			return err
+		}
+		promo.ProductTypes = append(promo.ProductTypes, t)
+	}
+	return nil
+}
+
+func productTypeSlugs(types []enums.ProductType) []string {
+	slugs := make([]string, 0, len(types))
+	for _, t := range types {
+		slugs = append(slugs, t.String())
+	}
+	return slugs
+}
+
+func (s *Store) GetAllPromoCodes(ctx context.Context) ([]models.PromoCode, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+promoCodeColumns+" FROM promo_codes p ORDER BY p.created_at DESC")
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	promos := make([]models.PromoCode, 0)
+	for rows.Next() {
+		promo := models.PromoCode{}
+		err = scanPromoCode(rows, &promo)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		promos = append(promos, promo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return promos, nil
+}
+
+func (s *Store) GetPromoCodeByUUID(ctx context.Context, promoUUID uuid.UUID) (*models.PromoCode, error) {
+	promo := &models.PromoCode{}
+	err := scanPromoCode(s.querier(ctx).QueryRow(ctx, "SELECT "+promoCodeColumns+" FROM promo_codes p WHERE p.uuid = $1", promoUUID), promo)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return promo, nil
+}
+
+func (s *Store) GetPromoCodeByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	promo := &models.PromoCode{}
+	err := scanPromoCode(s.querier(ctx).QueryRow(ctx, "SELECT "+promoCodeColumns+" FROM promo_codes p WHERE p.code = $1", strings.ToUpper(code)), promo)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return promo, nil
+}
+
+// GetUserCartPromoCode returns the promo code applied to the user's cart. With lock set the
+// promo code row stays locked until the transaction ends, so usage limits are checked one order at a time.
+func (s *Store) GetUserCartPromoCode(ctx context.Context, userID int, lock bool) (*models.PromoCode, error) {
+	query := "SELECT " + promoCodeColumns + " FROM promo_codes p JOIN carts c ON c.promo_code_id = p.id WHERE c.user_id = $1"
+	if lock {
+		query += " FOR UPDATE OF p"
+	}
+	promo := &models.PromoCode{}
+	err := scanPromoCode(s.querier(ctx).QueryRow(ctx, query, userID), promo)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return promo, nil
+}
+
+func (s *Store) SetUserCartPromoCode(ctx context.Context, userID int, promoCodeID *int) error {
+	if promoCodeID == nil {
+		_, err := s.querier(ctx).Exec(ctx, "UPDATE carts SET promo_code_id = NULL WHERE user_id = $1", userID)
+		return wrapDBError(err)
+	}
+
+	cart, err := s.getOrCreateUserCartByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.querier(ctx).Exec(ctx, "UPDATE carts SET promo_code_id = $1 WHERE id = $2", promoCodeID, cart.ID)
+	return wrapDBError(err)
+}
+
+// CountPromoCodeUsages returns how many orders used the promo code in total and by the user.
+func (s *Store) CountPromoCodeUsages(ctx context.Context, promoCodeID, userID int) (int, int, error) {
+	var total, byUser int
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2) FROM orders WHERE promo_code_id = $1",
+		promoCodeID, userID,
+	).Scan(&total, &byUser)
+	if err != nil {
+		return 0, 0, wrapDBError(err)
+	}
+	return total, byUser, nil
+}
+
+func (s *Store) CreatePromoCode(ctx context.Context, promo *models.PromoCode) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		`INSERT INTO promo_codes (uuid, code, kind, value, min_order_amount, starts_at, ends_at, usage_limit, per_user_limit, product_types, is_active, owner_id, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
+		promo.UUID, promo.Code, promo.Kind, promo.Value, promo.MinOrderAmount, promo.StartsAt, promo.EndsAt, promo.UsageLimit, promo.PerUserLimit, productTypeSlugs(promo.ProductTypes), promo.IsActive, promo.OwnerID, promo.CreatedBy, promo.CreatedAt, promo.UpdatedAt,
+	).Scan(&promo.ID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	return s.setPromoCodeCategories(ctx, promo)
+}
+
+func (s *Store) UpdatePromoCode(ctx context.Context, promo *models.PromoCode) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE promo_codes SET code = $1, kind = $2, value = $3, min_order_amount = $4, starts_at = $5, ends_at = $6, usage_limit = $7, per_user_limit = $8, product_types = $9, is_active = $10, updated_at = $11
+		WHERE id = $12`,
+		promo.Code, promo.Kind, promo.Value, promo.MinOrderAmount, promo.StartsAt, promo.EndsAt, promo.UsageLimit, promo.PerUserLimit, productTypeSlugs(promo.ProductTypes), promo.IsActive, promo.UpdatedAt, promo.ID,
+	)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	return s.setPromoCodeCategories(ctx, promo)
+}
+
+func (s *Store) setPromoCodeCategories(ctx context.Context, promo *models.PromoCode) error {
+	_, err := s.querier(ctx).Exec(ctx, "DELETE FROM promo_code_categories WHERE promo_code_id = $1", promo.ID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	if len(promo.CategoryIDs) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(promo.CategoryIDs))
+	args := []any{promo.ID}
+	for _, categoryID := range promo.CategoryIDs {
+		args = append(args, categoryID)
+		placeholders = append(placeholders, fmt.Sprintf("($1, $%d)", len(args)))
+	}
+	_, err = s.querier(ctx).Exec(
+		ctx,
+		"INSERT INTO promo_code_categories (promo_code_id, category_id) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT DO NOTHING",
+		args...,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) DeletePromoCodeByUUID(ctx context.Context, promoUUID uuid.UUID) error {
+	tag, err := s.querier(ctx).Exec(ctx, "DELETE FROM promo_codes WHERE uuid = $1", promoUUID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
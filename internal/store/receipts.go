@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const receiptColumns = "id, uuid, order_id, payment_id, operation, status, operator, payload, external_id, fiscal, attempts, last_error, next_attempt_at, created_at, updated_at"
+
+func scanReceipt(row pgx.Row, receipt *models.Receipt) error {
+	return row.Scan(
+		&receipt.ID,
+		&receipt.UUID,
+		&receipt.OrderID,
+		&receipt.PaymentID,
+		&receipt.Operation,
+		&receipt.Status,
+		&receipt.Operator,
+		&receipt.Payload,
+		&receipt.ExternalID,
+		&receipt.Fiscal,
+		&receipt.Attempts,
+		&receipt.LastError,
+		&receipt.NextAttemptAt,
+		&receipt.CreatedAt,
+		&receipt.UpdatedAt,
+	)
+}
+
+func (s *Store) GetReceiptByUUID(ctx context.Context, receiptUUID uuid.UUID) (*models.Receipt, error) {
+	receipt := &models.Receipt{}
+	err := scanReceipt(s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT "+receiptColumns+" FROM receipts WHERE uuid = $1 FOR UPDATE",
+		receiptUUID,
+	), receipt)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return receipt, nil
+}
+
+func (s *Store) GetReceiptsByOrderID(ctx context.Context, orderID int) ([]models.Receipt, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+receiptColumns+" FROM receipts WHERE order_id = $1 ORDER BY created_at", orderID)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	receipts := make([]models.Receipt, 0)
+	for rows.Next() {
+		receipt := models.Receipt{}
+		err = scanReceipt(rows, &receipt)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return receipts, nil
+}
+
+// CreateReceipt stores the receipt unless one already exists for the same payment and operation.
+func (s *Store) CreateReceipt(ctx context.Context, receipt *models.Receipt) (bool, error) {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		`INSERT INTO receipts (uuid, order_id, payment_id, operation, status, operator, payload, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (payment_id, operation) DO NOTHING
+		RETURNING id`,
+		receipt.UUID, receipt.OrderID, receipt.PaymentID, receipt.Operation, receipt.Status, receipt.Operator, receipt.Payload, receipt.Attempts, receipt.NextAttemptAt, receipt.CreatedAt, receipt.UpdatedAt,
+	).Scan(&receipt.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, wrapDBError(err)
+	}
+	return true, nil
+}
+
+// ClaimDueReceipts returns unfinished receipts whose next attempt is due and postpones
+// them until leaseUntil, so concurrent workers do not pick the same receipt.
+func (s *Store) ClaimDueReceipts(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.Receipt, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`UPDATE receipts SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM receipts
+			WHERE status IN ('pending', 'sent') AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+receiptColumns,
+		now, leaseUntil, limit,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	receipts := make([]models.Receipt, 0)
+	for rows.Next() {
+		receipt := models.Receipt{}
+		err = scanReceipt(rows, &receipt)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return receipts, nil
+}
+
+func (s *Store) UpdateReceipt(ctx context.Context, receipt *models.Receipt) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE receipts SET status = $1, operator = $2, external_id = $3, fiscal = $4, attempts = $5, last_error = $6, next_attempt_at = $7, updated_at = $8 WHERE id = $9",
+		receipt.Status, receipt.Operator, receipt.ExternalID, receipt.Fiscal, receipt.Attempts, receipt.LastError, receipt.NextAttemptAt, receipt.UpdatedAt, receipt.ID,
+	)
+	return wrapDBError(err)
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const referralColumns = "id, referrer_id, referred_id, order_id, reward_promo_code_id, created_at, converted_at"
+
+func scanReferral(row pgx.Row, referral *models.Referral) error {
+	return row.Scan(
+		&referral.ID,
+		&referral.ReferrerID,
+		&referral.ReferredID,
+		&referral.OrderID,
+		&referral.RewardPromoCodeID,
+		&referral.CreatedAt,
+		&referral.ConvertedAt,
+	)
+}
+
+func (s *Store) CreateReferral(ctx context.Context, referral *models.Referral) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO referrals (referrer_id, referred_id, created_at) VALUES ($1, $2, $3) RETURNING id",
+		referral.ReferrerID, referral.ReferredID, referral.CreatedAt,
+	).Scan(&referral.ID)
+	return wrapDBError(err)
+}
+
+// ConvertReferral marks the referral of the user as converted by the order. It returns
+// models.ErrNotFound when the user was not referred or has already converted.
+func (s *Store) ConvertReferral(ctx context.Context, referredID, orderID int, convertedAt time.Time) (*models.Referral, error) {
+	referral := &models.Referral{}
+	err := scanReferral(s.querier(ctx).QueryRow(
+		ctx,
+		"UPDATE referrals SET order_id = $2, converted_at = $3 WHERE referred_id = $1 AND converted_at IS NULL RETURNING "+referralColumns,
+		referredID, orderID, convertedAt,
+	), referral)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return referral, nil
+}
+
+func (s *Store) UpdateReferralReward(ctx context.Context, referral *models.Referral) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE referrals SET reward_promo_code_id = $1 WHERE id = $2",
+		referral.RewardPromoCodeID, referral.ID,
+	)
+	return wrapDBError(err)
+}
+
+// CountReferrals returns how many users the referrer invited and how many of them ordered.
+func (s *Store) CountReferrals(ctx context.Context, referrerID int) (int, int, error) {
+	var invited, converted int
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"SELECT COUNT(*), COUNT(converted_at) FROM referrals WHERE referrer_id = $1",
+		referrerID,
+	).Scan(&invited, &converted)
+	if err != nil {
+		return 0, 0, wrapDBError(err)
+	}
+	return invited, converted, nil
+}
+
+// GetReferralReport aggregates referrals created in [from, to) by referrer. Orders and
+// revenue count every order of the referred users, revenue uses the agreed order amount.
+func (s *Store) GetReferralReport(ctx context.Context, from, to time.Time) ([]models.ReferralReportRow, error) {
+	rows, err := s.querier(ctx).Query(
+		ctx,
+		`SELECT u.id, u.uuid, u.first_name, u.username, u.referral_code,
+			COUNT(DISTINCT r.id),
+			COUNT(DISTINCT r.id) FILTER (WHERE r.converted_at IS NOT NULL),
+			COUNT(o.id),
+			COALESCE(SUM(o.amount), 0),
+			COUNT(DISTINCT r.reward_promo_code_id)
+		FROM referrals r
+		JOIN users u ON u.id = r.referrer_id
+		LEFT JOIN orders o ON o.user_id = r.referred_id
+		WHERE r.created_at >= $1 AND r.created_at < $2
+		GROUP BY u.id
+		ORDER BY 7 DESC, 6 DESC, u.id`,
+		from, to,
+	)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	report := make([]models.ReferralReportRow, 0)
+	for rows.Next() {
+		row := models.ReferralReportRow{}
+		err = rows.Scan(
+			&row.ReferrerID,
+			&row.ReferrerUUID,
+			&row.FirstName,
+			&row.Username,
+			&row.ReferralCode,
+			&row.Invited,
+			&row.Converted,
+			&row.Orders,
+			&row.Revenue,
+			&row.Rewards,
+		)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		report = append(report, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return report, nil
+}
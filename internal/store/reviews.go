@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/enums"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const reviewColumns = "r.id, r.uuid, r.product_id, p.name, r.order_id, r.user_id, u.first_name, r.rating, r.content, r.files, r.status, r.moderator_id, r.moderated_at, r.rejection_reason, r.created_at, r.updated_at"
+
+const reviewTables = " FROM reviews r JOIN products p ON p.id = r.product_id JOIN users u ON u.id = r.user_id"
+
+func scanReview(row pgx.Row, review *models.Review) error {
+	return row.Scan(
+		&review.ID,
+		&review.UUID,
+		&review.ProductID,
+		&review.ProductName,
+		&review.OrderID,
+		&review.UserID,
+		&review.AuthorName,
+		&review.Rating,
+		&review.Content,
+		&review.Files,
+		&review.Status,
+		&review.ModeratorID,
+		&review.ModeratedAt,
+		&review.RejectionReason,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+}
+
+// GetReviews returns reviews by status, or of the user when userID is set. Empty filters match every review.
+func (s *Store) GetReviews(ctx context.Context, status *enums.ReviewStatus, userID *int) ([]models.Review, error) {
+	query := "SELECT " + reviewColumns + reviewTables + " WHERE TRUE"
+	var args []any
+	if status != nil {
+		args = append(args, *status)
+		query += fmt.Sprintf(" AND r.status = $%d", len(args))
+	}
+	if userID != nil {
+		args = append(args, *userID)
+		query += fmt.Sprintf(" AND r.user_id = $%d", len(args))
+	}
+	return s.queryReviews(ctx, query+" ORDER BY r.created_at DESC", args...)
+}
+
+// GetApprovedReviews returns the latest approved reviews, of the given products when productIDs is not empty.
+func (s *Store) GetApprovedReviews(ctx context.Context, productIDs []int, limit int) ([]models.Review, error) {
+	query := "SELECT " + reviewColumns + reviewTables + " WHERE r.status = $1"
+	args := []any{enums.ReviewStatusApproved}
+	if len(productIDs) > 0 {
+		args = append(args, productIDs)
+		query += fmt.Sprintf(" AND r.product_id = ANY($%d)", len(args))
+	}
+	args = append(args, limit)
+	return s.queryReviews(ctx, query+fmt.Sprintf(" ORDER BY r.created_at DESC LIMIT $%d", len(args)), args...)
+}
+
+func (s *Store) queryReviews(ctx context.Context, query string, args ...any) ([]models.Review, error) {
+	rows, err := s.querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	reviews := make([]models.Review, 0)
+	for rows.Next() {
+		review := models.Review{}
+		err = scanReview(rows, &review)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return reviews, nil
+}
+
+func (s *Store) GetReviewByUUID(ctx context.Context, reviewUUID uuid.UUID) (*models.Review, error) {
+	review := &models.Review{}
+	err := scanReview(s.querier(ctx).QueryRow(ctx, "SELECT "+reviewColumns+reviewTables+" WHERE r.uuid = $1", reviewUUID), review)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return review, nil
+}
+
+func (s *Store) CreateReview(ctx context.Context, review *models.Review) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		`INSERT INTO reviews (uuid, product_id, order_id, user_id, rating, content, files, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		review.UUID, review.ProductID, review.OrderID, review.UserID, review.Rating, review.Content, review.Files, review.Status, review.CreatedAt, review.UpdatedAt,
+	).Scan(&review.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateReview(ctx context.Context, review *models.Review) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		`UPDATE reviews SET rating = $1, content = $2, files = $3, status = $4, moderator_id = $5, moderated_at = $6,
+		rejection_reason = $7, updated_at = $8 WHERE id = $9`,
+		review.Rating, review.Content, review.Files, review.Status, review.ModeratorID, review.ModeratedAt,
+		review.RejectionReason, review.UpdatedAt, review.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) DeleteReview(ctx context.Context, reviewID int) error {
+	tag, err := s.querier(ctx).Exec(ctx, "DELETE FROM reviews WHERE id = $1", reviewID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+const segmentColumns = "id, uuid, name, filter, created_by, created_at, updated_at"
+
+func scanSegment(row pgx.Row, segment *models.Segment) error {
+	return row.Scan(
+		&segment.ID,
+		&segment.UUID,
+		&segment.Name,
+		&segment.Filter,
+		&segment.CreatedBy,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+}
+
+func (s *Store) GetAllSegments(ctx context.Context) ([]models.Segment, error) {
+	rows, err := s.querier(ctx).Query(ctx, "SELECT "+segmentColumns+" FROM segments ORDER BY name")
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	defer rows.Close()
+
+	segments := make([]models.Segment, 0)
+	for rows.Next() {
+		segment := models.Segment{}
+		err = scanSegment(rows, &segment)
+		if err != nil {
+			return nil, wrapDBError(err)
+		}
+		segments = append(segments, segment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wrapDBError(err)
+	}
+	return segments, nil
+}
+
+func (s *Store) GetSegmentByUUID(ctx context.Context, segmentUUID uuid.UUID) (*models.Segment, error) {
+	segment := &models.Segment{}
+	err := scanSegment(s.querier(ctx).QueryRow(ctx, "SELECT "+segmentColumns+" FROM segments WHERE uuid = $1", segmentUUID), segment)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return segment, nil
+}
+
+func (s *Store) GetSegmentByID(ctx context.Context, id int) (*models.Segment, error) {
+	segment := &models.Segment{}
+	err := scanSegment(s.querier(ctx).QueryRow(ctx, "SELECT "+segmentColumns+" FROM segments WHERE id = $1", id), segment)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return segment, nil
+}
+
+func (s *Store) CreateSegment(ctx context.Context, segment *models.Segment) error {
+	err := s.querier(ctx).QueryRow(
+		ctx,
+		"INSERT INTO segments (uuid, name, filter, created_by, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		segment.UUID, segment.Name, segment.Filter, segment.CreatedBy, segment.CreatedAt, segment.UpdatedAt,
+	).Scan(&segment.ID)
+	return wrapDBError(err)
+}
+
+func (s *Store) UpdateSegment(ctx context.Context, segment *models.Segment) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE segments SET name = $1, filter = $2, updated_at = $3 WHERE id = $4",
+		segment.Name, segment.Filter, segment.UpdatedAt, segment.ID,
+	)
+	return wrapDBError(err)
+}
+
+func (s *Store) DeleteSegmentByUUID(ctx context.Context, segmentUUID uuid.UUID) error {
+	tag, err := s.querier(ctx).Exec(ctx, "DELETE FROM segments WHERE uuid = $1", segmentUUID)
+	if err != nil {
+		return wrapDBError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
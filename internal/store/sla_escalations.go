@@ -0,0 +1,25 @@
+package store
+
+import (
+	"context"
+
+	"github.com/zagvozdeen/ola/internal/store/models"
+)
+
+// CreateSLAEscalation reports false when the escalation has already been recorded.
+func (s *Store) CreateSLAEscalation(ctx context.Context, escalation *models.SLAEscalation) (bool, error) {
+	tag, err := s.querier(ctx).Exec(
+		ctx,
+		"INSERT INTO sla_escalations (kind, entity_id, status, status_changed_at, level, created_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING",
+		escalation.Kind,
+		escalation.EntityID,
+		escalation.Status,
+		escalation.StatusChangedAt,
+		escalation.Level,
+		escalation.CreatedAt,
+	)
+	if err != nil {
+		return false, wrapDBError(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -4,12 +4,41 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/zagvozdeen/ola/internal/store/enums"
 	"github.com/zagvozdeen/ola/internal/store/models"
 )
 
-func (s *Store) GetAllUsers(ctx context.Context) ([]models.User, error) {
-	rows, err := s.querier(ctx).Query(ctx, "SELECT id, tid, uuid, first_name, last_name, username, email, phone, password, role, created_at, updated_at FROM users ORDER BY created_at DESC")
+const userColumns = "id, tid, uuid, first_name, last_name, username, email, phone, password, role, referral_code, bot_blocked_at, created_at, updated_at"
+
+func scanUser(row pgx.Row, user *models.User) error {
+	return row.Scan(
+		&user.ID,
+		&user.TID,
+		&user.UUID,
+		&user.FirstName,
+		&user.LastName,
+		&user.Username,
+		&user.Email,
+		&user.Phone,
+		&user.Password,
+		&user.Role,
+		&user.ReferralCode,
+		&user.BotBlockedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+}
+
+// GetAllUsers returns every user, or only users linked to customers with the tag when it is set.
+func (s *Store) GetAllUsers(ctx context.Context, tag string) ([]models.User, error) {
+	query := "SELECT " + userColumns + " FROM users"
+	var args []any
+	if tag != "" {
+		query += " WHERE id IN (SELECT user_id FROM customers WHERE $1 = ANY(tags))"
+		args = append(args, tag)
+	}
+	rows, err := s.querier(ctx).Query(ctx, query+" ORDER BY created_at DESC", args...)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -18,7 +47,7 @@ func (s *Store) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	users := make([]models.User, 0)
 	for rows.Next() {
 		user := models.User{}
-		err = rows.Scan(&user.ID, &user.TID, &user.UUID, &user.FirstName, &user.LastName, &user.Username, &user.Email, &user.Phone, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		err = scanUser(rows, &user)
 		if err != nil {
 			return nil, wrapDBError(err)
 		}
@@ -33,7 +62,7 @@ func (s *Store) GetAllUsers(ctx context.Context) ([]models.User, error) {
 
 func (s *Store) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	user := &models.User{}
-	err := s.querier(ctx).QueryRow(ctx, "SELECT id, tid, uuid, first_name, last_name, username, email, phone, password, role, created_at, updated_at FROM users WHERE id = $1", id).Scan(&user.ID, &user.TID, &user.UUID, &user.FirstName, &user.LastName, &user.Username, &user.Email, &user.Phone, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(s.querier(ctx).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id), user)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -42,7 +71,7 @@ func (s *Store) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 
 func (s *Store) GetUserByTID(ctx context.Context, tid int64) (*models.User, error) {
 	user := &models.User{}
-	err := s.querier(ctx).QueryRow(ctx, "SELECT id, tid, uuid, first_name, last_name, username, email, phone, password, role, created_at, updated_at FROM users WHERE tid = $1", tid).Scan(&user.ID, &user.TID, &user.UUID, &user.FirstName, &user.LastName, &user.Username, &user.Email, &user.Phone, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(s.querier(ctx).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE tid = $1", tid), user)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -51,11 +80,11 @@ func (s *Store) GetUserByTID(ctx context.Context, tid int64) (*models.User, erro
 
 func (s *Store) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (*models.User, error) {
 	user := &models.User{}
-	err := s.querier(ctx).QueryRow(
+	err := scanUser(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, tid, uuid, first_name, last_name, username, email, phone, password, role, created_at, updated_at FROM users WHERE uuid = $1",
+		"SELECT "+userColumns+" FROM users WHERE uuid = $1",
 		userUUID,
-	).Scan(&user.ID, &user.TID, &user.UUID, &user.FirstName, &user.LastName, &user.Username, &user.Email, &user.Phone, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	), user)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -64,11 +93,20 @@ func (s *Store) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (*models.
 
 func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
-	err := s.querier(ctx).QueryRow(
+	err := scanUser(s.querier(ctx).QueryRow(
 		ctx,
-		"SELECT id, tid, uuid, first_name, last_name, username, email, phone, password, role, created_at, updated_at FROM users WHERE email = $1",
+		"SELECT "+userColumns+" FROM users WHERE email = $1",
 		email,
-	).Scan(&user.ID, &user.TID, &user.UUID, &user.FirstName, &user.LastName, &user.Username, &user.Email, &user.Phone, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	), user)
+	if err != nil {
+		return nil, wrapDBError(err)
+	}
+	return user, nil
+}
+
+func (s *Store) GetUserByReferralCode(ctx context.Context, code string) (*models.User, error) {
+	user := &models.User{}
+	err := scanUser(s.querier(ctx).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE referral_code = $1", code), user)
 	if err != nil {
 		return nil, wrapDBError(err)
 	}
@@ -93,6 +131,25 @@ func (s *Store) UpdateUserPhone(ctx context.Context, user *models.User) error {
 	return wrapDBError(err)
 }
 
+func (s *Store) UpdateUserReferralCode(ctx context.Context, user *models.User) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE users SET referral_code = $1, updated_at = $2 WHERE id = $3",
+		user.ReferralCode, user.UpdatedAt, user.ID,
+	)
+	return wrapDBError(err)
+}
+
+// UpdateUserBotBlockedAt marks the user as having blocked the bot, nil clears the mark.
+func (s *Store) UpdateUserBotBlockedAt(ctx context.Context, user *models.User) error {
+	_, err := s.querier(ctx).Exec(
+		ctx,
+		"UPDATE users SET bot_blocked_at = $1, updated_at = $2 WHERE id = $3",
+		user.BotBlockedAt, user.UpdatedAt, user.ID,
+	)
+	return wrapDBError(err)
+}
+
 func (s *Store) UpdateUserRole(ctx context.Context, userID int, role enums.UserRole) error {
 	tag, err := s.querier(ctx).Exec(
 		ctx,
@@ -81,6 +81,8 @@ router.beforeEach(async (to, from) => {
             return { name: 'orders.edit', params: { uuid } }
           case 'feedback':
             return { name: 'feedback.edit', params: { uuid } }
+          case 'product':
+            return { name: 'main', hash: `#product-${uuid}` }
           }
         } catch (e) {
           console.error(e)
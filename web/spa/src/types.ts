@@ -17,17 +17,27 @@ export enum OrderSource {
   Landing = 'landing',
   Spa = 'spa',
   Tma = 'tma',
+  Bot = 'bot',
+}
+
+export const OrderSourceTranslates: Record<OrderSource, string> = {
+  [OrderSource.Landing]: 'Сайт',
+  [OrderSource.Spa]: 'Веб-приложение',
+  [OrderSource.Tma]: 'Mini App',
+  [OrderSource.Bot]: 'Чат-бот',
 }
 
 export enum RequestStatus {
   Created = 'created',
   InProgress = 'in_progress',
+  AwaitingPayment = 'awaiting_payment',
   Reviewed = 'reviewed',
 }
 
 export const RequestStatusBgColor: Record<RequestStatus, string> = {
   [RequestStatus.Created]: 'bg-blue-700',
   [RequestStatus.InProgress]: 'bg-yellow-700',
+  [RequestStatus.AwaitingPayment]: 'bg-orange-700',
   [RequestStatus.Reviewed]: 'bg-green-700',
 }
 
@@ -50,6 +60,7 @@ export const ProductTypeBgColor: Record<ProductType, string> = {
 export const RequestStatusTranslates: Record<RequestStatus, string> = {
   [RequestStatus.Created]: 'Создана',
   [RequestStatus.InProgress]: 'В процессе',
+  [RequestStatus.AwaitingPayment]: 'Ожидает оплаты',
   [RequestStatus.Reviewed]: 'Рассмотрена',
 }
 
@@ -63,6 +74,8 @@ export const RequestStatusOptions = Object.values(RequestStatus).map((key) => ({
   label: RequestStatusTranslates[key],
 }))
 
+export const FeedbackStatusOptions = RequestStatusOptions.filter(option => option.value !== RequestStatus.AwaitingPayment)
+
 export const UserRoleTranslates: Record<UserRole, string> = {
   [UserRole.User]: 'Пользователь',
   [UserRole.Manager]: 'Менеджер',